@@ -0,0 +1,69 @@
+package assets
+
+import (
+	"fmt"
+
+	"github.com/nyaruka/gocommon/uuids"
+)
+
+// AuthProfileUUID is the UUID of an auth profile
+type AuthProfileUUID uuids.UUID
+
+// AuthProfile is a reusable set of credentials which can be used to authenticate HTTP requests. The type of the
+// profile determines which config values it requires: `basic` profiles need a `username` and `password`, `bearer`
+// profiles need a `token`, `hmac` profiles need a `secret` and optionally the `header` to put the signature in, and
+// `oauth2` profiles need a `token_url`, `client_id`, `client_secret` and optionally a `scope`.
+//
+//   {
+//     "uuid": "a8ea3f5b-4f29-4b19-9ad1-4c13f7a67e4b",
+//     "name": "Shop API",
+//     "type": "bearer",
+//     "config": {
+//       "token": "sesame"
+//     }
+//   }
+//
+// @asset auth_profile
+type AuthProfile interface {
+	UUID() AuthProfileUUID
+	Name() string
+	Type() string
+	Config() map[string]string
+}
+
+// AuthProfileReference is used to reference an auth profile
+type AuthProfileReference struct {
+	UUID AuthProfileUUID `json:"uuid" validate:"required,uuid"`
+	Name string          `json:"name"`
+}
+
+// NewAuthProfileReference creates a new auth profile reference with the given UUID and name
+func NewAuthProfileReference(uuid AuthProfileUUID, name string) *AuthProfileReference {
+	return &AuthProfileReference{UUID: uuid, Name: name}
+}
+
+// Type returns the name of the asset type
+func (r *AuthProfileReference) Type() string {
+	return "auth_profile"
+}
+
+// GenericUUID returns the untyped UUID
+func (r *AuthProfileReference) GenericUUID() uuids.UUID {
+	return uuids.UUID(r.UUID)
+}
+
+// Identity returns the unique identity of the asset
+func (r *AuthProfileReference) Identity() string {
+	return string(r.UUID)
+}
+
+// Variable returns whether this a variable (vs concrete) reference
+func (r *AuthProfileReference) Variable() bool {
+	return false
+}
+
+func (r *AuthProfileReference) String() string {
+	return fmt.Sprintf("%s[uuid=%s,name=%s]", r.Type(), r.Identity(), r.Name)
+}
+
+var _ UUIDReference = (*AuthProfileReference)(nil)
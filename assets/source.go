@@ -2,6 +2,7 @@ package assets
 
 // Source is a source of assets
 type Source interface {
+	AuthProfiles() ([]AuthProfile, error)
 	Channels() ([]Channel, error)
 	Classifiers() ([]Classifier, error)
 	Fields() ([]Field, error)
@@ -0,0 +1,35 @@
+package static
+
+import (
+	"github.com/nyaruka/goflow/assets"
+)
+
+// AuthProfile is a JSON serializable implementation of an auth profile asset
+type AuthProfile struct {
+	UUID_   assets.AuthProfileUUID `json:"uuid" validate:"required,uuid"`
+	Name_   string                 `json:"name"`
+	Type_   string                 `json:"type" validate:"required"`
+	Config_ map[string]string      `json:"config"`
+}
+
+// NewAuthProfile creates a new auth profile
+func NewAuthProfile(uuid assets.AuthProfileUUID, name string, type_ string, config map[string]string) assets.AuthProfile {
+	return &AuthProfile{
+		UUID_:   uuid,
+		Name_:   name,
+		Type_:   type_,
+		Config_: config,
+	}
+}
+
+// UUID returns the UUID of this auth profile
+func (p *AuthProfile) UUID() assets.AuthProfileUUID { return p.UUID_ }
+
+// Name returns the name of this auth profile
+func (p *AuthProfile) Name() string { return p.Name_ }
+
+// Type returns the type of this auth profile
+func (p *AuthProfile) Type() string { return p.Type_ }
+
+// Config returns the config values of this auth profile
+func (p *AuthProfile) Config() map[string]string { return p.Config_ }
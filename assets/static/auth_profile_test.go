@@ -0,0 +1,23 @@
+package static_test
+
+import (
+	"testing"
+
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/assets/static"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthProfile(t *testing.T) {
+	profile := static.NewAuthProfile(
+		assets.AuthProfileUUID("a8ea3f5b-4f29-4b19-9ad1-4c13f7a67e4b"),
+		"Shop API",
+		"bearer",
+		map[string]string{"token": "sesame"},
+	)
+	assert.Equal(t, assets.AuthProfileUUID("a8ea3f5b-4f29-4b19-9ad1-4c13f7a67e4b"), profile.UUID())
+	assert.Equal(t, "Shop API", profile.Name())
+	assert.Equal(t, "bearer", profile.Type())
+	assert.Equal(t, map[string]string{"token": "sesame"}, profile.Config())
+}
@@ -15,19 +15,20 @@ import (
 // StaticSource is an asset source which loads assets from a static JSON file
 type StaticSource struct {
 	s struct {
-		Channels    []*Channel                `json:"channels" validate:"omitempty,dive"`
-		Classifiers []*Classifier             `json:"classifiers" validate:"omitempty,dive"`
-		Fields      []*Field                  `json:"fields" validate:"omitempty,dive"`
-		Flows       []*Flow                   `json:"flows" validate:"omitempty,dive"`
-		Globals     []*Global                 `json:"globals" validate:"omitempty,dive"`
-		Groups      []*Group                  `json:"groups" validate:"omitempty,dive"`
-		Labels      []*Label                  `json:"labels" validate:"omitempty,dive"`
-		Locations   []*envs.LocationHierarchy `json:"locations"`
-		Resthooks   []*Resthook               `json:"resthooks" validate:"omitempty,dive"`
-		Templates   []*Template               `json:"templates" validate:"omitempty,dive"`
-		Ticketers   []*Ticketer               `json:"ticketers" validate:"omitempty,dive"`
-		Topics      []*Topic                  `json:"topics" validate:"omitempty,dive"`
-		Users       []*User                   `json:"users" validate:"omitempty,dive"`
+		AuthProfiles []*AuthProfile            `json:"auth_profiles" validate:"omitempty,dive"`
+		Channels     []*Channel                `json:"channels" validate:"omitempty,dive"`
+		Classifiers  []*Classifier             `json:"classifiers" validate:"omitempty,dive"`
+		Fields       []*Field                  `json:"fields" validate:"omitempty,dive"`
+		Flows        []*Flow                   `json:"flows" validate:"omitempty,dive"`
+		Globals      []*Global                 `json:"globals" validate:"omitempty,dive"`
+		Groups       []*Group                  `json:"groups" validate:"omitempty,dive"`
+		Labels       []*Label                  `json:"labels" validate:"omitempty,dive"`
+		Locations    []*envs.LocationHierarchy `json:"locations"`
+		Resthooks    []*Resthook               `json:"resthooks" validate:"omitempty,dive"`
+		Templates    []*Template               `json:"templates" validate:"omitempty,dive"`
+		Ticketers    []*Ticketer               `json:"ticketers" validate:"omitempty,dive"`
+		Topics       []*Topic                  `json:"topics" validate:"omitempty,dive"`
+		Users        []*User                   `json:"users" validate:"omitempty,dive"`
 	}
 }
 
@@ -56,6 +57,15 @@ func LoadSource(path string) (*StaticSource, error) {
 
 var _ assets.Source = (*StaticSource)(nil)
 
+// AuthProfiles returns all auth profile assets
+func (s *StaticSource) AuthProfiles() ([]assets.AuthProfile, error) {
+	set := make([]assets.AuthProfile, len(s.s.AuthProfiles))
+	for i := range s.s.AuthProfiles {
+		set[i] = s.s.AuthProfiles[i]
+	}
+	return set, nil
+}
+
 // Channels returns all channel assets
 func (s *StaticSource) Channels() ([]assets.Channel, error) {
 	set := make([]assets.Channel, len(s.s.Channels))
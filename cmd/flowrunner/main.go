@@ -24,6 +24,7 @@ import (
 	"github.com/nyaruka/goflow/flows/resumes"
 	"github.com/nyaruka/goflow/flows/triggers"
 	"github.com/nyaruka/goflow/services/classification/wit"
+	tickets "github.com/nyaruka/goflow/services/tickets/http"
 	"github.com/nyaruka/goflow/services/webhooks"
 	"github.com/nyaruka/goflow/utils"
 	"github.com/pkg/errors"
@@ -47,12 +48,14 @@ const contactJSON = `{
 const usage = `usage: flowrunner [flags] <assets.json> [flow_uuid]`
 
 func main() {
-	var initialMsg, contactLang, witToken string
+	var initialMsg, contactLang, witToken, ticketsURL, ticketsToken string
 	var printRepro bool
 	flags := flag.NewFlagSet("", flag.ExitOnError)
 	flags.StringVar(&initialMsg, "msg", "", "initial message to trigger session with")
 	flags.StringVar(&contactLang, "lang", "eng", "initial language of the contact")
 	flags.StringVar(&witToken, "wit.token", "", "access token for wit.ai")
+	flags.StringVar(&ticketsURL, "tickets.url", "", "base URL of ticket API for opening tickets")
+	flags.StringVar(&ticketsToken, "tickets.token", "", "access token for ticket API")
 	flags.BoolVar(&printRepro, "repro", false, "print repro afterwards")
 	flags.Parse(os.Args[1:])
 	args := flags.Args()
@@ -69,7 +72,7 @@ func main() {
 		flowUUID = assets.FlowUUID(args[1])
 	}
 
-	engine := createEngine(witToken)
+	engine := createEngine(witToken, ticketsURL, ticketsToken)
 
 	repro, err := RunFlow(engine, assetsPath, flowUUID, initialMsg, envs.Language(contactLang), os.Stdin, os.Stdout)
 
@@ -85,7 +88,7 @@ func main() {
 	}
 }
 
-func createEngine(witToken string) flows.Engine {
+func createEngine(witToken, ticketsURL, ticketsToken string) flows.Engine {
 	builder := engine.NewBuilder().
 		WithWebhookServiceFactory(webhooks.NewServiceFactory(http.DefaultClient, nil, nil, map[string]string{"User-Agent": "goflow-runner"}, 10000))
 
@@ -97,6 +100,11 @@ func createEngine(witToken string) flows.Engine {
 			return nil, errors.New("only classifiers of type wit supported")
 		})
 	}
+	if ticketsURL != "" {
+		builder.WithTicketServiceFactory(func(session flows.Session, ticketer *flows.Ticketer) (flows.TicketService, error) {
+			return tickets.NewService(http.DefaultClient, nil, ticketer, ticketsURL, ticketsToken), nil
+		})
+	}
 
 	return builder.Build()
 }
@@ -5,6 +5,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/antlr/antlr4/runtime/Go/antlr"
@@ -296,8 +297,13 @@ func (q *ContactQuery) String() string {
 	return s
 }
 
+// the generated lexer and parser share DFA caches between all instances which the ANTLR runtime updates without
+// any locking, so only one query can be parsed at a time
+var parseMutex sync.Mutex
+
 // ParseQuery parses a ContactQL query from the given input. If resolver is provided then we validate against it
-// to ensure that fields and groups exist. If not provided then still validate what we can.
+// to ensure that fields and groups exist. If not provided then still validate what we can. It's safe to call
+// concurrently.
 func ParseQuery(env envs.Environment, text string, resolver Resolver) (*ContactQuery, error) {
 	// preprocess text before parsing
 	text = strings.TrimSpace(text)
@@ -309,14 +315,7 @@ func ParseQuery(env envs.Environment, text string, resolver Resolver) (*ContactQ
 		}
 	}
 
-	errListener := &errorListener{}
-	input := antlr.NewInputStream(text)
-	lexer := gen.NewContactQLLexer(input)
-	stream := antlr.NewCommonTokenStream(lexer, 0)
-	p := gen.NewContactQLParser(stream)
-	p.RemoveErrorListeners()
-	p.AddErrorListener(errListener)
-	tree := p.Parse()
+	tree, errListener := parseQuery(text)
 
 	// if we ran into errors parsing, bail
 	err := errListener.Error()
@@ -340,6 +339,20 @@ func ParseQuery(env envs.Environment, text string, resolver Resolver) (*ContactQ
 	return &ContactQuery{root: rootNode, resolver: resolver}, nil
 }
 
+func parseQuery(text string) (antlr.ParseTree, *errorListener) {
+	parseMutex.Lock()
+	defer parseMutex.Unlock()
+
+	errListener := &errorListener{}
+	input := antlr.NewInputStream(text)
+	lexer := gen.NewContactQLLexer(input)
+	stream := antlr.NewCommonTokenStream(lexer, 0)
+	p := gen.NewContactQLParser(stream)
+	p.RemoveErrorListeners()
+	p.AddErrorListener(errListener)
+	return p.Parse(), errListener
+}
+
 type errorListener struct {
 	*antlr.DefaultErrorListener
 
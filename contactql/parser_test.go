@@ -1,6 +1,8 @@
 package contactql_test
 
 import (
+	"fmt"
+	"sync"
 	"testing"
 
 	"github.com/nyaruka/goflow/assets"
@@ -240,6 +242,36 @@ func TestParseQuery(t *testing.T) {
 	}
 }
 
+func TestParseQueryConcurrently(t *testing.T) {
+	env := envs.NewBuilder().Build()
+
+	queries := []string{
+		`age > %d OR name ~ "bob"`,
+		`(gender = "f%d" AND age < 20) OR tel != ""`,
+		`created_on >= "2020-01-%02d"`,
+		`group = "Testers %d" and language != eng`,
+		`fields.score <= %d`,
+	}
+
+	// run with -race to check that queries being parsed at the same time don't share any state
+	start := make(chan bool)
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+
+			for _, query := range queries {
+				_, err := contactql.ParseQuery(env, fmt.Sprintf(query, i+1), nil)
+				assert.NoError(t, err)
+			}
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+}
+
 func TestParsingErrors(t *testing.T) {
 	tests := []struct {
 		query    string
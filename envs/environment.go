@@ -36,6 +36,7 @@ type Environment interface {
 	NumberFormat() *NumberFormat
 	RedactionPolicy() RedactionPolicy
 	MaxValueLength() int
+	WrittenNumbers() bool
 
 	DefaultLanguage() Language
 	DefaultLocale() Locale
@@ -57,6 +58,7 @@ type environment struct {
 	numberFormat     *NumberFormat
 	redactionPolicy  RedactionPolicy
 	maxValueLength   int
+	writtenNumbers   bool
 }
 
 func (e *environment) DateFormat() DateFormat           { return e.dateFormat }
@@ -67,6 +69,7 @@ func (e *environment) DefaultCountry() Country          { return e.defaultCountr
 func (e *environment) NumberFormat() *NumberFormat      { return e.numberFormat }
 func (e *environment) RedactionPolicy() RedactionPolicy { return e.redactionPolicy }
 func (e *environment) MaxValueLength() int              { return e.maxValueLength }
+func (e *environment) WrittenNumbers() bool             { return e.writtenNumbers }
 
 // DefaultLanguage is the first allowed language
 func (e *environment) DefaultLanguage() Language {
@@ -106,6 +109,7 @@ type envEnvelope struct {
 	DefaultCountry   Country         `json:"default_country,omitempty" validate:"omitempty,country"`
 	RedactionPolicy  RedactionPolicy `json:"redaction_policy" validate:"omitempty,eq=none|eq=urns"`
 	MaxValuelength   int             `json:"max_value_length"`
+	WrittenNumbers   bool            `json:"written_numbers,omitempty"`
 }
 
 // ReadEnvironment reads an environment from the given JSON
@@ -125,6 +129,7 @@ func ReadEnvironment(data json.RawMessage) (Environment, error) {
 	env.numberFormat = envelope.NumberFormat
 	env.redactionPolicy = envelope.RedactionPolicy
 	env.maxValueLength = envelope.MaxValuelength
+	env.writtenNumbers = envelope.WrittenNumbers
 
 	tz, err := time.LoadLocation(envelope.Timezone)
 	if err != nil {
@@ -145,6 +150,7 @@ func (e *environment) toEnvelope() *envEnvelope {
 		NumberFormat:     e.numberFormat,
 		RedactionPolicy:  e.redactionPolicy,
 		MaxValuelength:   e.maxValueLength,
+		WrittenNumbers:   e.writtenNumbers,
 	}
 }
 
@@ -220,5 +226,11 @@ func (b *EnvironmentBuilder) WithMaxValueLength(maxValueLength int) *Environment
 	return b
 }
 
+// WithWrittenNumbers sets whether number tests should also look for numbers written as words, e.g. "twenty five"
+func (b *EnvironmentBuilder) WithWrittenNumbers(writtenNumbers bool) *EnvironmentBuilder {
+	b.env.writtenNumbers = writtenNumbers
+	return b
+}
+
 // Build returns the final environment
 func (b *EnvironmentBuilder) Build() Environment { return b.env }
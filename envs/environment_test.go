@@ -46,6 +46,7 @@ func TestEnvironmentMarshaling(t *testing.T) {
 	assert.Nil(t, env.AllowedLanguages())
 	assert.Equal(t, envs.NilCountry, env.DefaultCountry())
 	assert.Equal(t, 640, env.MaxValueLength())
+	assert.False(t, env.WrittenNumbers())
 	assert.Nil(t, env.LocationResolver())
 
 	// can create with valid values
@@ -54,7 +55,8 @@ func TestEnvironmentMarshaling(t *testing.T) {
 		"time_format": "tt:mm:ss", 
 		"allowed_languages": ["eng", "fra"], 
 		"default_country": "RW", 
-		"timezone": "Africa/Kigali"
+		"timezone": "Africa/Kigali",
+		"written_numbers": true
 	}`))
 	assert.NoError(t, err)
 	assert.Equal(t, envs.DateFormatDayMonthYear, env.DateFormat())
@@ -64,11 +66,12 @@ func TestEnvironmentMarshaling(t *testing.T) {
 	assert.Equal(t, []envs.Language{envs.Language("eng"), envs.Language("fra")}, env.AllowedLanguages())
 	assert.Equal(t, envs.Country("RW"), env.DefaultCountry())
 	assert.Equal(t, "en-RW", env.DefaultLocale().ToBCP47())
+	assert.True(t, env.WrittenNumbers())
 	assert.Nil(t, env.LocationResolver())
 
 	data, err := jsonx.Marshal(env)
 	require.NoError(t, err)
-	assert.Equal(t, string(data), `{"date_format":"DD-MM-YYYY","time_format":"tt:mm:ss","timezone":"Africa/Kigali","allowed_languages":["eng","fra"],"number_format":{"decimal_symbol":".","digit_grouping_symbol":","},"default_country":"RW","redaction_policy":"none","max_value_length":640}`)
+	assert.Equal(t, string(data), `{"date_format":"DD-MM-YYYY","time_format":"tt:mm:ss","timezone":"Africa/Kigali","allowed_languages":["eng","fra"],"number_format":{"decimal_symbol":".","digit_grouping_symbol":","},"default_country":"RW","redaction_policy":"none","max_value_length":640,"written_numbers":true}`)
 }
 
 func TestEnvironmentEqual(t *testing.T) {
@@ -107,6 +110,7 @@ func TestEnvironmentBuilder(t *testing.T) {
 		WithNumberFormat(&envs.NumberFormat{DecimalSymbol: "'"}).
 		WithRedactionPolicy(envs.RedactionPolicyURNs).
 		WithMaxValueLength(1024).
+		WithWrittenNumbers(true).
 		Build()
 
 	assert.Equal(t, envs.DateFormatDayMonthYear, env.DateFormat())
@@ -117,5 +121,6 @@ func TestEnvironmentBuilder(t *testing.T) {
 	assert.Equal(t, &envs.NumberFormat{DecimalSymbol: "'"}, env.NumberFormat())
 	assert.Equal(t, envs.RedactionPolicyURNs, env.RedactionPolicy())
 	assert.Equal(t, 1024, env.MaxValueLength())
+	assert.True(t, env.WrittenNumbers())
 	assert.Nil(t, env.LocationResolver())
 }
@@ -2,13 +2,32 @@ package excellent
 
 import (
 	"strings"
+	"sync"
 
 	"github.com/antlr/antlr4/runtime/Go/antlr"
 	"github.com/nyaruka/goflow/excellent/gen"
 )
 
-// VisitExpression parses and visits the given expression with the given visitor
+// the generated lexer and parser share DFA caches between all instances which the ANTLR runtime updates without
+// any locking, so only one expression can be parsed at a time
+var parseMutex sync.Mutex
+
+// VisitExpression parses and visits the given expression with the given visitor. It's safe to call concurrently.
 func VisitExpression(expression string, visitor antlr.ParseTreeVisitor) (interface{}, error) {
+	tree, errListener := parseExpression(expression)
+
+	// if we ran into errors parsing, return the first one
+	if len(errListener.Errors()) > 0 {
+		return nil, errListener.Errors()[0]
+	}
+
+	return visitor.Visit(tree), nil
+}
+
+func parseExpression(expression string) (antlr.ParseTree, *ErrorListener) {
+	parseMutex.Lock()
+	defer parseMutex.Unlock()
+
 	errListener := NewErrorListener(expression)
 
 	input := antlr.NewInputStream(expression)
@@ -17,14 +36,7 @@ func VisitExpression(expression string, visitor antlr.ParseTreeVisitor) (interfa
 	p := gen.NewExcellent2Parser(stream)
 	p.RemoveErrorListeners()
 	p.AddErrorListener(errListener)
-	tree := p.Parse()
-
-	// if we ran into errors parsing, return the first one
-	if len(errListener.Errors()) > 0 {
-		return nil, errListener.Errors()[0]
-	}
-
-	return visitor.Visit(tree), nil
+	return p.Parse(), errListener
 }
 
 // VisitTemplate scans the given template and calls the callback for each token encountered
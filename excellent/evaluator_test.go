@@ -1,7 +1,9 @@
 package excellent_test
 
 import (
+	"fmt"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/nyaruka/goflow/envs"
@@ -392,6 +394,42 @@ func TestEvaluationErrors(t *testing.T) {
 	}
 }
 
+func TestEvaluateConcurrently(t *testing.T) {
+	vars := types.NewXObject(map[string]types.XValue{
+		"foo": types.NewXText("bar"),
+	})
+	env := envs.NewBuilder().Build()
+
+	templates := []string{
+		`@(upper(foo) & %d)`,
+		`@(%d + 2 * 3 >= 5)`,
+		`@(if(foo = "bar", %d, "no"))`,
+		`@(array(1, 2, %d)[0])`,
+		`@(-%d / 2.5)`,
+		`@(foo != "%d")`,
+		`@(join(array("a", "%d"), ","))`,
+		`@(text_length("%d") <= 1)`,
+	}
+
+	// run with -race to check that evaluations running at the same time don't share any state
+	start := make(chan bool)
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+
+			for _, template := range templates {
+				_, err := excellent.EvaluateTemplate(env, vars, fmt.Sprintf(template, i), nil)
+				assert.NoError(t, err)
+			}
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+}
+
 func BenchmarkEvaluationErrors(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		vars := types.NewXObject(map[string]types.XValue{
@@ -0,0 +1,71 @@
+package actions
+
+import (
+	"context"
+
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/events"
+)
+
+func init() {
+	registerType(TypeAddTicketNote, func() flows.Action { return &AddTicketNoteAction{} })
+}
+
+// TypeAddTicketNote is the type for the add ticket note action
+const TypeAddTicketNote string = "add_ticket_note"
+
+// AddTicketNoteAction is used to add a note to the contact's current ticket. A [event:ticket_note_added] event will be
+// created if the note is added successfully.
+//
+//   {
+//     "uuid": "8eebd020-1af5-431c-b943-aa670fc74da9",
+//     "type": "add_ticket_note",
+//     "note": "Customer says: @input.text"
+//   }
+//
+// @action add_ticket_note
+type AddTicketNoteAction struct {
+	baseAction
+	onlineAction
+
+	Note string `json:"note" validate:"required" engine:"evaluated"`
+}
+
+// NewAddTicketNote creates a new add ticket note action
+func NewAddTicketNote(uuid flows.ActionUUID, note string) *AddTicketNoteAction {
+	return &AddTicketNoteAction{
+		baseAction: newBaseAction(TypeAddTicketNote, uuid),
+		Note:       note,
+	}
+}
+
+// Execute runs this action
+func (a *AddTicketNoteAction) Execute(run flows.FlowRun, step flows.Step, logModifier flows.ModifierCallback, logEvent flows.EventCallback) error {
+	return a.ExecuteWithContext(context.Background(), run, step, logModifier, logEvent)
+}
+
+// ExecuteWithContext runs this action, passing the given context to the ticket service
+func (a *AddTicketNoteAction) ExecuteWithContext(ctx context.Context, run flows.FlowRun, step flows.Step, logModifier flows.ModifierCallback, logEvent flows.EventCallback) error {
+	ticket := currentTicket(run, logEvent)
+	if ticket == nil {
+		return nil
+	}
+
+	note, err := run.EvaluateTemplate(a.Note)
+	if err != nil {
+		logEvent(events.NewError(err))
+	}
+	if note == "" {
+		logEvent(events.NewErrorf("ticket note evaluated to empty string, skipping"))
+		return nil
+	}
+
+	added := updateTicket(run, ticket, logEvent, func(svc flows.TicketService, logHTTP flows.HTTPLogCallback) error {
+		return addTicketNote(ctx, svc, run.Session(), ticket, note, logHTTP)
+	})
+	if added {
+		logEvent(events.NewTicketNoteAdded(ticket, note))
+	}
+
+	return nil
+}
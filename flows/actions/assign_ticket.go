@@ -0,0 +1,79 @@
+package actions
+
+import (
+	"context"
+
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/events"
+)
+
+func init() {
+	registerType(TypeAssignTicket, func() flows.Action { return &AssignTicketAction{} })
+}
+
+// TypeAssignTicket is the type for the assign ticket action
+const TypeAssignTicket string = "assign_ticket"
+
+// AssignTicketAction is used to assign the contact's current ticket to a user, or to unassign it if no assignee is
+// given. The assignee can be a fixed user or an expression which evaluates to the email address of a user. A
+// [event:ticket_assigned] event will be created if the ticket is assigned successfully.
+//
+//   {
+//     "uuid": "8eebd020-1af5-431c-b943-aa670fc74da9",
+//     "type": "assign_ticket",
+//     "assignee": {"email": "bob@nyaruka.com", "name": "Bob McTickets"}
+//   }
+//
+// @action assign_ticket
+type AssignTicketAction struct {
+	baseAction
+	onlineAction
+
+	Assignee *assets.UserReference `json:"assignee" validate:"omitempty,dive"`
+}
+
+// NewAssignTicket creates a new assign ticket action
+func NewAssignTicket(uuid flows.ActionUUID, assignee *assets.UserReference) *AssignTicketAction {
+	return &AssignTicketAction{
+		baseAction: newBaseAction(TypeAssignTicket, uuid),
+		Assignee:   assignee,
+	}
+}
+
+// Execute runs this action
+func (a *AssignTicketAction) Execute(run flows.FlowRun, step flows.Step, logModifier flows.ModifierCallback, logEvent flows.EventCallback) error {
+	return a.ExecuteWithContext(context.Background(), run, step, logModifier, logEvent)
+}
+
+// ExecuteWithContext runs this action, passing the given context to the ticket service
+func (a *AssignTicketAction) ExecuteWithContext(ctx context.Context, run flows.FlowRun, step flows.Step, logModifier flows.ModifierCallback, logEvent flows.EventCallback) error {
+	ticket := currentTicket(run, logEvent)
+	if ticket == nil {
+		return nil
+	}
+
+	var assignee *flows.User
+	if a.Assignee != nil {
+		assignee = resolveUser(run, a.Assignee, logEvent)
+		if assignee == nil {
+			return nil
+		}
+	}
+
+	// nothing to do if ticket is already assigned to this user
+	if ticket.Assignee() == assignee {
+		return nil
+	}
+
+	assigned := updateTicket(run, ticket, logEvent, func(svc flows.TicketService, logHTTP flows.HTTPLogCallback) error {
+		return assignTicket(ctx, svc, run.Session(), ticket, assignee, logHTTP)
+	})
+	if assigned {
+		ticket.SetAssignee(assignee)
+
+		logEvent(events.NewTicketAssigned(ticket, assignee))
+	}
+
+	return nil
+}
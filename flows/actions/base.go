@@ -1,11 +1,15 @@
 package actions
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/nyaruka/gocommon/dates"
 	"github.com/nyaruka/gocommon/urns"
@@ -18,6 +22,7 @@ import (
 	"github.com/nyaruka/goflow/utils"
 
 	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
 )
 
 // max number of bytes to be saved to extra on a result
@@ -80,9 +85,20 @@ func (a *baseAction) LocalizationUUID() uuids.UUID { return uuids.UUID(a.UUID_)
 
 // helper function for actions that send a message (text + attachments) that must be localized and evalulated
 func (a *baseAction) evaluateMessage(run flows.FlowRun, languages []envs.Language, actionText string, actionAttachments []string, actionQuickReplies []string, logEvent flows.EventCallback) (string, []utils.Attachment, []string) {
+	return a.evaluateMessageWith(run, run.EvaluateTemplate, languages, actionText, actionAttachments, actionQuickReplies, logEvent)
+}
+
+// helper function for actions that send a message to a contact other than the run contact, evaluating it as if that were the run contact
+func (a *baseAction) evaluateMessageForContact(run flows.FlowRun, contact *flows.Contact, languages []envs.Language, actionText string, actionAttachments []string, actionQuickReplies []string, logEvent flows.EventCallback) (string, []utils.Attachment, []string) {
+	evaluate := func(template string) (string, error) { return run.EvaluateTemplateForContact(contact, template) }
+
+	return a.evaluateMessageWith(run, evaluate, languages, actionText, actionAttachments, actionQuickReplies, logEvent)
+}
+
+func (a *baseAction) evaluateMessageWith(run flows.FlowRun, evaluate func(string) (string, error), languages []envs.Language, actionText string, actionAttachments []string, actionQuickReplies []string, logEvent flows.EventCallback) (string, []utils.Attachment, []string) {
 	// localize and evaluate the message text
 	localizedText := run.GetTranslatedTextArray(uuids.UUID(a.UUID()), "text", []string{actionText}, languages)[0]
-	evaluatedText, err := run.EvaluateTemplate(localizedText)
+	evaluatedText, err := evaluate(localizedText)
 	if err != nil {
 		logEvent(events.NewError(err))
 	}
@@ -91,7 +107,7 @@ func (a *baseAction) evaluateMessage(run flows.FlowRun, languages []envs.Languag
 	translatedAttachments := run.GetTranslatedTextArray(uuids.UUID(a.UUID()), "attachments", actionAttachments, languages)
 	evaluatedAttachments := make([]utils.Attachment, 0, len(translatedAttachments))
 	for _, a := range translatedAttachments {
-		evaluatedAttachment, err := run.EvaluateTemplate(a)
+		evaluatedAttachment, err := evaluate(a)
 		if err != nil {
 			logEvent(events.NewError(err))
 		}
@@ -110,7 +126,7 @@ func (a *baseAction) evaluateMessage(run flows.FlowRun, languages []envs.Languag
 	translatedQuickReplies := run.GetTranslatedTextArray(uuids.UUID(a.UUID()), "quick_replies", actionQuickReplies, languages)
 	evaluatedQuickReplies := make([]string, 0, len(translatedQuickReplies))
 	for _, qr := range translatedQuickReplies {
-		evaluatedQuickReply, err := run.EvaluateTemplate(qr)
+		evaluatedQuickReply, err := evaluate(qr)
 		if err != nil {
 			logEvent(events.NewError(err))
 		}
@@ -149,6 +165,22 @@ func (a *baseAction) saveWebhookResult(run flows.FlowRun, step flows.Step, name
 	a.saveResult(run, step, name, value, category, "", input, extra, logEvent)
 }
 
+// helper to save a run result for a contact found or created by an action, with the contact's context as extra
+func (a *baseAction) saveContactResult(run flows.FlowRun, step flows.Step, name string, contact *flows.Contact, input string, logEvent flows.EventCallback) {
+	if contact == nil {
+		a.saveResult(run, step, name, "", CategoryFailure, "", input, nil, logEvent)
+		return
+	}
+
+	var extra json.RawMessage
+	asJSON, _ := types.ToXJSON(flows.Context(run.Environment(), contact))
+	if len(asJSON.Native()) < resultExtraMaxBytes {
+		extra = json.RawMessage(asJSON.Native())
+	}
+
+	a.saveResult(run, step, name, string(contact.UUID()), CategorySuccess, "", input, extra, logEvent)
+}
+
 func (a *baseAction) updateWebhook(run flows.FlowRun, call *flows.WebhookCall) {
 	parsed := types.JSONToXValue(call.ResponseBody)
 
@@ -162,8 +194,183 @@ func (a *baseAction) updateWebhook(run flows.FlowRun, call *flows.WebhookCall) {
 
 // helper to apply a contact modifier
 func (a *baseAction) applyModifier(run flows.FlowRun, mod flows.Modifier, logModifier flows.ModifierCallback, logEvent flows.EventCallback) {
-	mod.Apply(run.Environment(), run.Session().Assets(), run.Contact(), logEvent)
-	logModifier(mod)
+	for _, m := range run.Session().Engine().Middleware().Modifier(run.Session(), mod) {
+		m.Apply(run.Environment(), run.Session().Assets(), run.Contact(), logEvent)
+		logModifier(m)
+	}
+}
+
+// helper to make a webhook call, passing on the context if the service supports it
+func callWebhook(ctx context.Context, svc flows.WebhookService, session flows.Session, request *http.Request) (call *flows.WebhookCall, err error) {
+	defer recordServiceCall(session, "webhook", time.Now())
+
+	flows.Blocking(ctx, func() {
+		if cs, ok := svc.(flows.ContextWebhookService); ok {
+			call, err = cs.CallWithContext(ctx, session, request)
+		} else {
+			call, err = svc.Call(session, request)
+		}
+	})
+	return call, err
+}
+
+// helper to classify input, passing on the context if the service supports it
+func classify(ctx context.Context, svc flows.ClassificationService, session flows.Session, input string, logHTTP flows.HTTPLogCallback) (classification *flows.Classification, err error) {
+	defer recordServiceCall(session, "classifier", time.Now())
+
+	flows.Blocking(ctx, func() {
+		if cs, ok := svc.(flows.ContextClassificationService); ok {
+			classification, err = cs.ClassifyWithContext(ctx, session, input, logHTTP)
+		} else {
+			classification, err = svc.Classify(session, input, logHTTP)
+		}
+	})
+	return classification, err
+}
+
+// helper to transfer airtime, passing on the context if the service supports it
+func transferAirtime(ctx context.Context, svc flows.AirtimeService, session flows.Session, sender, recipient urns.URN, amounts map[string]decimal.Decimal, logHTTP flows.HTTPLogCallback) (transfer *flows.AirtimeTransfer, err error) {
+	defer recordServiceCall(session, "airtime", time.Now())
+
+	flows.Blocking(ctx, func() {
+		if cs, ok := svc.(flows.ContextAirtimeService); ok {
+			transfer, err = cs.TransferWithContext(ctx, session, sender, recipient, amounts, logHTTP)
+		} else {
+			transfer, err = svc.Transfer(session, sender, recipient, amounts, logHTTP)
+		}
+	})
+	return transfer, err
+}
+
+// helper to open a ticket, passing on the context if the service supports it
+func openTicket(ctx context.Context, svc flows.TicketService, session flows.Session, topic *flows.Topic, body string, assignee *flows.User, logHTTP flows.HTTPLogCallback) (ticket *flows.Ticket, err error) {
+	defer recordServiceCall(session, "ticket", time.Now())
+
+	flows.Blocking(ctx, func() {
+		if cs, ok := svc.(flows.ContextTicketService); ok {
+			ticket, err = cs.OpenWithContext(ctx, session, topic, body, assignee, logHTTP)
+		} else {
+			ticket, err = svc.Open(session, topic, body, assignee, logHTTP)
+		}
+	})
+	return ticket, err
+}
+
+// helper to add a note to a ticket, passing on the context if the service supports it
+func addTicketNote(ctx context.Context, svc flows.TicketService, session flows.Session, ticket *flows.Ticket, note string, logHTTP flows.HTTPLogCallback) (err error) {
+	defer recordServiceCall(session, "ticket", time.Now())
+
+	flows.Blocking(ctx, func() {
+		if cs, ok := svc.(flows.ContextTicketService); ok {
+			err = cs.AddNoteWithContext(ctx, session, ticket, note, logHTTP)
+		} else {
+			err = svc.AddNote(session, ticket, note, logHTTP)
+		}
+	})
+	return err
+}
+
+// helper to assign a ticket, passing on the context if the service supports it
+func assignTicket(ctx context.Context, svc flows.TicketService, session flows.Session, ticket *flows.Ticket, assignee *flows.User, logHTTP flows.HTTPLogCallback) (err error) {
+	defer recordServiceCall(session, "ticket", time.Now())
+
+	flows.Blocking(ctx, func() {
+		if cs, ok := svc.(flows.ContextTicketService); ok {
+			err = cs.AssignWithContext(ctx, session, ticket, assignee, logHTTP)
+		} else {
+			err = svc.Assign(session, ticket, assignee, logHTTP)
+		}
+	})
+	return err
+}
+
+// helper to change the topic of a ticket, passing on the context if the service supports it
+func changeTicketTopic(ctx context.Context, svc flows.TicketService, session flows.Session, ticket *flows.Ticket, topic *flows.Topic, logHTTP flows.HTTPLogCallback) (err error) {
+	defer recordServiceCall(session, "ticket", time.Now())
+
+	flows.Blocking(ctx, func() {
+		if cs, ok := svc.(flows.ContextTicketService); ok {
+			err = cs.ChangeTopicWithContext(ctx, session, ticket, topic, logHTTP)
+		} else {
+			err = svc.ChangeTopic(session, ticket, topic, logHTTP)
+		}
+	})
+	return err
+}
+
+// helper to close a ticket, passing on the context if the service supports it
+func closeTicket(ctx context.Context, svc flows.TicketService, session flows.Session, ticket *flows.Ticket, logHTTP flows.HTTPLogCallback) (err error) {
+	defer recordServiceCall(session, "ticket", time.Now())
+
+	flows.Blocking(ctx, func() {
+		if cs, ok := svc.(flows.ContextTicketService); ok {
+			err = cs.CloseWithContext(ctx, session, ticket, logHTTP)
+		} else {
+			err = svc.Close(session, ticket, logHTTP)
+		}
+	})
+	return err
+}
+
+// helper to reopen a ticket, passing on the context if the service supports it
+func reopenTicket(ctx context.Context, svc flows.TicketService, session flows.Session, ticket *flows.Ticket, logHTTP flows.HTTPLogCallback) (err error) {
+	defer recordServiceCall(session, "ticket", time.Now())
+
+	flows.Blocking(ctx, func() {
+		if cs, ok := svc.(flows.ContextTicketService); ok {
+			err = cs.ReopenWithContext(ctx, session, ticket, logHTTP)
+		} else {
+			err = svc.Reopen(session, ticket, logHTTP)
+		}
+	})
+	return err
+}
+
+// helper to look up a contact by URN, passing on the context if the service supports it
+func lookupContactByURN(ctx context.Context, svc flows.ContactService, session flows.Session, urn urns.URN) (contact *flows.Contact, err error) {
+	defer recordServiceCall(session, "contact", time.Now())
+
+	flows.Blocking(ctx, func() {
+		if cs, ok := svc.(flows.ContextContactService); ok {
+			contact, err = cs.LookupByURNWithContext(ctx, session, urn)
+		} else {
+			contact, err = svc.LookupByURN(session, urn)
+		}
+	})
+	return contact, err
+}
+
+// helper to look up a contact by field value, passing on the context if the service supports it
+func lookupContactByField(ctx context.Context, svc flows.ContactService, session flows.Session, field *flows.Field, value string) (contact *flows.Contact, err error) {
+	defer recordServiceCall(session, "contact", time.Now())
+
+	flows.Blocking(ctx, func() {
+		if cs, ok := svc.(flows.ContextContactService); ok {
+			contact, err = cs.LookupByFieldWithContext(ctx, session, field, value)
+		} else {
+			contact, err = svc.LookupByField(session, field, value)
+		}
+	})
+	return contact, err
+}
+
+// helper to create a contact, passing on the context if the service supports it
+func createContact(ctx context.Context, svc flows.ContactService, session flows.Session, name string, language envs.Language, urnz []urns.URN) (contact *flows.Contact, err error) {
+	defer recordServiceCall(session, "contact", time.Now())
+
+	flows.Blocking(ctx, func() {
+		if cs, ok := svc.(flows.ContextContactService); ok {
+			contact, err = cs.CreateWithContext(ctx, session, name, language, urnz)
+		} else {
+			contact, err = svc.Create(session, name, language, urnz)
+		}
+	})
+	return contact, err
+}
+
+// helper to report the duration of a call to a service which started at the given time
+func recordServiceCall(session flows.Session, service string, start time.Time) {
+	session.Engine().Metrics().ObserveHistogram(flows.MetricServiceCallDuration, time.Since(start).Seconds(), map[string]string{"service": service})
 }
 
 // helper to log a failure
@@ -280,6 +487,43 @@ type createMsgAction struct {
 	QuickReplies []string `json:"quick_replies,omitempty" engine:"localized,evaluated"`
 }
 
+// applies the message policy of the given channel to the evaluated content of a message to the given URN, logging an
+// error for each change made, and returning the text split into parts and the quick replies to send. Text which can't
+// be split, e.g. because it's the content of a template, is always returned as a single part.
+func (a *createMsgAction) applyMsgPolicy(channel *flows.Channel, urn urns.URN, text string, canSplit bool, quickReplies []string, logEvent flows.EventCallback) ([]string, []string) {
+	if channel == nil {
+		return []string{text}, quickReplies
+	}
+
+	policy := channel.MsgPolicy(urn.Scheme())
+	parts := []string{text}
+
+	if canSplit && policy.MaxTextLength > 0 && utf8.RuneCountInString(text) > policy.MaxTextLength {
+		parts = utils.SplitText(text, policy.MaxTextLength)
+		logEvent(events.NewCodedErrorf(flows.ErrorCodeMsgTextSplit, "message text is longer than the %d character limit of channel '%s', split into %d parts", policy.MaxTextLength, channel.Name(), len(parts)))
+	}
+
+	if policy.MaxQuickReplies > 0 && len(quickReplies) > policy.MaxQuickReplies {
+		logEvent(events.NewCodedErrorf(flows.ErrorCodeMsgQuickRepliesDropped, "channel '%s' only supports %d quick replies, dropping %d", channel.Name(), policy.MaxQuickReplies, len(quickReplies)-policy.MaxQuickReplies))
+		quickReplies = quickReplies[:policy.MaxQuickReplies]
+	}
+
+	if policy.MaxQuickReplyLength > 0 {
+		policedQuickReplies := make([]string, len(quickReplies))
+		for i, qr := range quickReplies {
+			policedQuickReplies[i] = qr
+
+			if utf8.RuneCountInString(qr) > policy.MaxQuickReplyLength {
+				policedQuickReplies[i] = utils.Truncate(qr, policy.MaxQuickReplyLength)
+				logEvent(events.NewCodedErrorf(flows.ErrorCodeMsgQuickReplyTruncated, "quick reply '%s' is longer than the %d character limit of channel '%s', truncating", qr, policy.MaxQuickReplyLength, channel.Name()))
+			}
+		}
+		quickReplies = policedQuickReplies
+	}
+
+	return parts, quickReplies
+}
+
 // helper function for actions that have a set of group references that must be resolved to actual groups
 func resolveGroups(run flows.FlowRun, references []*assets.GroupReference, logEvent flows.EventCallback) []*flows.Group {
 	groupAssets := run.Session().Assets().Groups()
@@ -380,6 +624,52 @@ func resolveUser(run flows.FlowRun, ref *assets.UserReference, logEvent flows.Ev
 	return user
 }
 
+// helper to get the contact's current ticket, which is the one most recently opened, logging an error if there isn't one
+func currentTicket(run flows.FlowRun, logEvent flows.EventCallback) *flows.Ticket {
+	if run.Contact() == nil {
+		logEvent(events.NewErrorf("can't execute action in session without a contact"))
+		return nil
+	}
+
+	ticket := run.Contact().Tickets().Last()
+	if ticket == nil {
+		logEvent(events.NewErrorf("contact has no ticket to update"))
+	}
+	return ticket
+}
+
+// helper to update the given ticket using the ticket service of its ticketer, returning whether the update succeeded
+func updateTicket(run flows.FlowRun, ticket *flows.Ticket, logEvent flows.EventCallback, update func(flows.TicketService, flows.HTTPLogCallback) error) bool {
+	if run.Session().BatchStart() {
+		logEvent(events.NewErrorf("can't update tickets during batch starts"))
+		return false
+	}
+
+	ticketer := ticket.Ticketer()
+	if ticketer == nil {
+		logEvent(events.NewErrorf("ticketer of ticket %s no longer exists", ticket.UUID()))
+		return false
+	}
+
+	svc, err := run.Session().Engine().Services().Ticket(run.Session(), ticketer)
+	if err != nil {
+		logEvent(events.NewError(err))
+		return false
+	}
+
+	httpLogger := &flows.HTTPLogger{}
+
+	err = update(svc, httpLogger.Log)
+	if err != nil {
+		logEvent(events.NewError(err))
+	}
+	if len(httpLogger.Logs) > 0 {
+		logEvent(events.NewTicketerCalled(ticketer.Reference(), httpLogger.Logs))
+	}
+
+	return err == nil
+}
+
 //------------------------------------------------------------------------------------------
 // JSON Encoding / Decoding
 //------------------------------------------------------------------------------------------
@@ -81,6 +81,7 @@ func testActionType(t *testing.T, assetsJSON json.RawMessage, typeName string) {
 		SMTPError    string               `json:"smtp_error,omitempty"`
 		NoContact    bool                 `json:"no_contact,omitempty"`
 		NoURNs       bool                 `json:"no_urns,omitempty"`
+		Tickets      []json.RawMessage    `json:"tickets,omitempty"`
 		NoInput      bool                 `json:"no_input,omitempty"`
 		RedactURNs   bool                 `json:"redact_urns,omitempty"`
 		AsBatch      bool                 `json:"as_batch,omitempty"`
@@ -172,6 +173,13 @@ func testActionType(t *testing.T, assetsJSON json.RawMessage, typeName string) {
 				contact.AddURN(urns.URN("twitterid:54784326227#nyaruka"), nil)
 			}
 
+			// optionally give our contact some tickets
+			for _, ticketJSON := range tc.Tickets {
+				ticket, err := flows.ReadTicket(sa, ticketJSON, assets.PanicOnMissing)
+				require.NoError(t, err)
+				contact.Tickets().Add(ticket)
+			}
+
 			// and switch their language
 			if tc.Localization != nil {
 				contact.SetLanguage(envs.Language("spa"))
@@ -232,6 +240,9 @@ func testActionType(t *testing.T, assetsJSON json.RawMessage, typeName string) {
 			WithAirtimeServiceFactory(func(flows.Session) (flows.AirtimeService, error) {
 				return dtone.NewService(http.DefaultClient, nil, "nyaruka", "123456789"), nil
 			}).
+			WithContactServiceFactory(func(flows.Session) (flows.ContactService, error) {
+				return test.NewContactService(), nil
+			}).
 			Build()
 
 		// create session
@@ -435,6 +446,130 @@ func TestConstructors(t *testing.T) {
 			"result_name": "Webhook Response"
 		}`,
 		},
+		{
+			actions.NewCallHTTP(
+				actionUUID,
+				"POST",
+				"http://example.com/orders",
+				map[string]string{
+					"Content-Type": "application/json",
+				},
+				`{"contact_id": 234}`, // body
+				assets.NewAuthProfileReference(assets.AuthProfileUUID("a8ea3f5b-4f29-4b19-9ad1-4c13f7a67e4b"), "Shop API"),
+				&actions.HTTPRetry{MaxRetries: 2, Statuses: []int{429, 503}, DelaySeconds: 1},
+				map[string]string{"Order ID": "$.order.id"},
+				"Order Response",
+			),
+			`{
+			"type": "call_http",
+			"uuid": "ad154980-7bf7-4ab8-8728-545fd6378912",
+			"method": "POST",
+			"url": "http://example.com/orders",
+			"headers": {
+				"Content-Type": "application/json"
+			},
+			"body": "{\"contact_id\": 234}",
+			"auth_profile": {
+				"uuid": "a8ea3f5b-4f29-4b19-9ad1-4c13f7a67e4b",
+				"name": "Shop API"
+			},
+			"retry": {
+				"max_retries": 2,
+				"statuses": [429, 503],
+				"delay_seconds": 1
+			},
+			"extract": {
+				"Order ID": "$.order.id"
+			},
+			"result_name": "Order Response"
+		}`,
+		},
+		{
+			actions.NewLookupContactByURN(
+				actionUUID,
+				"tel:@results.referrer_phone",
+				"Referrer",
+			),
+			`{
+			"type": "lookup_contact",
+			"uuid": "ad154980-7bf7-4ab8-8728-545fd6378912",
+			"urn": "tel:@results.referrer_phone",
+			"result_name": "Referrer"
+		}`,
+		},
+		{
+			actions.NewLookupContactByField(
+				actionUUID,
+				assets.NewFieldReference("national_id", "National ID"),
+				"@results.id_number",
+				"Referrer",
+			),
+			`{
+			"type": "lookup_contact",
+			"uuid": "ad154980-7bf7-4ab8-8728-545fd6378912",
+			"field": {
+				"key": "national_id",
+				"name": "National ID"
+			},
+			"value": "@results.id_number",
+			"result_name": "Referrer"
+		}`,
+		},
+		{
+			actions.NewCreateContact(
+				actionUUID,
+				"@results.friend_name",
+				envs.Language("spa"),
+				[]string{"tel:@results.friend_phone"},
+				"Friend",
+			),
+			`{
+			"type": "create_contact",
+			"uuid": "ad154980-7bf7-4ab8-8728-545fd6378912",
+			"name": "@results.friend_name",
+			"language": "spa",
+			"urns": ["tel:@results.friend_phone"],
+			"result_name": "Friend"
+		}`,
+		},
+		{
+			actions.NewRemoveContactURN(
+				actionUUID,
+				"tel:@results.old_phone",
+			),
+			`{
+			"type": "remove_contact_urn",
+			"uuid": "ad154980-7bf7-4ab8-8728-545fd6378912",
+			"urn": "tel:@results.old_phone"
+		}`,
+		},
+		{
+			actions.NewSetContactPreferredURN(
+				actionUUID,
+				"whatsapp:@fields.whatsapp",
+			),
+			`{
+			"type": "set_contact_preferred_urn",
+			"uuid": "ad154980-7bf7-4ab8-8728-545fd6378912",
+			"urn": "whatsapp:@fields.whatsapp"
+		}`,
+		},
+		{
+			actions.NewSendPersonalizedBroadcast(
+				actionUUID,
+				"Hi @contact.first_name, @run.contact.name has invited you",
+				nil,
+				[]string{"Join"},
+				[]string{"tel:@results.friend_phone"},
+			),
+			`{
+			"type": "send_personalized_broadcast",
+			"uuid": "ad154980-7bf7-4ab8-8728-545fd6378912",
+			"text": "Hi @contact.first_name, @run.contact.name has invited you",
+			"quick_replies": ["Join"],
+			"urns": ["tel:@results.friend_phone"]
+		}`,
+		},
 		{
 			actions.NewOpenTicket(
 				actionUUID,
@@ -463,6 +598,59 @@ func TestConstructors(t *testing.T) {
 				"result_name": "Ticket"
 			}`,
 		},
+		{
+			actions.NewAddTicketNote(
+				actionUUID,
+				"Customer says: @input.text",
+			),
+			`{
+				"uuid": "ad154980-7bf7-4ab8-8728-545fd6378912",
+				"type": "add_ticket_note",
+				"note": "Customer says: @input.text"
+			}`,
+		},
+		{
+			actions.NewAssignTicket(
+				actionUUID,
+				assets.NewUserReference("bob@nyaruka.com", "Bob McTickets"),
+			),
+			`{
+				"uuid": "ad154980-7bf7-4ab8-8728-545fd6378912",
+				"type": "assign_ticket",
+				"assignee": {
+					"email": "bob@nyaruka.com",
+					"name": "Bob McTickets"
+				}
+			}`,
+		},
+		{
+			actions.NewChangeTicketTopic(
+				actionUUID,
+				assets.NewTopicReference("472a7a73-96cb-4736-b567-056d987cc5b4", "Weather"),
+			),
+			`{
+				"uuid": "ad154980-7bf7-4ab8-8728-545fd6378912",
+				"type": "change_ticket_topic",
+				"topic": {
+					"uuid": "472a7a73-96cb-4736-b567-056d987cc5b4",
+					"name": "Weather"
+				}
+			}`,
+		},
+		{
+			actions.NewCloseTicket(actionUUID),
+			`{
+				"uuid": "ad154980-7bf7-4ab8-8728-545fd6378912",
+				"type": "close_ticket"
+			}`,
+		},
+		{
+			actions.NewReopenTicket(actionUUID),
+			`{
+				"uuid": "ad154980-7bf7-4ab8-8728-545fd6378912",
+				"type": "reopen_ticket"
+			}`,
+		},
 		{
 			actions.NewPlayAudio(
 				actionUUID,
@@ -608,6 +796,20 @@ func TestConstructors(t *testing.T) {
 			"value": "Male"
 		}`,
 		},
+		{
+			actions.NewSetContactFields(
+				actionUUID,
+				map[string]string{"gender": "Male", "age": "@results.age"},
+			),
+			`{
+			"type": "set_contact_fields",
+			"uuid": "ad154980-7bf7-4ab8-8728-545fd6378912",
+			"values": {
+				"age": "@results.age",
+				"gender": "Male"
+			}
+		}`,
+		},
 		{
 			actions.NewSetContactLanguage(
 				actionUUID,
@@ -671,6 +873,7 @@ func TestConstructors(t *testing.T) {
 			actions.NewEnterFlow(
 				actionUUID,
 				assets.NewFlowReference(assets.FlowUUID("fece6eac-9127-4343-9269-56e88f391562"), "Parent"),
+				map[string]string{"name": "@contact.name"},
 				true, // terminal
 			),
 			`{
@@ -680,6 +883,9 @@ func TestConstructors(t *testing.T) {
 				"uuid": "fece6eac-9127-4343-9269-56e88f391562",
 				"name": "Parent"
 			},
+			"params": {
+				"name": "@contact.name"
+			},
 			"terminal": true
 		}`,
 		},
@@ -977,3 +1183,90 @@ func TestStartSessionLoopProtectionWithInput(t *testing.T) {
 
 	assert.Equal(t, 10, len(sessions))
 }
+
+func TestCallHTTPReusesOAuth2Token(t *testing.T) {
+	defer httpx.SetRequestor(httpx.DefaultRequestor)
+
+	mocks := httpx.NewMockRequestor(map[string][]httpx.MockResponse{
+		"http://auth.example.com/token": {
+			httpx.NewMockResponse(200, nil, `{"access_token": "abc123", "expires_in": 3600}`),
+		},
+		"http://temba.io/orders": {
+			httpx.NewMockResponse(200, nil, `{"ok": true}`),
+			httpx.NewMockResponse(200, nil, `{"ok": true}`),
+		},
+	})
+	httpx.SetRequestor(mocks)
+
+	env := envs.NewBuilder().Build()
+
+	source, err := static.NewSource([]byte(`{
+		"auth_profiles": [
+			{
+				"uuid": "c4f1e2d3-b5a6-4978-8a9b-0c1d2e3f4a5b",
+				"name": "Partner OAuth",
+				"type": "oauth2",
+				"config": {
+					"token_url": "http://auth.example.com/token",
+					"client_id": "goflow",
+					"client_secret": "s3cret"
+				}
+			}
+		],
+		"flows": [
+			{
+				"uuid": "5472a1c3-63e1-484f-8485-cc8ecb16a058",
+				"name": "Orders",
+				"spec_version": "13.1",
+				"language": "eng",
+				"type": "messaging",
+				"nodes": [
+					{
+						"uuid": "cc49453a-78ed-48a6-8b94-318b46517071",
+						"actions": [
+							{
+								"uuid": "cdf981ae-a9cf-4c32-98f3-65bac07bf990",
+								"type": "call_http",
+								"method": "GET",
+								"url": "http://temba.io/orders",
+								"auth_profile": {"uuid": "c4f1e2d3-b5a6-4978-8a9b-0c1d2e3f4a5b", "name": "Partner OAuth"}
+							},
+							{
+								"uuid": "5a1b0e6d-2d6f-4f3b-9c2e-7a4d1f0c8e93",
+								"type": "call_http",
+								"method": "GET",
+								"url": "http://temba.io/orders",
+								"auth_profile": {"uuid": "c4f1e2d3-b5a6-4978-8a9b-0c1d2e3f4a5b", "name": "Partner OAuth"}
+							}
+						],
+						"exits": [
+							{
+								"uuid": "717ee506-7b2d-4a18-b142-eafed0c5e9d8"
+							}
+						]
+					}
+				]
+			}
+		]
+	}`))
+	require.NoError(t, err)
+
+	sa, err := engine.NewSessionAssets(env, source, nil)
+	require.NoError(t, err)
+
+	flow := assets.NewFlowReference("5472a1c3-63e1-484f-8485-cc8ecb16a058", "Orders")
+	contact := flows.NewEmptyContact(sa, "Bob", envs.Language("eng"), nil)
+
+	_, sprint, err := test.NewEngine().NewSession(sa, triggers.NewBuilder(env, flow, contact).Manual().Build())
+	require.NoError(t, err)
+
+	// token is only fetched once and then reused by the second request
+	urls := make([]string, 0)
+	for _, e := range sprint.Events() {
+		if e.Type() == events.TypeWebhookCalled {
+			urls = append(urls, e.(*events.WebhookCalledEvent).URL)
+		}
+	}
+	assert.Equal(t, []string{"http://auth.example.com/token", "http://temba.io/orders", "http://temba.io/orders"}, urls)
+	assert.False(t, mocks.HasUnused())
+}
@@ -1,6 +1,8 @@
 package actions
 
 import (
+	"context"
+
 	"github.com/nyaruka/gocommon/jsonx"
 	"github.com/nyaruka/goflow/assets"
 	"github.com/nyaruka/goflow/flows"
@@ -53,6 +55,11 @@ func NewCallClassifier(uuid flows.ActionUUID, classifier *assets.ClassifierRefer
 
 // Execute runs this action
 func (a *CallClassifierAction) Execute(run flows.FlowRun, step flows.Step, logModifier flows.ModifierCallback, logEvent flows.EventCallback) error {
+	return a.ExecuteWithContext(context.Background(), run, step, logModifier, logEvent)
+}
+
+// ExecuteWithContext runs this action, passing the given context to any services called
+func (a *CallClassifierAction) ExecuteWithContext(ctx context.Context, run flows.FlowRun, step flows.Step, logModifier flows.ModifierCallback, logEvent flows.EventCallback) error {
 	classifiers := run.Session().Assets().Classifiers()
 	classifier := classifiers.Get(a.Classifier.UUID)
 
@@ -62,7 +69,7 @@ func (a *CallClassifierAction) Execute(run flows.FlowRun, step flows.Step, logMo
 		logEvent(events.NewError(err))
 	}
 
-	classification, skipped := a.classify(run, step, input, classifier, logEvent)
+	classification, skipped := a.classify(ctx, run, step, input, classifier, logEvent)
 	if classification != nil {
 		a.saveSuccess(run, step, input, classification, logEvent)
 	} else if skipped {
@@ -74,7 +81,7 @@ func (a *CallClassifierAction) Execute(run flows.FlowRun, step flows.Step, logMo
 	return nil
 }
 
-func (a *CallClassifierAction) classify(run flows.FlowRun, step flows.Step, input string, classifier *flows.Classifier, logEvent flows.EventCallback) (*flows.Classification, bool) {
+func (a *CallClassifierAction) classify(ctx context.Context, run flows.FlowRun, step flows.Step, input string, classifier *flows.Classifier, logEvent flows.EventCallback) (*flows.Classification, bool) {
 	if input == "" {
 		logEvent(events.NewErrorf("can't classify empty input, skipping classification"))
 		return nil, true
@@ -92,7 +99,7 @@ func (a *CallClassifierAction) classify(run flows.FlowRun, step flows.Step, inpu
 
 	httpLogger := &flows.HTTPLogger{}
 
-	classification, err := svc.Classify(run.Session(), input, httpLogger.Log)
+	classification, err := classify(ctx, svc, run.Session(), input, httpLogger.Log)
 
 	if len(httpLogger.Logs) > 0 {
 		logEvent(events.NewClassifierCalled(classifier.Reference(), httpLogger.Logs))
@@ -0,0 +1,539 @@
+package actions
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nyaruka/gocommon/dates"
+	"github.com/nyaruka/gocommon/httpx"
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/events"
+	"github.com/nyaruka/goflow/utils"
+
+	"github.com/buger/jsonparser"
+	"github.com/pkg/errors"
+	"golang.org/x/net/http/httpguts"
+)
+
+func init() {
+	registerType(TypeCallHTTP, func() flows.Action { return &CallHTTPAction{} })
+}
+
+// TypeCallHTTP is the type for the call HTTP action
+const TypeCallHTTP string = "call_http"
+
+// supported types of auth profile
+const (
+	AuthProfileTypeBasic  = "basic"
+	AuthProfileTypeBearer = "bearer"
+	AuthProfileTypeHMAC   = "hmac"
+	AuthProfileTypeOAuth2 = "oauth2"
+)
+
+// the header used for HMAC signatures if the auth profile doesn't specify one
+const defaultSignatureHeader = "X-Signature"
+
+// CallHTTPAction can be used to make an HTTP request to an external service. Like [action:call_webhook], the body,
+// header and url fields may be templates and a [event:webhook_called] event will be created for the request, but
+// requests can also be authenticated using a reusable [asset:auth_profile], retried if the response has one of the
+// given status codes after a delay which doubles with each retry, and have values extracted from the JSON response into results. Each key of `extract` is the name
+// of a result and each value is a JSON path like `$.order.items[0].id` into the response. An extracted result has the
+// category `Success` if the path was found, and `Failure` if it wasn't. If this action has a `result_name`, then
+// additionally it will create a result with that name whose value is the status code of the last request made.
+//
+//   {
+//     "uuid": "8eebd020-1af5-431c-b943-aa670fc74da9",
+//     "type": "call_http",
+//     "method": "GET",
+//     "url": "http://localhost:49998/?cmd=success",
+//     "auth_profile": {
+//       "uuid": "a8ea3f5b-4f29-4b19-9ad1-4c13f7a67e4b",
+//       "name": "Shop API"
+//     },
+//     "retry": {
+//       "max_retries": 2,
+//       "statuses": [429, 503]
+//     },
+//     "extract": {
+//       "Status": "$.ok"
+//     },
+//     "result_name": "Shop Lookup"
+//   }
+//
+// @action call_http
+type CallHTTPAction struct {
+	baseAction
+	onlineAction
+
+	Method      string                       `json:"method" validate:"required,http_method"`
+	URL         string                       `json:"url" validate:"required" engine:"evaluated"`
+	Headers     map[string]string            `json:"headers,omitempty" engine:"evaluated"`
+	Body        string                       `json:"body,omitempty" engine:"evaluated"`
+	AuthProfile *assets.AuthProfileReference `json:"auth_profile,omitempty" validate:"omitempty,dive"`
+	Retry       *HTTPRetry                   `json:"retry,omitempty" validate:"omitempty,dive"`
+	Extract     map[string]string            `json:"extract,omitempty"`
+	ResultName  string                       `json:"result_name,omitempty"`
+}
+
+// HTTPRetry configures retrying of requests whose responses have one of the given status codes
+type HTTPRetry struct {
+	MaxRetries   int   `json:"max_retries" validate:"required"`
+	Statuses     []int `json:"statuses" validate:"required"`
+	DelaySeconds int   `json:"delay_seconds,omitempty"`
+}
+
+// limits on how many times a request can be retried, and how long in total it can wait between retries
+const (
+	maxHTTPRetries    = 5
+	maxHTTPRetryDelay = 10 * time.Second
+)
+
+func (r *HTTPRetry) validate() error {
+	if r.MaxRetries < 1 || r.MaxRetries > maxHTTPRetries {
+		return errors.Errorf("retry max_retries must be between 1 and %d", maxHTTPRetries)
+	}
+	if r.DelaySeconds < 0 || time.Duration(r.DelaySeconds)*time.Second > maxHTTPRetryDelay {
+		return errors.Errorf("retry delay_seconds must be between 0 and %d", maxHTTPRetryDelay/time.Second)
+	}
+	for _, status := range r.Statuses {
+		if status < 400 || status > 599 {
+			return errors.Errorf("retry status %d isn't an HTTP error status", status)
+		}
+	}
+	return nil
+}
+
+// builds the config for retrying requests, whose backoffs start at our delay and double with each retry
+func (r *HTTPRetry) config() *httpx.RetryConfig {
+	retries := httpx.NewExponentialRetries(time.Duration(r.DelaySeconds)*time.Second, r.MaxRetries, 0)
+
+	// retries happen within the sprint so the total time spent waiting is capped
+	remaining := maxHTTPRetryDelay
+	for i, backoff := range retries.Backoffs {
+		if backoff > remaining {
+			backoff = remaining
+		}
+		retries.Backoffs[i] = backoff
+		remaining -= backoff
+	}
+
+	retries.ShouldRetry = func(request *http.Request, response *http.Response, withDelay time.Duration) bool {
+		if response == nil {
+			return false
+		}
+		for _, status := range r.Statuses {
+			if response.StatusCode == status {
+				return true
+			}
+		}
+		return false
+	}
+	return retries
+}
+
+// NewCallHTTP creates a new call HTTP action
+func NewCallHTTP(uuid flows.ActionUUID, method string, url string, headers map[string]string, body string, authProfile *assets.AuthProfileReference, retry *HTTPRetry, extract map[string]string, resultName string) *CallHTTPAction {
+	return &CallHTTPAction{
+		baseAction:  newBaseAction(TypeCallHTTP, uuid),
+		Method:      method,
+		URL:         url,
+		Headers:     headers,
+		Body:        body,
+		AuthProfile: authProfile,
+		Retry:       retry,
+		Extract:     extract,
+		ResultName:  resultName,
+	}
+}
+
+// Validate validates our action is valid
+func (a *CallHTTPAction) Validate() error {
+	for key := range a.Headers {
+		if !httpguts.ValidHeaderFieldName(key) {
+			return errors.Errorf("header '%s' is not a valid HTTP header", key)
+		}
+	}
+
+	if a.Retry != nil {
+		if err := a.Retry.validate(); err != nil {
+			return err
+		}
+	}
+
+	for name, path := range a.Extract {
+		if name == "" || len(name) > 64 {
+			return errors.Errorf("extracted result name '%s' must be between 1 and 64 characters", name)
+		}
+		if _, err := parseJSONPath(path); err != nil {
+			return errors.Errorf("path '%s' for extracted result '%s' isn't a valid JSON path", path, name)
+		}
+	}
+
+	return nil
+}
+
+// Execute runs this action
+func (a *CallHTTPAction) Execute(run flows.FlowRun, step flows.Step, logModifier flows.ModifierCallback, logEvent flows.EventCallback) error {
+	return a.ExecuteWithContext(context.Background(), run, step, logModifier, logEvent)
+}
+
+// ExecuteWithContext runs this action, passing the given context to any services called
+func (a *CallHTTPAction) ExecuteWithContext(ctx context.Context, run flows.FlowRun, step flows.Step, logModifier flows.ModifierCallback, logEvent flows.EventCallback) error {
+	// substitute any variables in our url
+	url, err := run.EvaluateTemplate(a.URL)
+	if err != nil {
+		logEvent(events.NewError(err))
+	}
+	if url == "" {
+		logEvent(events.NewErrorf("HTTP request URL evaluated to empty string"))
+		return nil
+	}
+	if !isValidURL(url) {
+		logEvent(events.NewErrorf("HTTP request URL evaluated to an invalid URL: '%s'", url))
+		return nil
+	}
+
+	method := strings.ToUpper(a.Method)
+	body := a.Body
+
+	// substitute any body variables
+	if body != "" {
+		// request bodies aren't truncated like other templates
+		body, err = run.EvaluateTemplateText(body, nil, false)
+		if err != nil {
+			logEvent(events.NewError(err))
+		}
+	}
+
+	// substitute any header variables
+	headers := make(map[string]string, len(a.Headers))
+	for key, value := range a.Headers {
+		headers[key], err = run.EvaluateTemplate(value)
+		if err != nil {
+			logEvent(events.NewError(err))
+		}
+	}
+
+	var profile *flows.AuthProfile
+	if a.AuthProfile != nil {
+		profile = run.Session().Assets().AuthProfiles().Get(a.AuthProfile.UUID)
+		if profile == nil {
+			logEvent(events.NewDependencyError(a.AuthProfile))
+			return nil
+		}
+	}
+
+	svc, err := run.Session().Engine().Services().Webhook(run.Session())
+	if err != nil {
+		logEvent(events.NewError(err))
+		return nil
+	}
+
+	// credentials are resolved once and used for every attempt
+	var secrets []string
+	if profile != nil {
+		authHeader, authValue, secret, err := authorize(ctx, svc, run.Session(), profile, body, logEvent)
+		if err != nil {
+			logEvent(events.NewError(err))
+			return nil
+		}
+		headers[authHeader] = authValue
+		if secret {
+			secrets = append(secrets, authValue)
+		}
+	}
+
+	call, err := a.call(ctx, svc, run.Session(), method, url, headers, body, secrets, logEvent)
+	if err != nil {
+		return err
+	}
+	if call != nil {
+		a.updateWebhook(run, call)
+
+		if a.ResultName != "" {
+			a.saveWebhookResult(run, step, a.ResultName, call, callStatus(call, nil, false), logEvent)
+		}
+
+		a.extractResults(run, step, call, logEvent)
+	}
+
+	return nil
+}
+
+// makes the request, retrying as configured, and returns the last call made
+func (a *CallHTTPAction) call(ctx context.Context, svc flows.WebhookService, session flows.Session, method, url string, headers map[string]string, body string, secrets []string, logEvent flows.EventCallback) (*flows.WebhookCall, error) {
+	redact := utils.NewRedactor(flows.RedactionMask, secrets...)
+
+	var retries *httpx.RetryConfig
+	if a.Retry != nil {
+		retries = a.Retry.config()
+	}
+
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, method, url, strings.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		for key, value := range headers {
+			req.Header.Add(key, value)
+		}
+
+		call, err := callWebhook(ctx, svc, session, req)
+		if err != nil {
+			logEvent(events.NewError(err))
+		}
+		if call == nil {
+			return nil, nil
+		}
+
+		// don't leak credentials into the event log
+		call.RequestTrace = []byte(redact(string(call.RequestTrace)))
+
+		logEvent(events.NewWebhookCalled(call, callStatus(call, err, false), ""))
+
+		if retries == nil || attempt >= retries.MaxRetries() {
+			return call, nil
+		}
+
+		// retries have no jitter so a delay of zero is allowed
+		backoff := retries.Backoffs[attempt]
+		if !retries.ShouldRetry(req, call.Response, backoff) {
+			return call, nil
+		}
+
+		flows.Blocking(ctx, func() {
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+			}
+		})
+	}
+}
+
+// saves a result for each of our JSON path extractions from the response
+func (a *CallHTTPAction) extractResults(run flows.FlowRun, step flows.Step, call *flows.WebhookCall, logEvent flows.EventCallback) {
+	input := call.Request.Method + " " + call.Request.URL.String()
+
+	for _, name := range a.extractNames() {
+		path := a.Extract[name]
+		value, found := extractJSONPath(call.ResponseJSON, path)
+
+		if found {
+			a.saveResult(run, step, name, value, CategorySuccess, "", input, nil, logEvent)
+		} else {
+			a.saveResult(run, step, name, "", CategoryFailure, "", input, nil, logEvent)
+		}
+	}
+}
+
+// gets the names of our extracted results in a consistent order
+func (a *CallHTTPAction) extractNames() []string {
+	names := make([]string, 0, len(a.Extract))
+	for name := range a.Extract {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Results enumerates any results generated by this flow object
+func (a *CallHTTPAction) Results(include func(*flows.ResultInfo)) {
+	if a.ResultName != "" {
+		include(flows.NewResultInfo(a.ResultName, webhookCategories))
+	}
+	for _, name := range a.extractNames() {
+		include(flows.NewResultInfo(name, webhookCategories))
+	}
+}
+
+// resolves the header which the given auth profile adds to a request with the given body, and whether its value is secret
+func authorize(ctx context.Context, svc flows.WebhookService, session flows.Session, profile *flows.AuthProfile, body string, logEvent flows.EventCallback) (string, string, bool, error) {
+	config := profile.Config()
+
+	required := func(keys ...string) error {
+		for _, key := range keys {
+			if config[key] == "" {
+				return errors.Errorf("auth profile '%s' is missing config value '%s'", profile.Name(), key)
+			}
+		}
+		return nil
+	}
+
+	switch profile.Type() {
+	case AuthProfileTypeBasic:
+		if err := required("username", "password"); err != nil {
+			return "", "", false, err
+		}
+		req := &http.Request{Header: make(http.Header)}
+		req.SetBasicAuth(config["username"], config["password"])
+		return "Authorization", req.Header.Get("Authorization"), true, nil
+
+	case AuthProfileTypeBearer:
+		if err := required("token"); err != nil {
+			return "", "", false, err
+		}
+		return "Authorization", "Bearer " + config["token"], true, nil
+
+	case AuthProfileTypeHMAC:
+		if err := required("secret"); err != nil {
+			return "", "", false, err
+		}
+		header := config["header"]
+		if header == "" {
+			header = defaultSignatureHeader
+		}
+		mac := hmac.New(sha256.New, []byte(config["secret"]))
+		mac.Write([]byte(body))
+		return header, hex.EncodeToString(mac.Sum(nil)), false, nil
+
+	case AuthProfileTypeOAuth2:
+		if err := required("token_url", "client_id", "client_secret"); err != nil {
+			return "", "", false, err
+		}
+		token, err := fetchOAuth2Token(ctx, svc, session, profile, logEvent)
+		if err != nil {
+			return "", "", false, errors.Wrapf(err, "unable to authorize with auth profile '%s'", profile.Name())
+		}
+		return "Authorization", "Bearer " + token, true, nil
+	}
+
+	return "", "", false, errors.Errorf("auth profile '%s' has unsupported type '%s'", profile.Name(), profile.Type())
+}
+
+// fetches an access token using the OAuth2 client credentials grant, reusing any token fetched earlier in the session
+func fetchOAuth2Token(ctx context.Context, svc flows.WebhookService, session flows.Session, profile *flows.AuthProfile, logEvent flows.EventCallback) (string, error) {
+	if token := session.AuthToken(profile.UUID()); token != "" {
+		return token, nil
+	}
+
+	config := profile.Config()
+	form := url.Values{
+		"grant_type":    []string{"client_credentials"},
+		"client_id":     []string{config["client_id"]},
+		"client_secret": []string{config["client_secret"]},
+	}
+	if config["scope"] != "" {
+		form.Set("scope", config["scope"])
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, config["token_url"], strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	call, err := callWebhook(ctx, svc, session, req)
+	if call == nil {
+		if err == nil {
+			err = errors.New("token request failed to connect")
+		}
+		return "", err
+	}
+
+	token, _ := jsonparser.GetString(call.ResponseJSON, "access_token")
+
+	// the request has the client secret and the response has the token so neither can go into the event log
+	secrets := []string{config["client_secret"], url.QueryEscape(config["client_secret"])}
+	if token != "" {
+		secrets = append(secrets, token)
+	}
+	redact := utils.NewRedactor(flows.RedactionMask, secrets...)
+	event := events.NewWebhookCalled(call, callStatus(call, err, false), "")
+	event.Request = redact(event.Request)
+	event.Response = redact(event.Response)
+	logEvent(event)
+
+	if err != nil {
+		return "", err
+	}
+	if call.Response == nil {
+		return "", errors.New("token request failed to connect")
+	}
+	if call.Response.StatusCode/100 != 2 {
+		return "", errors.Errorf("token request failed with status %d", call.Response.StatusCode)
+	}
+	if token == "" {
+		return "", errors.New("token response has no access_token")
+	}
+
+	// tokens are reused until shortly before they expire
+	var expiresOn time.Time
+	if expiresIn, err := jsonparser.GetInt(call.ResponseJSON, "expires_in"); err == nil && expiresIn > 0 {
+		expiresOn = dates.Now().Add(time.Duration(expiresIn)*time.Second - oauth2TokenExpiryMargin)
+	}
+	session.SetAuthToken(profile.UUID(), token, expiresOn)
+
+	return token, nil
+}
+
+// how long before an OAuth2 token expires that we stop reusing it
+const oauth2TokenExpiryMargin = 30 * time.Second
+
+// parses a JSON path like $.order.items[0].id into the keys used by jsonparser
+func parseJSONPath(path string) ([]string, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, errors.Errorf("'%s' isn't a valid JSON path", path)
+	}
+
+	keys := make([]string, 0)
+	rest := path[1:]
+
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			end := strings.IndexAny(rest[1:], ".[")
+			if end < 0 {
+				end = len(rest) - 1
+			}
+			if end == 0 {
+				return nil, errors.Errorf("'%s' isn't a valid JSON path", path)
+			}
+			keys = append(keys, rest[1:end+1])
+			rest = rest[end+1:]
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, errors.Errorf("'%s' isn't a valid JSON path", path)
+			}
+			if index, err := strconv.Atoi(rest[1:end]); err != nil || index < 0 {
+				return nil, errors.Errorf("'%s' isn't a valid JSON path", path)
+			}
+			keys = append(keys, rest[:end+1])
+			rest = rest[end+1:]
+		default:
+			return nil, errors.Errorf("'%s' isn't a valid JSON path", path)
+		}
+	}
+
+	return keys, nil
+}
+
+// extracts the value at the given JSON path, with strings unquoted and other values as JSON
+func extractJSONPath(data []byte, path string) (string, bool) {
+	keys, err := parseJSONPath(path)
+	if err != nil || len(data) == 0 {
+		return "", false
+	}
+
+	value, valueType, _, err := jsonparser.Get(data, keys...)
+	if err != nil || valueType == jsonparser.NotExist || valueType == jsonparser.Null {
+		return "", false
+	}
+	if valueType == jsonparser.String {
+		str, err := jsonparser.ParseString(value)
+		if err != nil {
+			return "", false
+		}
+		return str, true
+	}
+	return string(value), true
+}
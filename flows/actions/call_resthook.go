@@ -1,6 +1,7 @@
 package actions
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"strings"
@@ -82,6 +83,11 @@ func NewCallResthook(uuid flows.ActionUUID, resthook string, resultName string)
 
 // Execute runs this action
 func (a *CallResthookAction) Execute(run flows.FlowRun, step flows.Step, logModifier flows.ModifierCallback, logEvent flows.EventCallback) error {
+	return a.ExecuteWithContext(context.Background(), run, step, logModifier, logEvent)
+}
+
+// ExecuteWithContext runs this action, passing the given context to any services called
+func (a *CallResthookAction) ExecuteWithContext(ctx context.Context, run flows.FlowRun, step flows.Step, logModifier flows.ModifierCallback, logEvent flows.EventCallback) error {
 	// NOOP if resthook doesn't exist
 	resthook := run.Session().Assets().Resthooks().FindBySlug(a.Resthook)
 	if resthook == nil {
@@ -107,7 +113,7 @@ func (a *CallResthookAction) Execute(run flows.FlowRun, step flows.Step, logModi
 	calls := make([]*flows.WebhookCall, 0, len(resthook.Subscribers()))
 
 	for _, url := range resthook.Subscribers() {
-		req, err := http.NewRequest("POST", url, strings.NewReader(payload))
+		req, err := http.NewRequestWithContext(ctx, "POST", url, strings.NewReader(payload))
 		if err != nil {
 			logEvent(events.NewError(err))
 			return nil
@@ -121,7 +127,7 @@ func (a *CallResthookAction) Execute(run flows.FlowRun, step flows.Step, logModi
 			return nil
 		}
 
-		call, err := svc.Call(run.Session(), req)
+		call, err := callWebhook(ctx, svc, run.Session(), req)
 
 		if err != nil {
 			logEvent(events.NewError(err))
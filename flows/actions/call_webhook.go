@@ -1,6 +1,7 @@
 package actions
 
 import (
+	"context"
 	"net/http"
 	"net/url"
 	"strings"
@@ -77,6 +78,11 @@ func (a *CallWebhookAction) Validate() error {
 
 // Execute runs this action
 func (a *CallWebhookAction) Execute(run flows.FlowRun, step flows.Step, logModifier flows.ModifierCallback, logEvent flows.EventCallback) error {
+	return a.ExecuteWithContext(context.Background(), run, step, logModifier, logEvent)
+}
+
+// ExecuteWithContext runs this action, passing the given context to any services called
+func (a *CallWebhookAction) ExecuteWithContext(ctx context.Context, run flows.FlowRun, step flows.Step, logModifier flows.ModifierCallback, logEvent flows.EventCallback) error {
 
 	// substitute any variables in our url
 	url, err := run.EvaluateTemplate(a.URL)
@@ -104,13 +110,13 @@ func (a *CallWebhookAction) Execute(run flows.FlowRun, step flows.Step, logModif
 		}
 	}
 
-	return a.call(run, step, url, method, body, logEvent)
+	return a.call(ctx, run, step, url, method, body, logEvent)
 }
 
 // Execute runs this action
-func (a *CallWebhookAction) call(run flows.FlowRun, step flows.Step, url, method, body string, logEvent flows.EventCallback) error {
+func (a *CallWebhookAction) call(ctx context.Context, run flows.FlowRun, step flows.Step, url, method, body string, logEvent flows.EventCallback) error {
 	// build our request
-	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	req, err := http.NewRequestWithContext(ctx, method, url, strings.NewReader(body))
 	if err != nil {
 		return err
 	}
@@ -131,7 +137,7 @@ func (a *CallWebhookAction) call(run flows.FlowRun, step flows.Step, url, method
 		return nil
 	}
 
-	call, err := svc.Call(run.Session(), req)
+	call, err := callWebhook(ctx, svc, run.Session(), req)
 
 	if err != nil {
 		logEvent(events.NewError(err))
@@ -0,0 +1,79 @@
+package actions
+
+import (
+	"context"
+
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/events"
+)
+
+func init() {
+	registerType(TypeChangeTicketTopic, func() flows.Action { return &ChangeTicketTopicAction{} })
+}
+
+// TypeChangeTicketTopic is the type for the change ticket topic action
+const TypeChangeTicketTopic string = "change_ticket_topic"
+
+// ChangeTicketTopicAction is used to change the topic of the contact's current ticket. A [event:ticket_topic_changed]
+// event will be created if the topic is changed successfully.
+//
+//   {
+//     "uuid": "8eebd020-1af5-431c-b943-aa670fc74da9",
+//     "type": "change_ticket_topic",
+//     "topic": {
+//       "uuid": "472a7a73-96cb-4736-b567-056d987cc5b4",
+//       "name": "Weather"
+//     }
+//   }
+//
+// @action change_ticket_topic
+type ChangeTicketTopicAction struct {
+	baseAction
+	onlineAction
+
+	Topic *assets.TopicReference `json:"topic" validate:"required,dive"`
+}
+
+// NewChangeTicketTopic creates a new change ticket topic action
+func NewChangeTicketTopic(uuid flows.ActionUUID, topic *assets.TopicReference) *ChangeTicketTopicAction {
+	return &ChangeTicketTopicAction{
+		baseAction: newBaseAction(TypeChangeTicketTopic, uuid),
+		Topic:      topic,
+	}
+}
+
+// Execute runs this action
+func (a *ChangeTicketTopicAction) Execute(run flows.FlowRun, step flows.Step, logModifier flows.ModifierCallback, logEvent flows.EventCallback) error {
+	return a.ExecuteWithContext(context.Background(), run, step, logModifier, logEvent)
+}
+
+// ExecuteWithContext runs this action, passing the given context to the ticket service
+func (a *ChangeTicketTopicAction) ExecuteWithContext(ctx context.Context, run flows.FlowRun, step flows.Step, logModifier flows.ModifierCallback, logEvent flows.EventCallback) error {
+	ticket := currentTicket(run, logEvent)
+	if ticket == nil {
+		return nil
+	}
+
+	topic := run.Session().Assets().Topics().Get(a.Topic.UUID)
+	if topic == nil {
+		logEvent(events.NewDependencyError(a.Topic))
+		return nil
+	}
+
+	// nothing to do if ticket already has this topic
+	if ticket.Topic() == topic {
+		return nil
+	}
+
+	changed := updateTicket(run, ticket, logEvent, func(svc flows.TicketService, logHTTP flows.HTTPLogCallback) error {
+		return changeTicketTopic(ctx, svc, run.Session(), ticket, topic, logHTTP)
+	})
+	if changed {
+		ticket.SetTopic(topic)
+
+		logEvent(events.NewTicketTopicChanged(ticket, topic))
+	}
+
+	return nil
+}
@@ -0,0 +1,126 @@
+package actions
+
+import (
+	"context"
+	"strings"
+
+	"github.com/nyaruka/gocommon/urns"
+	"github.com/nyaruka/goflow/envs"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/events"
+)
+
+func init() {
+	registerType(TypeCreateContact, func() flows.Action { return &CreateContactAction{} })
+}
+
+// TypeCreateContact is the type for the create contact action
+const TypeCreateContact string = "create_contact"
+
+// CreateContactAction can be used to create a new contact other than the session contact. The name and URNs may be
+// templates and will be evaluated at runtime, and URNs which evaluate to invalid URNs are ignored. The action saves a
+// result with the given name whose value is the UUID of the new contact, and whose category is `Success` if the contact
+// was created and `Failure` if not. The new contact's properties are accessible through `extra` on the result.
+//
+//   {
+//     "uuid": "8eebd020-1af5-431c-b943-aa670fc74da9",
+//     "type": "create_contact",
+//     "name": "Jim",
+//     "language": "eng",
+//     "urns": ["tel:+12065553333"],
+//     "result_name": "Friend"
+//   }
+//
+// @action create_contact
+type CreateContactAction struct {
+	baseAction
+	onlineAction
+
+	Name       string        `json:"name,omitempty" engine:"evaluated"`
+	Language   envs.Language `json:"language,omitempty"`
+	URNs       []string      `json:"urns" validate:"required,min=1" engine:"evaluated"`
+	ResultName string        `json:"result_name" validate:"required"`
+}
+
+// NewCreateContact creates a new create contact action
+func NewCreateContact(uuid flows.ActionUUID, name string, language envs.Language, urns []string, resultName string) *CreateContactAction {
+	return &CreateContactAction{
+		baseAction: newBaseAction(TypeCreateContact, uuid),
+		Name:       name,
+		Language:   language,
+		URNs:       urns,
+		ResultName: resultName,
+	}
+}
+
+// Execute runs this action
+func (a *CreateContactAction) Execute(run flows.FlowRun, step flows.Step, logModifier flows.ModifierCallback, logEvent flows.EventCallback) error {
+	return a.ExecuteWithContext(context.Background(), run, step, logModifier, logEvent)
+}
+
+// ExecuteWithContext runs this action, passing the given context to the contact service
+func (a *CreateContactAction) ExecuteWithContext(ctx context.Context, run flows.FlowRun, step flows.Step, logModifier flows.ModifierCallback, logEvent flows.EventCallback) error {
+	contact, input := a.create(ctx, run, logEvent)
+
+	a.saveContactResult(run, step, a.ResultName, contact, input, logEvent)
+	return nil
+}
+
+func (a *CreateContactAction) create(ctx context.Context, run flows.FlowRun, logEvent flows.EventCallback) (*flows.Contact, string) {
+	name, err := run.EvaluateTemplate(a.Name)
+	if err != nil {
+		logEvent(events.NewError(err))
+	}
+	name = strings.TrimSpace(name)
+
+	contactURNs := make([]urns.URN, 0, len(a.URNs))
+	for _, u := range a.URNs {
+		evaluatedURN, err := run.EvaluateTemplate(u)
+		if err != nil {
+			logEvent(events.NewError(err))
+		}
+
+		evaluatedURN = strings.TrimSpace(evaluatedURN)
+		urn := urns.URN(evaluatedURN).Normalize(string(run.Environment().DefaultCountry()))
+
+		if err := urn.Validate(); err != nil {
+			logEvent(events.NewErrorf("ignoring invalid URN '%s'", evaluatedURN))
+			continue
+		}
+		contactURNs = append(contactURNs, urn)
+	}
+
+	input := strings.Join(urnsToStrings(contactURNs), ", ")
+
+	if len(contactURNs) == 0 {
+		logEvent(events.NewErrorf("can't create contact without any valid URNs"))
+		return nil, input
+	}
+
+	svc, err := run.Session().Engine().Services().Contact(run.Session())
+	if err != nil {
+		logEvent(events.NewError(err))
+		return nil, input
+	}
+
+	contact, err := createContact(ctx, svc, run.Session(), name, a.Language, contactURNs)
+	if err != nil {
+		logEvent(events.NewError(err))
+		return nil, input
+	}
+
+	return contact, input
+}
+
+// Results enumerates any results generated by this flow object
+func (a *CreateContactAction) Results(include func(*flows.ResultInfo)) {
+	include(flows.NewResultInfo(a.ResultName, contactCategories))
+}
+
+func urnsToStrings(urnz []urns.URN) []string {
+	strs := make([]string, len(urnz))
+	for i := range urnz {
+		strs[i] = string(urnz[i])
+	}
+	return strs
+}
@@ -1,7 +1,10 @@
 package actions
 
 import (
+	"sort"
+
 	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/excellent/types"
 	"github.com/nyaruka/goflow/flows"
 	"github.com/nyaruka/goflow/flows/events"
 
@@ -17,12 +20,17 @@ const TypeEnterFlow string = "enter_flow"
 
 // EnterFlowAction can be used to start a contact down another flow. The current flow will pause until the subflow exits or expires.
 //
+// Values can be passed to the parameters declared by the subflow, which are converted to the declared types and made
+// available in the subflow as `@params`. If the subflow declares outputs, these are saved as results on the current
+// run when the subflow completes.
+//
 // A [event:flow_entered] event will be created to record that the flow was started.
 //
 //   {
 //     "uuid": "8eebd020-1af5-431c-b943-aa670fc74da9",
 //     "type": "enter_flow",
 //     "flow": {"uuid": "b7cf0d83-f1c9-411c-96fd-c511a4cfa86d", "name": "Collect Language"},
+//     "params": {"name": "@contact.name"},
 //     "terminal": false
 //   }
 //
@@ -32,14 +40,16 @@ type EnterFlowAction struct {
 	universalAction
 
 	Flow     *assets.FlowReference `json:"flow" validate:"required"`
+	Params   map[string]string     `json:"params,omitempty" engine:"evaluated"`
 	Terminal bool                  `json:"terminal,omitempty"`
 }
 
 // NewEnterFlow creates a new start flow action
-func NewEnterFlow(uuid flows.ActionUUID, flow *assets.FlowReference, terminal bool) *EnterFlowAction {
+func NewEnterFlow(uuid flows.ActionUUID, flow *assets.FlowReference, params map[string]string, terminal bool) *EnterFlowAction {
 	return &EnterFlowAction{
 		baseAction: newBaseAction(TypeEnterFlow, uuid),
 		Flow:       flow,
+		Params:     params,
 		Terminal:   terminal,
 	}
 }
@@ -59,7 +69,53 @@ func (a *EnterFlowAction) Execute(run flows.FlowRun, step flows.Step, logModifie
 		return nil
 	}
 
-	run.Session().PushFlow(flow, run, a.Terminal)
+	params, err := a.evaluateParams(run, flow, logEvent)
+	if err != nil {
+		a.fail(run, err, logEvent)
+		return nil
+	}
+
+	run.Session().PushFlow(flow, run, a.Terminal, params)
 	logEvent(events.NewFlowEntered(a.Flow, run.UUID(), a.Terminal))
 	return nil
 }
+
+// evaluates the values passed to the params declared by the given flow, converting each to the declared type
+func (a *EnterFlowAction) evaluateParams(run flows.FlowRun, flow flows.Flow, logEvent flows.EventCallback) (*types.XObject, error) {
+	values := make(map[string]types.XValue, len(flow.Params()))
+
+	for _, param := range flow.Params() {
+		template, exists := a.Params[param.Key]
+		if !exists {
+			if param.Required {
+				return nil, errors.Errorf("missing value for required param '%s' of %s", param.Key, flow.Reference())
+			}
+			values[param.Key] = nil
+			continue
+		}
+
+		value, err := run.EvaluateTemplateValue(template)
+		if err != nil {
+			logEvent(events.NewError(err))
+		}
+
+		if values[param.Key], err = param.Type.Convert(run.Environment(), value); err != nil {
+			return nil, errors.Errorf("value for param '%s' of %s isn't a valid %s", param.Key, flow.Reference(), param.Type)
+		}
+	}
+
+	// params which the flow doesn't declare can't be passed to it
+	undeclared := make([]string, 0)
+	for key := range a.Params {
+		if _, declared := values[key]; !declared {
+			undeclared = append(undeclared, key)
+		}
+	}
+	sort.Strings(undeclared)
+
+	for _, key := range undeclared {
+		logEvent(events.NewErrorf("param '%s' isn't declared by %s and has been ignored", key, flow.Reference()))
+	}
+
+	return types.NewXObject(values), nil
+}
@@ -0,0 +1,151 @@
+package actions
+
+import (
+	"context"
+	"strings"
+
+	"github.com/nyaruka/gocommon/urns"
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/events"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	registerType(TypeLookupContact, func() flows.Action { return &LookupContactAction{} })
+}
+
+// TypeLookupContact is the type for the lookup contact action
+const TypeLookupContact string = "lookup_contact"
+
+var contactCategories = []string{CategorySuccess, CategoryFailure}
+
+// LookupContactAction can be used to look up another contact by URN, or by the value of a field. The action saves a
+// result with the given name whose value is the UUID of the contact found, and whose category is `Success` if a contact
+// was found and `Failure` if not. The found contact's properties, e.g. its name and fields, are accessible through
+// `extra` on the result.
+//
+//   {
+//     "uuid": "8eebd020-1af5-431c-b943-aa670fc74da9",
+//     "type": "lookup_contact",
+//     "urn": "tel:+12065551212",
+//     "result_name": "Referrer"
+//   }
+//
+// @action lookup_contact
+type LookupContactAction struct {
+	baseAction
+	onlineAction
+
+	URN        string                 `json:"urn,omitempty" engine:"evaluated"`
+	Field      *assets.FieldReference `json:"field,omitempty" validate:"omitempty,dive"`
+	Value      string                 `json:"value,omitempty" engine:"evaluated"`
+	ResultName string                 `json:"result_name" validate:"required"`
+}
+
+// NewLookupContactByURN creates a new lookup contact action which looks up a contact by URN
+func NewLookupContactByURN(uuid flows.ActionUUID, urn string, resultName string) *LookupContactAction {
+	return &LookupContactAction{
+		baseAction: newBaseAction(TypeLookupContact, uuid),
+		URN:        urn,
+		ResultName: resultName,
+	}
+}
+
+// NewLookupContactByField creates a new lookup contact action which looks up a contact by field value
+func NewLookupContactByField(uuid flows.ActionUUID, field *assets.FieldReference, value string, resultName string) *LookupContactAction {
+	return &LookupContactAction{
+		baseAction: newBaseAction(TypeLookupContact, uuid),
+		Field:      field,
+		Value:      value,
+		ResultName: resultName,
+	}
+}
+
+// Validate validates our action is valid
+func (a *LookupContactAction) Validate() error {
+	if (a.URN == "") == (a.Field == nil) {
+		return errors.New("must specify one of urn or field")
+	}
+	if a.Field != nil && a.Value == "" {
+		return errors.New("must specify a value to look up by field")
+	}
+	return nil
+}
+
+// Execute runs this action
+func (a *LookupContactAction) Execute(run flows.FlowRun, step flows.Step, logModifier flows.ModifierCallback, logEvent flows.EventCallback) error {
+	return a.ExecuteWithContext(context.Background(), run, step, logModifier, logEvent)
+}
+
+// ExecuteWithContext runs this action, passing the given context to the contact service
+func (a *LookupContactAction) ExecuteWithContext(ctx context.Context, run flows.FlowRun, step flows.Step, logModifier flows.ModifierCallback, logEvent flows.EventCallback) error {
+	contact, input := a.lookup(ctx, run, logEvent)
+
+	a.saveContactResult(run, step, a.ResultName, contact, input, logEvent)
+	return nil
+}
+
+func (a *LookupContactAction) lookup(ctx context.Context, run flows.FlowRun, logEvent flows.EventCallback) (*flows.Contact, string) {
+	var urn urns.URN
+	var field *flows.Field
+	var input string
+
+	if a.URN != "" {
+		evaluatedURN, err := run.EvaluateTemplate(a.URN)
+		if err != nil {
+			logEvent(events.NewError(err))
+		}
+
+		evaluatedURN = strings.TrimSpace(evaluatedURN)
+		urn = urns.URN(evaluatedURN).Normalize(string(run.Environment().DefaultCountry()))
+
+		if err := urn.Validate(); err != nil {
+			logEvent(events.NewErrorf("can't look up contact by invalid URN '%s'", evaluatedURN))
+			return nil, evaluatedURN
+		}
+		input = string(urn)
+	} else {
+		field = run.Session().Assets().Fields().Get(a.Field.Key)
+		if field == nil {
+			logEvent(events.NewDependencyError(a.Field))
+			return nil, ""
+		}
+
+		evaluatedValue, err := run.EvaluateTemplate(a.Value)
+		if err != nil {
+			logEvent(events.NewError(err))
+		}
+
+		input = strings.TrimSpace(evaluatedValue)
+		if input == "" {
+			logEvent(events.NewErrorf("can't look up contact by empty value of field '%s'", a.Field.Key))
+			return nil, input
+		}
+	}
+
+	svc, err := run.Session().Engine().Services().Contact(run.Session())
+	if err != nil {
+		logEvent(events.NewError(err))
+		return nil, input
+	}
+
+	var contact *flows.Contact
+	if field != nil {
+		contact, err = lookupContactByField(ctx, svc, run.Session(), field, input)
+	} else {
+		contact, err = lookupContactByURN(ctx, svc, run.Session(), urn)
+	}
+	if err != nil {
+		logEvent(events.NewError(err))
+		return nil, input
+	}
+
+	return contact, input
+}
+
+// Results enumerates any results generated by this flow object
+func (a *LookupContactAction) Results(include func(*flows.ResultInfo)) {
+	include(flows.NewResultInfo(a.ResultName, contactCategories))
+}
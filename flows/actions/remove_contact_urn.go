@@ -0,0 +1,86 @@
+package actions
+
+import (
+	"strings"
+
+	"github.com/nyaruka/gocommon/urns"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/events"
+	"github.com/nyaruka/goflow/flows/modifiers"
+)
+
+func init() {
+	registerType(TypeRemoveContactURN, func() flows.Action { return &RemoveContactURNAction{} })
+}
+
+// TypeRemoveContactURN is our type for the remove URN action
+const TypeRemoveContactURN string = "remove_contact_urn"
+
+// RemoveContactURNAction can be used to remove a URN from the current contact. The URN can be a template and will
+// be evaluated at runtime. A [event:contact_urns_changed] event will be created if the contact had the URN.
+//
+//   {
+//     "uuid": "8eebd020-1af5-431c-b943-aa670fc74da9",
+//     "type": "remove_contact_urn",
+//     "urn": "@urns.mailto"
+//   }
+//
+// @action remove_contact_urn
+type RemoveContactURNAction struct {
+	baseAction
+	universalAction
+
+	URN string `json:"urn" validate:"required" engine:"evaluated"`
+}
+
+// NewRemoveContactURN creates a new remove URN action
+func NewRemoveContactURN(uuid flows.ActionUUID, urn string) *RemoveContactURNAction {
+	return &RemoveContactURNAction{
+		baseAction: newBaseAction(TypeRemoveContactURN, uuid),
+		URN:        urn,
+	}
+}
+
+// Execute runs this action
+func (a *RemoveContactURNAction) Execute(run flows.FlowRun, step flows.Step, logModifier flows.ModifierCallback, logEvent flows.EventCallback) error {
+	// only generate event if run has a contact
+	contact := run.Contact()
+	if contact == nil {
+		logEvent(events.NewErrorf("can't execute action in session without a contact"))
+		return nil
+	}
+
+	urn := evaluateContactURN(run, a.URN, "remove", logEvent)
+	if urn == urns.NilURN {
+		return nil
+	}
+
+	a.applyModifier(run, modifiers.NewURNs([]urns.URN{urn}, modifiers.URNsRemove), logModifier, logEvent)
+	return nil
+}
+
+// evaluates the given URN template and normalizes and validates the result, logging an error and returning a nil URN
+// if the result isn't a valid URN
+func evaluateContactURN(run flows.FlowRun, template string, verb string, logEvent flows.EventCallback) urns.URN {
+	evaluatedURN, err := run.EvaluateTemplate(template)
+
+	// if we received an error, log it although it might just be a non-expression like foo@bar.com
+	if err != nil {
+		logEvent(events.NewError(err))
+	}
+
+	evaluatedURN = strings.TrimSpace(evaluatedURN)
+	if evaluatedURN == "" {
+		logEvent(events.NewErrorf("can't %s URN which is empty", verb))
+		return urns.NilURN
+	}
+
+	urn := urns.URN(evaluatedURN).Normalize(string(run.Environment().DefaultCountry()))
+
+	if err := urn.Validate(); err != nil {
+		logEvent(events.NewErrorf("can't %s invalid URN '%s'", verb, evaluatedURN))
+		return urns.NilURN
+	}
+
+	return urn
+}
@@ -21,6 +21,9 @@ const TypeSendBroadcast string = "send_broadcast"
 // The URNs and text fields may be templates. A [event:broadcast_created] event will be created for each unique urn, contact and group
 // with the evaluated text.
 //
+// URNs which will be sent to by a channel which limits the length of messages or the number or length of quick replies
+// are sent separate broadcasts which comply with those limits, as described for [action:send_msg].
+//
 //   {
 //     "uuid": "8eebd020-1af5-431c-b943-aa670fc74da9",
 //     "type": "send_broadcast",
@@ -82,10 +85,121 @@ func (a *SendBroadcastAction) Execute(run flows.FlowRun, step flows.Step, logMod
 		}
 	}
 
-	// if we have any recipients, log an event
-	if len(urnList) > 0 || len(contactRefs) > 0 || len(groupRefs) > 0 {
-		logEvent(events.NewBroadcastCreated(translations, run.Flow().Language(), groupRefs, contactRefs, urnList))
+	// URN recipients whose channel we know may need the broadcast changed to comply with that channel's message policy,
+	// in which case they're sent separate broadcasts
+	unpolicedURNs := make([]urns.URN, 0, len(urnList))
+	policedBroadcasts := make([]*events.BroadcastCreatedEvent, 0)
+
+	for _, recipients := range a.groupURNsByChannel(run, urnList) {
+		var broadcasts []*events.BroadcastCreatedEvent
+		if recipients.channel != nil {
+			broadcasts = a.applyBroadcastPolicy(run, recipients.channel, recipients.urns, languages, translations, logEvent)
+		}
+
+		if len(broadcasts) > 0 {
+			policedBroadcasts = append(policedBroadcasts, broadcasts...)
+		} else {
+			unpolicedURNs = append(unpolicedURNs, recipients.urns...)
+		}
+	}
+
+	// if we have any other recipients, log an event
+	if len(unpolicedURNs) > 0 || len(contactRefs) > 0 || len(groupRefs) > 0 {
+		logEvent(events.NewBroadcastCreated(translations, run.Flow().Language(), groupRefs, contactRefs, unpolicedURNs))
+	}
+
+	for _, broadcast := range policedBroadcasts {
+		logEvent(broadcast)
 	}
 
 	return nil
 }
+
+// URNs which will be sent to by the same channel
+type channelURNs struct {
+	channel *flows.Channel
+	urns    []urns.URN
+}
+
+// groups the given URNs by the channel and scheme they will be sent with, preserving their order
+func (a *SendBroadcastAction) groupURNsByChannel(run flows.FlowRun, urnList []urns.URN) []*channelURNs {
+	groups := make([]*channelURNs, 0)
+	byKey := make(map[string]*channelURNs)
+
+	for _, urn := range urnList {
+		channel := run.Session().Assets().Channels().GetForURN(flows.NewContactURN(urn, nil), assets.ChannelRoleSend)
+
+		key := urn.Scheme()
+		if channel != nil {
+			key = string(channel.UUID()) + ":" + key
+		}
+
+		group := byKey[key]
+		if group == nil {
+			group = &channelURNs{channel: channel}
+			groups = append(groups, group)
+			byKey[key] = group
+		}
+		group.urns = append(group.urns, urn)
+	}
+
+	return groups
+}
+
+// applies the message policy of the given channel to each translation of the broadcast, returning the broadcasts to
+// send to the given URNs instead, or nil if no changes were needed. If text is split into parts then a broadcast is
+// created for each part, and languages which need fewer parts are omitted from the later broadcasts.
+func (a *SendBroadcastAction) applyBroadcastPolicy(run flows.FlowRun, channel *flows.Channel, urnList []urns.URN, languages []envs.Language, translations map[envs.Language]*events.BroadcastTranslation, logEvent flows.EventCallback) []*events.BroadcastCreatedEvent {
+	textParts := make(map[envs.Language][]string, len(translations))
+	quickReplies := make(map[envs.Language][]string, len(translations))
+	numBroadcasts := 1
+
+	// the policy logs an error for every change it makes
+	changed := false
+	logChange := func(e flows.Event) {
+		changed = true
+		logEvent(e)
+	}
+
+	for _, language := range languages {
+		translation := translations[language]
+		if textParts[language] != nil {
+			continue // flow language can also be a translation language
+		}
+
+		textParts[language], quickReplies[language] = a.applyMsgPolicy(channel, urnList[0], translation.Text, true, translation.QuickReplies, logChange)
+
+		if len(textParts[language]) > numBroadcasts {
+			numBroadcasts = len(textParts[language])
+		}
+	}
+
+	if !changed {
+		return nil
+	}
+
+	// attachments go with the first part, and quick replies with the last part in each language
+	broadcasts := make([]*events.BroadcastCreatedEvent, numBroadcasts)
+	for i := range broadcasts {
+		partTranslations := make(map[envs.Language]*events.BroadcastTranslation, len(translations))
+
+		for language, parts := range textParts {
+			if i >= len(parts) {
+				continue
+			}
+
+			partTranslation := &events.BroadcastTranslation{Text: parts[i]}
+			if i == 0 {
+				partTranslation.Attachments = translations[language].Attachments
+			}
+			if i == len(parts)-1 {
+				partTranslation.QuickReplies = quickReplies[language]
+			}
+			partTranslations[language] = partTranslation
+		}
+
+		broadcasts[i] = events.NewBroadcastCreated(partTranslations, run.Flow().Language(), nil, nil, urnList)
+	}
+
+	return broadcasts
+}
@@ -7,6 +7,9 @@ import (
 	"github.com/nyaruka/goflow/envs"
 	"github.com/nyaruka/goflow/flows"
 	"github.com/nyaruka/goflow/flows/events"
+	"github.com/nyaruka/goflow/utils"
+
+	"github.com/pkg/errors"
 )
 
 func init() {
@@ -20,6 +23,17 @@ const TypeSendMsg string = "send_msg"
 // will attempt to find pairs of URNs and channels which can be used for sending. If it can't find such a pair, it will
 // create a message without a channel or URN.
 //
+// Messages can also include interactive content on channels which support it: reply buttons, a list of rows in
+// sections which is opened with a button, or a button which opens a URL. The IDs of buttons and list rows aren't
+// evaluated or localized, so that a flow can route on `@input.button_id` whatever language the contact is using. Buttons
+// or list rows whose titles evaluate to empty strings are skipped, and if none are left the message is sent without its
+// interactive content.
+//
+// Where the channel of a destination limits the length of messages, the text is split into several messages, and
+// where it limits the number or length of quick replies, extra quick replies are dropped and long ones truncated.
+// Each of these changes is logged as an [event:error] event with a code that identifies it. Text from a template is
+// never split.
+//
 // A [event:msg_created] event will be created with the evaluated text.
 //
 //   {
@@ -36,6 +50,14 @@ const TypeSendMsg string = "send_msg"
 //       },
 //       "variables": ["@contact.name"]
 //     },
+//     "interactive": {
+//       "uuid": "2f9c3b5a-6e52-4a1d-8b2f-0b4c1c6c6c1e",
+//       "type": "buttons",
+//       "buttons": [
+//         {"uuid": "0e2e3a5c-7d4f-45b8-b8a4-1f1d8e2c2a9b", "id": "yes", "title": "Yes"},
+//         {"uuid": "a4c3f1e8-5b6d-4c2a-9e7f-3d8b1a6c5e4f", "id": "no", "title": "No"}
+//       ]
+//     },
 //     "topic": "event"
 //   }
 //
@@ -45,9 +67,170 @@ type SendMsgAction struct {
 	universalAction
 	createMsgAction
 
-	AllURNs    bool           `json:"all_urns,omitempty"`
-	Templating *Templating    `json:"templating,omitempty" validate:"omitempty,dive"`
-	Topic      flows.MsgTopic `json:"topic,omitempty" validate:"omitempty,msg_topic"`
+	AllURNs     bool           `json:"all_urns,omitempty"`
+	Interactive *Interactive   `json:"interactive,omitempty" validate:"omitempty,dive"`
+	Templating  *Templating    `json:"templating,omitempty" validate:"omitempty,dive"`
+	Topic       flows.MsgTopic `json:"topic,omitempty" validate:"omitempty,msg_topic"`
+}
+
+// maximum number of reply buttons and list rows in interactive content
+const (
+	maxInteractiveButtons = 3
+	maxInteractiveRows    = 10
+)
+
+// Interactive represents interactive content which should be sent with the message
+type Interactive struct {
+	UUID     uuids.UUID            `json:"uuid" validate:"required,uuid4"`
+	Type     flows.InteractiveType `json:"type" validate:"required,interactive_type"`
+	Buttons  []*InteractiveButton  `json:"buttons,omitempty" validate:"omitempty,dive"`
+	Button   string                `json:"button,omitempty" engine:"localized,evaluated"`
+	Sections []*InteractiveSection `json:"sections,omitempty" validate:"omitempty,dive"`
+	URL      string                `json:"url,omitempty" engine:"evaluated"`
+}
+
+// LocalizationUUID gets the UUID which identifies this object for localization
+func (i *Interactive) LocalizationUUID() uuids.UUID { return i.UUID }
+
+// InteractiveButton represents a reply button or a row in a list
+type InteractiveButton struct {
+	UUID        uuids.UUID `json:"uuid" validate:"required,uuid4"`
+	ID          string     `json:"id" validate:"required"`
+	Title       string     `json:"title" validate:"required" engine:"localized,evaluated"`
+	Description string     `json:"description,omitempty" engine:"localized,evaluated"`
+}
+
+// LocalizationUUID gets the UUID which identifies this object for localization
+func (b *InteractiveButton) LocalizationUUID() uuids.UUID { return b.UUID }
+
+// InteractiveSection represents a section of rows in a list
+type InteractiveSection struct {
+	UUID  uuids.UUID           `json:"uuid" validate:"required,uuid4"`
+	Title string               `json:"title,omitempty" engine:"localized,evaluated"`
+	Rows  []*InteractiveButton `json:"rows" validate:"required,min=1,dive"`
+}
+
+// LocalizationUUID gets the UUID which identifies this object for localization
+func (s *InteractiveSection) LocalizationUUID() uuids.UUID { return s.UUID }
+
+// validates the interactive content has what's required by its type
+func (i *Interactive) validate() error {
+	ids := make(map[string]bool)
+	checkID := func(id string) error {
+		if ids[id] {
+			return errors.Errorf("interactive button ID '%s' isn't unique", id)
+		}
+		ids[id] = true
+		return nil
+	}
+
+	switch i.Type {
+	case flows.InteractiveTypeButtons:
+		if len(i.Buttons) == 0 || len(i.Buttons) > maxInteractiveButtons {
+			return errors.Errorf("interactive content of type buttons must have between 1 and %d buttons", maxInteractiveButtons)
+		}
+		if len(i.Sections) > 0 || i.URL != "" {
+			return errors.New("interactive content of type buttons can't have sections or a URL")
+		}
+		for _, b := range i.Buttons {
+			if err := checkID(b.ID); err != nil {
+				return err
+			}
+		}
+	case flows.InteractiveTypeList:
+		if i.Button == "" || len(i.Sections) == 0 {
+			return errors.New("interactive content of type list must have a button and sections")
+		}
+		if len(i.Buttons) > 0 || i.URL != "" {
+			return errors.New("interactive content of type list can't have buttons or a URL")
+		}
+		numRows := 0
+		for _, section := range i.Sections {
+			for _, row := range section.Rows {
+				if err := checkID(row.ID); err != nil {
+					return err
+				}
+				numRows++
+			}
+		}
+		if numRows > maxInteractiveRows {
+			return errors.Errorf("interactive content of type list can't have more than %d rows", maxInteractiveRows)
+		}
+	case flows.InteractiveTypeCTAURL:
+		if i.Button == "" || i.URL == "" {
+			return errors.New("interactive content of type cta_url must have a button and a URL")
+		}
+		if len(i.Buttons) > 0 || len(i.Sections) > 0 {
+			return errors.New("interactive content of type cta_url can't have buttons or sections")
+		}
+	}
+	return nil
+}
+
+// evaluates the interactive content, localizing and evaluating all titles and descriptions
+func (i *Interactive) evaluate(run flows.FlowRun, logEvent flows.EventCallback) *flows.MsgInteractive {
+	evaluateText := func(uuid uuids.UUID, key, native string) string {
+		if native == "" {
+			return ""
+		}
+		evaluated, err := run.EvaluateTemplate(run.GetText(uuid, key, native))
+		if err != nil {
+			logEvent(events.NewError(err))
+		}
+		return evaluated
+	}
+	evaluateButtons := func(buttons []*InteractiveButton) []*flows.MsgButton {
+		evaluated := make([]*flows.MsgButton, 0, len(buttons))
+		for _, b := range buttons {
+			title := evaluateText(b.UUID, "title", b.Title)
+			if title == "" {
+				logEvent(events.NewErrorf("interactive button title evaluated to empty string, skipping"))
+				continue
+			}
+			evaluated = append(evaluated, &flows.MsgButton{ID: b.ID, Title: title, Description: evaluateText(b.UUID, "description", b.Description)})
+		}
+		return evaluated
+	}
+
+	interactive := &flows.MsgInteractive{
+		Type:    i.Type,
+		Buttons: evaluateButtons(i.Buttons),
+		Button:  evaluateText(i.UUID, "button", i.Button),
+	}
+	if len(interactive.Buttons) == 0 {
+		interactive.Buttons = nil
+	}
+
+	for _, section := range i.Sections {
+		interactive.Sections = append(interactive.Sections, &flows.MsgListSection{
+			Title: evaluateText(section.UUID, "title", section.Title),
+			Rows:  evaluateButtons(section.Rows),
+		})
+	}
+
+	if i.URL != "" {
+		url, err := run.EvaluateTemplate(i.URL)
+		if err != nil {
+			logEvent(events.NewError(err))
+		}
+		interactive.URL = url
+	}
+
+	// buttons or a list without anything to pick can't be sent so the message is sent without interactive content
+	numRows := 0
+	for _, section := range interactive.Sections {
+		numRows += len(section.Rows)
+	}
+	if i.Type == flows.InteractiveTypeButtons && len(interactive.Buttons) == 0 {
+		logEvent(events.NewErrorf("all interactive button titles evaluated to empty strings, sending without interactive content"))
+		return nil
+	}
+	if i.Type == flows.InteractiveTypeList && numRows == 0 {
+		logEvent(events.NewErrorf("all interactive list row titles evaluated to empty strings, sending without interactive content"))
+		return nil
+	}
+
+	return interactive
 }
 
 // Templating represents the templating that should be used if possible
@@ -73,6 +256,17 @@ func NewSendMsg(uuid flows.ActionUUID, text string, attachments []string, quickR
 	}
 }
 
+// Validate validates our action is valid
+func (a *SendMsgAction) Validate() error {
+	if a.Interactive != nil {
+		if len(a.QuickReplies) > 0 {
+			return errors.New("can't specify both quick replies and interactive content")
+		}
+		return a.Interactive.validate()
+	}
+	return nil
+}
+
 // Execute runs this action
 func (a *SendMsgAction) Execute(run flows.FlowRun, step flows.Step, logModifier flows.ModifierCallback, logEvent flows.EventCallback) error {
 	if run.Contact() == nil {
@@ -82,6 +276,11 @@ func (a *SendMsgAction) Execute(run flows.FlowRun, step flows.Step, logModifier
 
 	evaluatedText, evaluatedAttachments, evaluatedQuickReplies := a.evaluateMessage(run, nil, a.Text, a.Attachments, a.QuickReplies, logEvent)
 
+	var evaluatedInteractive *flows.MsgInteractive
+	if a.Interactive != nil {
+		evaluatedInteractive = a.Interactive.evaluate(run, logEvent)
+	}
+
 	destinations := run.Contact().ResolveDestinations(a.AllURNs)
 
 	sa := run.Session().Assets()
@@ -122,14 +321,35 @@ func (a *SendMsgAction) Execute(run flows.FlowRun, step flows.Step, logModifier
 			}
 		}
 
-		msg := flows.NewMsgOut(dest.URN.URN(), channelRef, evaluatedText, evaluatedAttachments, evaluatedQuickReplies, templating, a.Topic)
-		logEvent(events.NewMsgCreated(msg))
+		// templated text has to be sent as is
+		textParts, quickReplies := a.applyMsgPolicy(dest.Channel, dest.URN.URN(), evaluatedText, templating == nil, evaluatedQuickReplies, logEvent)
+
+		// if text has been split, attachments and templating go with the first part, and quick replies and interactive
+		// content with the last part
+		for i, text := range textParts {
+			var partAttachments []utils.Attachment
+			var partQuickReplies []string
+			var partInteractive *flows.MsgInteractive
+			var partTemplating *flows.MsgTemplating
+
+			if i == 0 {
+				partAttachments = evaluatedAttachments
+				partTemplating = templating
+			}
+			if i == len(textParts)-1 {
+				partQuickReplies = quickReplies
+				partInteractive = evaluatedInteractive
+			}
+
+			msg := flows.NewMsgOut(dest.URN.URN(), channelRef, text, partAttachments, partQuickReplies, partInteractive, partTemplating, a.Topic)
+			logEvent(events.NewMsgCreated(msg))
+		}
 	}
 
 	// if we couldn't find a destination, create a msg without a URN or channel and it's up to the caller
 	// to handle that as they want
 	if len(destinations) == 0 {
-		msg := flows.NewMsgOut(urns.NilURN, nil, evaluatedText, evaluatedAttachments, evaluatedQuickReplies, nil, flows.NilMsgTopic)
+		msg := flows.NewMsgOut(urns.NilURN, nil, evaluatedText, evaluatedAttachments, evaluatedQuickReplies, evaluatedInteractive, nil, flows.NilMsgTopic)
 		logEvent(events.NewMsgCreated(msg))
 	}
 
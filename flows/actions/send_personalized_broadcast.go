@@ -0,0 +1,178 @@
+package actions
+
+import (
+	"context"
+	"strings"
+
+	"github.com/nyaruka/gocommon/dates"
+	"github.com/nyaruka/gocommon/urns"
+	"github.com/nyaruka/gocommon/uuids"
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/envs"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/events"
+)
+
+func init() {
+	registerType(TypeSendPersonalizedBroadcast, func() flows.Action { return &SendPersonalizedBroadcastAction{} })
+}
+
+// TypeSendPersonalizedBroadcast is the type for the send personalized broadcast action
+const TypeSendPersonalizedBroadcast string = "send_personalized_broadcast"
+
+// SendPersonalizedBroadcastAction can be used to send a message to one or more URNs which is personalized for each
+// recipient. The URNs may be templates which are evaluated in the context of the current contact. Each URN is then
+// looked up to find an existing contact, and the message is localized and evaluated separately for each recipient,
+// with `@contact`, `@fields` and `@urns` referring to that recipient rather than the current contact, who can still
+// be referenced as `@run.contact`. Recipients who aren't existing contacts have no name, language or fields.
+//
+// A [event:personalized_broadcast_created] event will be created with the message rendered for each unique URN. Where
+// the channel of a recipient limits the content of messages, it is changed as described for [action:send_msg].
+//
+//   {
+//     "uuid": "8eebd020-1af5-431c-b943-aa670fc74da9",
+//     "type": "send_personalized_broadcast",
+//     "urns": ["tel:+12065553434"],
+//     "text": "Hi @contact.first_name, @run.contact.name has invited you to join"
+//   }
+//
+// @action send_personalized_broadcast
+type SendPersonalizedBroadcastAction struct {
+	baseAction
+	onlineAction
+	createMsgAction
+
+	URNs []string `json:"urns" validate:"required,min=1" engine:"evaluated"`
+}
+
+// NewSendPersonalizedBroadcast creates a new send personalized broadcast action
+func NewSendPersonalizedBroadcast(uuid flows.ActionUUID, text string, attachments []string, quickReplies []string, urns []string) *SendPersonalizedBroadcastAction {
+	return &SendPersonalizedBroadcastAction{
+		baseAction: newBaseAction(TypeSendPersonalizedBroadcast, uuid),
+		createMsgAction: createMsgAction{
+			Text:         text,
+			Attachments:  attachments,
+			QuickReplies: quickReplies,
+		},
+		URNs: urns,
+	}
+}
+
+// Execute runs this action
+func (a *SendPersonalizedBroadcastAction) Execute(run flows.FlowRun, step flows.Step, logModifier flows.ModifierCallback, logEvent flows.EventCallback) error {
+	return a.ExecuteWithContext(context.Background(), run, step, logModifier, logEvent)
+}
+
+// ExecuteWithContext runs this action, passing the given context to the contact service
+func (a *SendPersonalizedBroadcastAction) ExecuteWithContext(ctx context.Context, run flows.FlowRun, step flows.Step, logModifier flows.ModifierCallback, logEvent flows.EventCallback) error {
+	urnList := a.resolveURNs(run, logEvent)
+	if len(urnList) == 0 {
+		return nil
+	}
+
+	// recipients can still be sent messages without a contact service, they just won't be matched to contacts
+	svc, err := run.Session().Engine().Services().Contact(run.Session())
+	if err != nil {
+		logEvent(events.NewError(err))
+	}
+
+	msgs := make([]*events.PersonalizedMsg, 0, len(urnList))
+
+	for _, urn := range urnList {
+		recipient, existing := a.lookupRecipient(ctx, run, svc, urn, logEvent)
+		if recipient == nil {
+			continue
+		}
+
+		var contactRef *flows.ContactReference
+		if existing {
+			contactRef = recipient.Reference()
+		}
+
+		language := run.ContactEnvironment(recipient).DefaultLanguage()
+		languages := []envs.Language{language, run.Flow().Language()}
+
+		text, attachments, quickReplies := a.evaluateMessageForContact(run, recipient, languages, a.Text, a.Attachments, a.QuickReplies, logEvent)
+
+		textParts, quickReplies := a.applyMsgPolicy(a.recipientChannel(run, recipient, urn), urn, text, true, quickReplies, logEvent)
+
+		// if text has been split, attachments go with the first part and quick replies with the last part
+		for i, text := range textParts {
+			msg := &events.PersonalizedMsg{URN: urn, Contact: contactRef, Language: language, Text: text}
+			if i == 0 {
+				msg.Attachments = attachments
+			}
+			if i == len(textParts)-1 {
+				msg.QuickReplies = quickReplies
+			}
+			msgs = append(msgs, msg)
+		}
+	}
+
+	if len(msgs) > 0 {
+		logEvent(events.NewPersonalizedBroadcastCreated(msgs))
+	}
+
+	return nil
+}
+
+// evaluates our URN templates, returning the unique valid URNs
+func (a *SendPersonalizedBroadcastAction) resolveURNs(run flows.FlowRun, logEvent flows.EventCallback) []urns.URN {
+	urnList := make([]urns.URN, 0, len(a.URNs))
+	seen := make(map[urns.URN]bool, len(a.URNs))
+
+	for _, u := range a.URNs {
+		evaluatedURN, err := run.EvaluateTemplate(u)
+		if err != nil {
+			logEvent(events.NewError(err))
+		}
+
+		evaluatedURN = strings.TrimSpace(evaluatedURN)
+		urn := urns.URN(evaluatedURN).Normalize(string(run.Environment().DefaultCountry()))
+
+		if err := urn.Validate(); err != nil {
+			logEvent(events.NewErrorf("ignoring invalid URN '%s'", evaluatedURN))
+			continue
+		}
+
+		if !seen[urn.Identity()] {
+			urnList = append(urnList, urn)
+			seen[urn.Identity()] = true
+		}
+	}
+
+	return urnList
+}
+
+// finds the channel the given recipient will be sent messages on with the given URN, if there is one
+func (a *SendPersonalizedBroadcastAction) recipientChannel(run flows.FlowRun, recipient *flows.Contact, urn urns.URN) *flows.Channel {
+	for _, u := range recipient.URNs() {
+		if u.URN().Identity() == urn.Identity() {
+			return run.Session().Assets().Channels().GetForURN(u, assets.ChannelRoleSend)
+		}
+	}
+	return nil
+}
+
+// looks up the existing contact with the given URN, falling back to a new contact with only that URN if there isn't one
+func (a *SendPersonalizedBroadcastAction) lookupRecipient(ctx context.Context, run flows.FlowRun, svc flows.ContactService, urn urns.URN, logEvent flows.EventCallback) (*flows.Contact, bool) {
+	if svc != nil {
+		contact, err := lookupContactByURN(ctx, svc, run.Session(), urn)
+		if err != nil {
+			logEvent(events.NewError(err))
+		} else if contact != nil {
+			return contact, true
+		}
+	}
+
+	contact, err := flows.NewContact(
+		run.Session().Assets(), flows.ContactUUID(uuids.New()), flows.ContactID(0), "", envs.NilLanguage, flows.ContactStatusActive,
+		nil, dates.Now(), nil, []urns.URN{urn}, nil, nil, nil, assets.IgnoreMissing,
+	)
+	if err != nil {
+		logEvent(events.NewError(err))
+		return nil, false
+	}
+
+	return contact, false
+}
@@ -0,0 +1,147 @@
+package actions
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/envs"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/events"
+	"github.com/nyaruka/goflow/flows/modifiers"
+)
+
+func init() {
+	registerType(TypeSetContactFields, func() flows.Action { return &SetContactFieldsAction{} })
+}
+
+// TypeSetContactFields is the type for the set contact fields action
+const TypeSetContactFields string = "set_contact_fields"
+
+// the order in which field types are set, so that location fields can be parsed using their parent locations
+var fieldTypeOrder = map[assets.FieldType]int{
+	assets.FieldTypeState:    1,
+	assets.FieldTypeDistrict: 2,
+	assets.FieldTypeWard:     3,
+}
+
+// SetContactFieldsAction can be used to update several field values on the contact at once. The values are a map
+// of field keys to templates and white space is trimmed from each final value. An empty string clears the value.
+// Each value must be valid for the type of its field, e.g. a number for a number field or a known location for a
+// state field, and any which are not generate an error event and are skipped, without affecting the other fields.
+// All values are evaluated before any are set, and a single [event:contact_fields_changed] event will be created with
+// the fields whose values changed.
+//
+//   {
+//     "uuid": "8eebd020-1af5-431c-b943-aa670fc74da9",
+//     "type": "set_contact_fields",
+//     "values": {
+//       "gender": "Female",
+//       "age": "39"
+//     }
+//   }
+//
+// @action set_contact_fields
+type SetContactFieldsAction struct {
+	baseAction
+	universalAction
+
+	Values map[string]string `json:"values" validate:"required,min=1" engine:"evaluated"`
+}
+
+// NewSetContactFields creates a new set contact fields action
+func NewSetContactFields(uuid flows.ActionUUID, values map[string]string) *SetContactFieldsAction {
+	return &SetContactFieldsAction{
+		baseAction: newBaseAction(TypeSetContactFields, uuid),
+		Values:     values,
+	}
+}
+
+// Execute runs this action
+func (a *SetContactFieldsAction) Execute(run flows.FlowRun, step flows.Step, logModifier flows.ModifierCallback, logEvent flows.EventCallback) error {
+	contact := run.Contact()
+	if contact == nil {
+		logEvent(events.NewErrorf("can't execute action in session without a contact"))
+		return nil
+	}
+
+	fieldAssets := run.Session().Assets().Fields()
+	fields := make([]*flows.Field, 0, len(a.Values))
+
+	for _, key := range a.fieldKeys() {
+		field := fieldAssets.Get(key)
+		if field != nil {
+			fields = append(fields, field)
+		} else {
+			logEvent(events.NewDependencyError(assets.NewFieldReference(key, "")))
+		}
+	}
+
+	// location fields are set after other fields, and in order of their level
+	sort.SliceStable(fields, func(i, j int) bool {
+		return fieldTypeOrder[fields[i].Type()] < fieldTypeOrder[fields[j].Type()]
+	})
+
+	// evaluate all the values first so that they all see the contact as it was before this action
+	values := make(map[string]string, len(fields))
+	evaluated := make([]*flows.Field, 0, len(fields))
+
+	for _, field := range fields {
+		value, err := run.EvaluateTemplate(a.Values[field.Key()])
+
+		// if we received an error, log it and skip this field
+		if err != nil {
+			logEvent(events.NewError(err))
+			continue
+		}
+
+		values[field.Key()] = strings.TrimSpace(value)
+		evaluated = append(evaluated, field)
+	}
+
+	// a non-empty value must be parseable as the type of the field, and location values are parsed using the parent
+	// locations being set before them
+	pending := make(flows.FieldValues, len(contact.Fields()))
+	for key, value := range contact.Fields() {
+		pending[key] = value
+	}
+
+	valid := make([]*flows.Field, 0, len(evaluated))
+
+	for _, field := range evaluated {
+		value := values[field.Key()]
+		parsed := pending.Parse(run.Environment(), fieldAssets, field, value)
+
+		if value != "" && flows.NewFieldValue(field, parsed).ToXValue(run.Environment()) == nil {
+			logEvent(events.NewErrorf("value '%s' isn't a valid %s for field '%s'", value, field.Type(), field.Key()))
+			delete(values, field.Key())
+			continue
+		}
+
+		pending.Set(field, parsed)
+		valid = append(valid, field)
+	}
+
+	if len(valid) > 0 {
+		a.applyModifier(run, modifiers.NewFields(valid, values), logModifier, logEvent)
+	}
+
+	return nil
+}
+
+// Dependencies enumerates the fields referenced by the keys of our values
+func (a *SetContactFieldsAction) Dependencies(localization flows.Localization, include func(envs.Language, assets.Reference)) {
+	for _, key := range a.fieldKeys() {
+		include(envs.NilLanguage, assets.NewFieldReference(key, ""))
+	}
+}
+
+// gets the keys of the fields we set in a stable order
+func (a *SetContactFieldsAction) fieldKeys() []string {
+	keys := make([]string, 0, len(a.Values))
+	for key := range a.Values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
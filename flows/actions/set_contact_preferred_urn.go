@@ -0,0 +1,64 @@
+package actions
+
+import (
+	"github.com/nyaruka/gocommon/urns"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/events"
+	"github.com/nyaruka/goflow/flows/modifiers"
+)
+
+func init() {
+	registerType(TypeSetContactPreferredURN, func() flows.Action { return &SetContactPreferredURNAction{} })
+}
+
+// TypeSetContactPreferredURN is our type for the set preferred URN action
+const TypeSetContactPreferredURN string = "set_contact_preferred_urn"
+
+// SetContactPreferredURNAction can be used to make one of the current contact's URNs its preferred URN, by moving it
+// to the front of the contact's URNs. The URN can be a template and will be evaluated at runtime. A
+// [event:contact_urns_changed] event will be created if the order of the contact's URNs changed.
+//
+//   {
+//     "uuid": "8eebd020-1af5-431c-b943-aa670fc74da9",
+//     "type": "set_contact_preferred_urn",
+//     "urn": "@urns.twitterid"
+//   }
+//
+// @action set_contact_preferred_urn
+type SetContactPreferredURNAction struct {
+	baseAction
+	universalAction
+
+	URN string `json:"urn" validate:"required" engine:"evaluated"`
+}
+
+// NewSetContactPreferredURN creates a new set preferred URN action
+func NewSetContactPreferredURN(uuid flows.ActionUUID, urn string) *SetContactPreferredURNAction {
+	return &SetContactPreferredURNAction{
+		baseAction: newBaseAction(TypeSetContactPreferredURN, uuid),
+		URN:        urn,
+	}
+}
+
+// Execute runs this action
+func (a *SetContactPreferredURNAction) Execute(run flows.FlowRun, step flows.Step, logModifier flows.ModifierCallback, logEvent flows.EventCallback) error {
+	// only generate event if run has a contact
+	contact := run.Contact()
+	if contact == nil {
+		logEvent(events.NewErrorf("can't execute action in session without a contact"))
+		return nil
+	}
+
+	urn := evaluateContactURN(run, a.URN, "prefer", logEvent)
+	if urn == urns.NilURN {
+		return nil
+	}
+
+	if !contact.HasURN(urn) {
+		logEvent(events.NewErrorf("can't prefer URN '%s' which contact doesn't have", urn))
+		return nil
+	}
+
+	a.applyModifier(run, modifiers.NewURNs([]urns.URN{urn}, modifiers.URNsPrefer), logModifier, logEvent)
+	return nil
+}
@@ -0,0 +1,47 @@
+package flows
+
+import (
+	"github.com/nyaruka/goflow/assets"
+)
+
+// AuthProfile represents a reusable set of credentials for authenticating HTTP requests
+type AuthProfile struct {
+	assets.AuthProfile
+}
+
+// NewAuthProfile returns a new auth profile object from the given auth profile asset
+func NewAuthProfile(asset assets.AuthProfile) *AuthProfile {
+	return &AuthProfile{AuthProfile: asset}
+}
+
+// Asset returns the underlying asset
+func (p *AuthProfile) Asset() assets.AuthProfile { return p.AuthProfile }
+
+// Reference returns a reference to this auth profile
+func (p *AuthProfile) Reference() *assets.AuthProfileReference {
+	if p == nil {
+		return nil
+	}
+	return assets.NewAuthProfileReference(p.UUID(), p.Name())
+}
+
+// AuthProfileAssets provides access to all auth profile assets
+type AuthProfileAssets struct {
+	byUUID map[assets.AuthProfileUUID]*AuthProfile
+}
+
+// NewAuthProfileAssets creates a new set of auth profile assets
+func NewAuthProfileAssets(profiles []assets.AuthProfile) *AuthProfileAssets {
+	s := &AuthProfileAssets{
+		byUUID: make(map[assets.AuthProfileUUID]*AuthProfile, len(profiles)),
+	}
+	for _, asset := range profiles {
+		s.byUUID[asset.UUID()] = NewAuthProfile(asset)
+	}
+	return s
+}
+
+// Get returns the auth profile with the given UUID
+func (s *AuthProfileAssets) Get(uuid assets.AuthProfileUUID) *AuthProfile {
+	return s.byUUID[uuid]
+}
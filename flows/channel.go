@@ -74,10 +74,51 @@ func (c *Channel) Context(env envs.Environment) map[string]types.XValue {
 	}
 }
 
+// MsgPolicy returns the policy for messages sent by this channel to URNs with the given scheme
+func (c *Channel) MsgPolicy(scheme string) *MsgPolicy {
+	if c.HasRole(assets.ChannelRoleUSSD) {
+		return ussdMsgPolicy
+	}
+	if policy, found := schemeMsgPolicies[scheme]; found {
+		return policy
+	}
+	return defaultMsgPolicy
+}
+
 func (c *Channel) String() string {
 	return fmt.Sprintf("%s (%s)", c.Address(), c.Name())
 }
 
+// MsgPolicy describes the limits a channel places on the content of outgoing messages. A limit of zero means there
+// is no limit.
+type MsgPolicy struct {
+	MaxTextLength       int // text longer than this is split into multiple messages
+	MaxQuickReplies     int // quick replies beyond this number are dropped
+	MaxQuickReplyLength int // quick replies longer than this are truncated
+}
+
+// codes of the errors logged when message content is changed to comply with a channel's message policy
+const (
+	ErrorCodeMsgTextSplit           = "msg_text_split"
+	ErrorCodeMsgQuickRepliesDropped = "msg_quick_replies_dropped"
+	ErrorCodeMsgQuickReplyTruncated = "msg_quick_reply_truncated"
+)
+
+var defaultMsgPolicy = &MsgPolicy{}
+
+// USSD sessions can only display a single short screen of text
+var ussdMsgPolicy = &MsgPolicy{MaxTextLength: 182}
+
+var schemeMsgPolicies = map[string]*MsgPolicy{
+	urns.FacebookScheme:  {MaxTextLength: 2000, MaxQuickReplies: 13, MaxQuickReplyLength: 20},
+	urns.TelScheme:       {MaxTextLength: 640}, // 4 SMS segments
+	urns.TelegramScheme:  {MaxTextLength: 4096},
+	urns.TwitterIDScheme: {MaxTextLength: 10000, MaxQuickReplies: 20, MaxQuickReplyLength: 36},
+	urns.TwitterScheme:   {MaxTextLength: 10000, MaxQuickReplies: 20, MaxQuickReplyLength: 36},
+	urns.ViberScheme:     {MaxTextLength: 7000},
+	urns.WhatsAppScheme:  {MaxTextLength: 4096, MaxQuickReplies: 10, MaxQuickReplyLength: 24},
+}
+
 // ChannelAssets provides access to all channel assets
 type ChannelAssets struct {
 	all    []*Channel
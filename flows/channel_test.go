@@ -44,6 +44,21 @@ func TestChannel(t *testing.T) {
 	assert.Nil(t, (*flows.Channel)(nil).Reference())
 }
 
+func TestChannelMsgPolicy(t *testing.T) {
+	rolesDefault := []assets.ChannelRole{assets.ChannelRoleSend, assets.ChannelRoleReceive}
+	rolesUSSD := []assets.ChannelRole{assets.ChannelRoleUSSD}
+
+	android := test.NewChannel("Android", "+250961111111", []string{"tel"}, rolesDefault, nil)
+	whatsapp := test.NewChannel("WhatsApp", "+250962222222", []string{"whatsapp"}, rolesDefault, nil)
+	ussd := test.NewChannel("USSD", "*123#", []string{"tel"}, rolesUSSD, nil)
+	external := test.NewChannel("External", "12345", []string{"ext"}, rolesDefault, nil)
+
+	assert.Equal(t, &flows.MsgPolicy{MaxTextLength: 640}, android.MsgPolicy("tel"))
+	assert.Equal(t, &flows.MsgPolicy{MaxTextLength: 4096, MaxQuickReplies: 10, MaxQuickReplyLength: 24}, whatsapp.MsgPolicy("whatsapp"))
+	assert.Equal(t, &flows.MsgPolicy{MaxTextLength: 182}, ussd.MsgPolicy("tel"))
+	assert.Equal(t, &flows.MsgPolicy{}, external.MsgPolicy("ext"))
+}
+
 func TestChannelSetGetForURN(t *testing.T) {
 	rolesSend := []assets.ChannelRole{assets.ChannelRoleSend}
 	rolesReceive := []assets.ChannelRole{assets.ChannelRoleReceive}
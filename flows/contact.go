@@ -252,6 +252,28 @@ func (c *Contact) RemoveURN(urn urns.URN) bool {
 	return true
 }
 
+// SetPreferredURN moves the given URN to the front of this contact's URNs and returns whether any change was made
+func (c *Contact) SetPreferredURN(urn urns.URN) bool {
+	urn = urn.Normalize("")
+
+	for i, u := range c.urns {
+		if u.URN().Identity() == urn.Identity() {
+			if i == 0 {
+				return false
+			}
+
+			newURNs := make([]*ContactURN, 0, len(c.urns))
+			newURNs = append(newURNs, u)
+			newURNs = append(newURNs, c.urns[:i]...)
+			newURNs = append(newURNs, c.urns[i+1:]...)
+
+			c.urns = URNList(newURNs)
+			return true
+		}
+	}
+	return false
+}
+
 // HasURN checks whether the contact has the given URN
 func (c *Contact) HasURN(urn urns.URN) bool {
 	urn = urn.Normalize("")
@@ -270,9 +292,12 @@ func (c *Contact) Fields() FieldValues { return c.fields }
 // Groups returns the groups that this contact belongs to
 func (c *Contact) Groups() *GroupList { return c.groups }
 
-// Tickets returns the tickets that this contact has open
+// Tickets returns the tickets of this contact, which includes tickets closed during this session
 func (c *Contact) Tickets() *TicketList { return c.tickets }
 
+// SetTickets sets the tickets of this contact
+func (c *Contact) SetTickets(tickets *TicketList) { c.tickets = tickets }
+
 // Reference returns a reference to this contact
 func (c *Contact) Reference() *ContactReference {
 	if c == nil {
@@ -314,7 +339,7 @@ func (c *Contact) Format(env envs.Environment) string {
 //   groups:[]group -> the groups the contact belongs to
 //   fields:fields -> the custom field values of the contact
 //   channel:channel -> the preferred channel of the contact
-//   tickets:[]ticket -> the open tickets of the contact
+//   tickets:[]ticket -> the tickets of the contact, including any closed in this session
 //
 // @context contact
 func (c *Contact) Context(env envs.Environment) map[string]types.XValue {
@@ -500,7 +525,8 @@ func (c *Contact) QueryProperty(env envs.Environment, key string, propType conta
 			}
 			return vals
 		case contactql.AttributeTickets:
-			return []interface{}{decimal.NewFromInt(int64(c.tickets.Count()))}
+			// queries only count open tickets so closing a ticket can change which groups a contact is in
+			return []interface{}{decimal.NewFromInt(int64(len(c.tickets.Open())))}
 		case contactql.AttributeCreatedOn:
 			return []interface{}{c.createdOn}
 		case contactql.AttributeLastSeenOn:
@@ -582,10 +608,25 @@ type contactEnvelope struct {
 	Groups     []*assets.GroupReference `json:"groups,omitempty"    validate:"dive"`
 	Fields     map[string]*Value        `json:"fields,omitempty"`
 	Tickets    []json.RawMessage        `json:"tickets,omitempty"`
+	Encrypted  string                   `json:"encrypted,omitempty"`
+}
+
+// the properties of a contact which are encrypted when a key provider is used
+type contactPII struct {
+	Name    string            `json:"name,omitempty"`
+	URNs    []urns.URN        `json:"urns,omitempty"      validate:"dive,urn"`
+	Fields  map[string]*Value `json:"fields,omitempty"`
+	Tickets []json.RawMessage `json:"tickets,omitempty"`
 }
 
 // ReadContact decodes a contact from the passed in JSON
 func ReadContact(sa SessionAssets, data json.RawMessage, missing assets.MissingCallback) (*Contact, error) {
+	return ReadEncryptedContact(sa, data, nil, missing)
+}
+
+// ReadEncryptedContact decodes a contact from the passed in JSON, using the given key provider to decrypt its PII
+// if it was marshaled with MarshalEncrypted
+func ReadEncryptedContact(sa SessionAssets, data json.RawMessage, kp KeyProvider, missing assets.MissingCallback) (*Contact, error) {
 	var envelope contactEnvelope
 	var err error
 
@@ -593,6 +634,23 @@ func ReadContact(sa SessionAssets, data json.RawMessage, missing assets.MissingC
 		return nil, errors.Wrap(err, "unable to read contact")
 	}
 
+	if envelope.Encrypted != "" {
+		if kp == nil {
+			return nil, errors.New("unable to read encrypted contact without key provider")
+		}
+
+		plaintext, err := DecryptPII(kp, envelope.Encrypted)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to decrypt contact")
+		}
+
+		pii := &contactPII{}
+		if err := utils.UnmarshalAndValidate(plaintext, pii); err != nil {
+			return nil, errors.Wrap(err, "unable to read contact")
+		}
+		envelope.Name, envelope.URNs, envelope.Fields, envelope.Tickets = pii.Name, pii.URNs, pii.Fields, pii.Tickets
+	}
+
 	c := &Contact{
 		uuid:       envelope.UUID,
 		id:         envelope.ID,
@@ -640,6 +698,12 @@ func ReadContact(sa SessionAssets, data json.RawMessage, missing assets.MissingC
 
 // MarshalJSON marshals this contact into JSON
 func (c *Contact) MarshalJSON() ([]byte, error) {
+	return c.MarshalEncrypted(nil)
+}
+
+// MarshalEncrypted marshals this contact into JSON, using the given key provider (if not nil) to encrypt its name,
+// URNs, field values and tickets
+func (c *Contact) MarshalEncrypted(kp KeyProvider) ([]byte, error) {
 	var err error
 	tickets := make([]json.RawMessage, len(c.tickets.tickets))
 	for i, ticket := range c.tickets.tickets {
@@ -673,5 +737,16 @@ func (c *Contact) MarshalJSON() ([]byte, error) {
 		}
 	}
 
+	if kp != nil {
+		plaintext, err := jsonx.Marshal(&contactPII{Name: ce.Name, URNs: ce.URNs, Fields: ce.Fields, Tickets: ce.Tickets})
+		if err != nil {
+			return nil, err
+		}
+		if ce.Encrypted, err = EncryptPII(kp, plaintext); err != nil {
+			return nil, errors.Wrap(err, "unable to encrypt contact")
+		}
+		ce.Name, ce.URNs, ce.Fields, ce.Tickets = "", nil, nil, nil
+	}
+
 	return jsonx.Marshal(ce)
 }
@@ -120,6 +120,13 @@ func TestContact(t *testing.T) {
 	assert.True(t, contact.RemoveURN("whatsapp:235423721788"))  // did have URN
 	assert.False(t, contact.RemoveURN("whatsapp:235423721788")) // no longer has URN
 
+	assert.False(t, contact.SetPreferredURN("tel:+16300000000"))   // doesn't have URN
+	assert.False(t, contact.SetPreferredURN("tel:+120-2456-1111")) // already preferred
+	assert.True(t, contact.SetPreferredURN("twitter:joey"))        // moved to front
+	assert.Equal(t, urns.URN("twitter:joey"), contact.URNs()[0].URN())
+	assert.True(t, contact.SetPreferredURN("tel:+12024561111")) // moved back to front
+	assert.Equal(t, []urns.URN{"tel:+12024561111?channel=294a14d4-c998-41e5-a314-5941b97b89d7", "twitter:joey"}, contact.URNs().RawURNs())
+
 	test.AssertXEqual(t, types.NewXObject(map[string]types.XValue{
 		"discord":    nil,
 		"ext":        nil,
@@ -215,6 +222,43 @@ func TestReadContact(t *testing.T) {
 	assert.EqualError(t, err, "unable to read contact: field 'status' is not a valid contact status")
 }
 
+func TestEncryptedContact(t *testing.T) {
+	session, _, err := test.CreateTestSession("", envs.RedactionPolicyNone)
+	require.NoError(t, err)
+
+	kp := flows.NewStaticKeyProvider("k1", map[string][]byte{"k1": []byte("0123456789abcdef0123456789abcdef")})
+	contact := session.Contact()
+
+	plainJSON, err := jsonx.Marshal(contact)
+	require.NoError(t, err)
+	assert.Contains(t, string(plainJSON), "+12024561111")
+
+	encryptedJSON, err := contact.MarshalEncrypted(kp)
+	require.NoError(t, err)
+	assert.NotContains(t, string(encryptedJSON), "+12024561111")
+	assert.NotContains(t, string(encryptedJSON), "AACC55")
+	assert.NotContains(t, string(encryptedJSON), contact.Name())
+	assert.Contains(t, string(encryptedJSON), string(contact.UUID()))
+
+	// can't be read without a key provider
+	_, err = flows.ReadContact(session.Assets(), encryptedJSON, assets.PanicOnMissing)
+	assert.EqualError(t, err, "unable to read encrypted contact without key provider")
+
+	// or with the wrong keys
+	wrongKP := flows.NewStaticKeyProvider("k1", map[string][]byte{"k1": []byte("fedcba9876543210fedcba9876543210")})
+	_, err = flows.ReadEncryptedContact(session.Assets(), encryptedJSON, wrongKP, assets.PanicOnMissing)
+	assert.EqualError(t, err, "unable to decrypt contact: unable to decrypt value with key 'k1': cipher: message authentication failed")
+
+	decrypted, err := flows.ReadEncryptedContact(session.Assets(), encryptedJSON, kp, assets.PanicOnMissing)
+	require.NoError(t, err)
+	assert.True(t, contact.Equal(decrypted))
+
+	// plaintext contacts can still be read when there is a key provider
+	decrypted, err = flows.ReadEncryptedContact(session.Assets(), plainJSON, kp, assets.PanicOnMissing)
+	require.NoError(t, err)
+	assert.True(t, contact.Equal(decrypted))
+}
+
 func TestContactFormat(t *testing.T) {
 	env := envs.NewBuilder().Build()
 	sa, _ := engine.NewSessionAssets(env, static.NewEmptySource(), nil)
@@ -414,6 +458,15 @@ func TestContactQuery(t *testing.T) {
 				"subject": "Old ticket",
 				"body": "I have a problem",
 				"assignee": null
+			},
+			{
+				"uuid": "8d6b2c1e-4f3a-4b5c-9d7e-1a2b3c4d5e6f",
+				"ticketer": {
+					"uuid": "19dc6346-9623-4fe4-be80-538d493ecdf5",
+					"name": "Support Tickets"
+				},
+				"body": "I had a problem",
+				"status": "closed"
 			}
 		],
 		"language": "eng",
@@ -514,7 +567,7 @@ func TestContactQuery(t *testing.T) {
 		{`group = customers`, envs.RedactionPolicyNone, false, ""},
 		{`group != customers`, envs.RedactionPolicyNone, true, ""},
 
-		{`tickets = 1`, envs.RedactionPolicyNone, true, ""},
+		{`tickets = 1`, envs.RedactionPolicyNone, true, ""}, // closed tickets aren't counted
 		{`tickets = 0`, envs.RedactionPolicyNone, false, ""},
 		{`tickets != 1`, envs.RedactionPolicyNone, false, ""},
 		{`tickets != 0`, envs.RedactionPolicyNone, true, ""},
@@ -210,6 +210,8 @@ func TestNewFlow(t *testing.T) {
 		123, // revision
 		30,  // expires after minutes
 		definition.NewLocalization(),
+		nil, // params
+		nil, // outputs
 		[]flows.Node{
 			definition.NewNode(
 				flows.NodeUUID("a58be63b-907d-4a1a-856b-0bb5579d7507"),
@@ -0,0 +1,123 @@
+package flows
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// prefix of values which have been encrypted, followed by the key ID and the base64 encoded nonce and ciphertext
+const encryptedPrefix = "enc:v1:"
+
+// KeyProvider provides the keys used to encrypt personally identifiable information (PII) in serialized sessions.
+// Keys must be 16, 24 or 32 bytes to select AES-128, AES-192 or AES-256.
+type KeyProvider interface {
+	// EncryptionKey returns the ID and value of the key which should be used to encrypt new values
+	EncryptionKey() (string, []byte, error)
+
+	// DecryptionKey returns the key with the given ID, which may no longer be the current encryption key
+	DecryptionKey(id string) ([]byte, error)
+}
+
+// EncryptPII encrypts the given plaintext with the current key of the given provider
+func EncryptPII(kp KeyProvider, plaintext []byte) (string, error) {
+	keyID, key, err := kp.EncryptionKey()
+	if err != nil {
+		return "", errors.Wrap(err, "unable to get encryption key")
+	}
+	if keyID == "" || strings.Contains(keyID, ":") {
+		return "", errors.Errorf("invalid encryption key ID '%s'", keyID)
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", errors.Wrap(err, "unable to generate nonce")
+	}
+
+	sealed := gcm.Seal(nonce, nonce, plaintext, []byte(keyID))
+
+	return encryptedPrefix + keyID + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// DecryptPII decrypts a value previously encrypted with EncryptPII
+func DecryptPII(kp KeyProvider, value string) ([]byte, error) {
+	if !IsEncryptedPII(value) {
+		return nil, errors.New("value is not encrypted")
+	}
+
+	parts := strings.SplitN(value[len(encryptedPrefix):], ":", 2)
+	if len(parts) != 2 {
+		return nil, errors.New("encrypted value is malformed")
+	}
+	keyID := parts[0]
+
+	sealed, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, errors.Wrap(err, "encrypted value is malformed")
+	}
+
+	key, err := kp.DecryptionKey(keyID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to get decryption key '%s'", keyID)
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, errors.New("encrypted value is malformed")
+	}
+
+	plaintext, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], []byte(keyID))
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to decrypt value with key '%s'", keyID)
+	}
+	return plaintext, nil
+}
+
+// IsEncryptedPII returns whether the given value looks like it was encrypted with EncryptPII
+func IsEncryptedPII(value string) bool {
+	return strings.HasPrefix(value, encryptedPrefix)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid encryption key")
+	}
+	return cipher.NewGCM(block)
+}
+
+type staticKeyProvider struct {
+	currentID string
+	keys      map[string][]byte
+}
+
+// NewStaticKeyProvider creates a new key provider from a fixed set of keys, where new values are encrypted
+// with the key with the given ID
+func NewStaticKeyProvider(currentID string, keys map[string][]byte) KeyProvider {
+	return &staticKeyProvider{currentID: currentID, keys: keys}
+}
+
+func (p *staticKeyProvider) EncryptionKey() (string, []byte, error) {
+	key, err := p.DecryptionKey(p.currentID)
+	return p.currentID, key, err
+}
+
+func (p *staticKeyProvider) DecryptionKey(id string) ([]byte, error) {
+	key, ok := p.keys[id]
+	if !ok {
+		return nil, errors.Errorf("no key with ID '%s'", id)
+	}
+	return key, nil
+}
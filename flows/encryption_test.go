@@ -0,0 +1,64 @@
+package flows_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nyaruka/goflow/flows"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptPII(t *testing.T) {
+	keys := map[string][]byte{
+		"2021": []byte("0123456789abcdef0123456789abcdef"),
+		"2022": []byte("fedcba9876543210fedcba9876543210"),
+		"bad":  []byte("tooshort"),
+	}
+	kp2021 := flows.NewStaticKeyProvider("2021", keys)
+	kp2022 := flows.NewStaticKeyProvider("2022", keys)
+
+	encrypted, err := flows.EncryptPII(kp2021, []byte(`"tel:+12065551212"`))
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(encrypted, "enc:v1:2021:"))
+	assert.True(t, flows.IsEncryptedPII(encrypted))
+	assert.NotContains(t, encrypted, "12065551212")
+
+	// each encryption uses a new nonce
+	encrypted2, err := flows.EncryptPII(kp2021, []byte(`"tel:+12065551212"`))
+	require.NoError(t, err)
+	assert.NotEqual(t, encrypted, encrypted2)
+
+	// values can still be decrypted after the current key has been rotated
+	decrypted, err := flows.DecryptPII(kp2022, encrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, `"tel:+12065551212"`, string(decrypted))
+
+	// unless the old key is no longer available
+	_, err = flows.DecryptPII(flows.NewStaticKeyProvider("2022", map[string][]byte{"2022": keys["2022"]}), encrypted)
+	assert.EqualError(t, err, "unable to get decryption key '2021': no key with ID '2021'")
+
+	// tampering with the key ID is detected
+	_, err = flows.DecryptPII(kp2021, strings.Replace(encrypted, ":2021:", ":2022:", 1))
+	assert.EqualError(t, err, "unable to decrypt value with key '2022': cipher: message authentication failed")
+
+	_, err = flows.DecryptPII(kp2021, "tel:+12065551212")
+	assert.EqualError(t, err, "value is not encrypted")
+
+	_, err = flows.DecryptPII(kp2021, "enc:v1:2021")
+	assert.EqualError(t, err, "encrypted value is malformed")
+
+	_, err = flows.DecryptPII(kp2021, "enc:v1:2021:AAAA")
+	assert.EqualError(t, err, "encrypted value is malformed")
+
+	_, err = flows.EncryptPII(flows.NewStaticKeyProvider("bad", keys), []byte(`"x"`))
+	assert.EqualError(t, err, "invalid encryption key: crypto/aes: invalid key size 8")
+
+	_, err = flows.EncryptPII(flows.NewStaticKeyProvider("2023", keys), []byte(`"x"`))
+	assert.EqualError(t, err, "unable to get encryption key: no key with ID '2023'")
+
+	_, err = flows.EncryptPII(flows.NewStaticKeyProvider("a:b", map[string][]byte{"a:b": keys["2021"]}), []byte(`"x"`))
+	assert.EqualError(t, err, "invalid encryption key ID 'a:b'")
+}
@@ -79,7 +79,7 @@ func TestSessionAssetsWithSourceErrors(t *testing.T) {
 	_, err = sa.Flows().Get(assets.FlowUUID("ddba5842-252f-4a20-b901-08696fc773e2"))
 	assert.EqualError(t, err, "unable to load flow assets")
 
-	for _, errType := range []string{"channels", "classifiers", "fields", "globals", "groups", "labels", "locations", "resthooks", "templates", "users"} {
+	for _, errType := range []string{"auth_profiles", "channels", "classifiers", "fields", "globals", "groups", "labels", "locations", "resthooks", "templates", "users"} {
 		source.currentErrType = errType
 		_, err = engine.NewSessionAssets(env, source, nil)
 		assert.EqualError(t, err, fmt.Sprintf("unable to load %s assets", errType), "error mismatch for type %s", errType)
@@ -98,6 +98,10 @@ func (s *testSource) err(t string) error {
 	return nil
 }
 
+func (s *testSource) AuthProfiles() ([]assets.AuthProfile, error) {
+	return nil, s.err("auth_profiles")
+}
+
 func (s *testSource) Channels() ([]assets.Channel, error) {
 	return nil, s.err("channels")
 }
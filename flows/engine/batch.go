@@ -0,0 +1,98 @@
+package engine
+
+import (
+	"context"
+	"sync"
+
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/envs"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/triggers"
+)
+
+// BatchResult is the outcome of starting a single session in a batch
+type BatchResult struct {
+	Trigger flows.Trigger
+	Session flows.Session
+	Sprint  flows.Sprint
+	Err     error
+}
+
+// StartBatch starts a session for each trigger received on the given channel, using a pool of the given number of
+// workers which share the same session assets. Results are sent on the returned channel which is unbuffered, so a
+// slow consumer will stop workers taking more triggers. The returned channel is closed once the triggers channel
+// has been closed and drained, or the context is cancelled.
+func StartBatch(ctx context.Context, eng flows.Engine, sa flows.SessionAssets, trigs <-chan flows.Trigger, workers int) <-chan *BatchResult {
+	results := make(chan *BatchResult)
+
+	if workers < 1 {
+		workers = 1
+	}
+
+	wg := &sync.WaitGroup{}
+	wg.Add(workers)
+
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+
+			for {
+				var trigger flows.Trigger
+				var ok bool
+
+				select {
+				case <-ctx.Done():
+					return
+				case trigger, ok = <-trigs:
+					if !ok {
+						return
+					}
+				}
+
+				session, sprint, err := eng.NewSessionWithContext(ctx, sa, trigger)
+
+				select {
+				case <-ctx.Done():
+					return
+				case results <- &BatchResult{Trigger: trigger, Session: session, Sprint: sprint, Err: err}:
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	return results
+}
+
+// BatchTriggers creates batch manual triggers for the given flow, one for each contact received on the given
+// channel. The returned channel is closed once the contacts channel is closed or the context is cancelled.
+func BatchTriggers(ctx context.Context, env envs.Environment, flow *assets.FlowReference, contacts <-chan *flows.Contact) <-chan flows.Trigger {
+	trigs := make(chan flows.Trigger)
+
+	go func() {
+		defer close(trigs)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case contact, ok := <-contacts:
+				if !ok {
+					return
+				}
+
+				select {
+				case <-ctx.Done():
+					return
+				case trigs <- triggers.NewBuilder(env, flow, contact).Manual().AsBatch().Build():
+				}
+			}
+		}
+	}()
+
+	return trigs
+}
@@ -0,0 +1,81 @@
+package engine_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/envs"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/engine"
+	"github.com/nyaruka/goflow/test"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStartBatch(t *testing.T) {
+	assetsJSON, err := os.ReadFile("../../test/testdata/runner/two_questions.json")
+	require.NoError(t, err)
+
+	sa, err := test.CreateSessionAssets(assetsJSON, "")
+	require.NoError(t, err)
+
+	env := envs.NewBuilder().Build()
+	flow := assets.NewFlowReference("615b8a0f-588c-4d20-a05f-363b0b4ce6f4", "Two Questions")
+	eng := engine.NewBuilder().Build()
+
+	contacts := make(chan *flows.Contact)
+	go func() {
+		defer close(contacts)
+		for i := 0; i < 50; i++ {
+			contacts <- flows.NewEmptyContact(sa, fmt.Sprintf("Contact %d", i), envs.NilLanguage, nil)
+		}
+	}()
+
+	ctx := context.Background()
+	results := engine.StartBatch(ctx, eng, sa, engine.BatchTriggers(ctx, env, flow, contacts), 4)
+
+	names := make(map[string]bool)
+	for result := range results {
+		require.NoError(t, result.Err)
+		assert.Equal(t, flows.SessionStatusWaiting, result.Session.Status())
+		assert.True(t, result.Session.BatchStart())
+		assert.Equal(t, result.Trigger.Contact().Name(), result.Session.Contact().Name())
+		assert.Equal(t, 3, len(result.Sprint.Events()))
+
+		names[result.Session.Contact().Name()] = true
+	}
+
+	assert.Equal(t, 50, len(names))
+
+	// cancelling the context stops the batch
+	ctx, cancel := context.WithCancel(context.Background())
+	contacts = make(chan *flows.Contact)
+	produced := make(chan bool)
+	go func() {
+		defer close(produced)
+		for i := 0; i < 50; i++ {
+			select {
+			case <-ctx.Done():
+				return
+			case contacts <- flows.NewEmptyContact(sa, fmt.Sprintf("Contact %d", i), envs.NilLanguage, nil):
+			}
+		}
+	}()
+
+	results = engine.StartBatch(ctx, eng, sa, engine.BatchTriggers(ctx, env, flow, contacts), 2)
+
+	<-results
+	cancel()
+
+	received := 1
+	for range results {
+		received++
+	}
+	<-produced
+
+	assert.Less(t, received, 50)
+}
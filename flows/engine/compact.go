@@ -0,0 +1,302 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"strconv"
+
+	"github.com/nyaruka/gocommon/jsonx"
+	"github.com/nyaruka/goflow/flows"
+
+	"github.com/pkg/errors"
+)
+
+// Sessions can be serialized in a compact binary form which is a tagged encoding of the session JSON in which every
+// string (object keys, UUIDs, asset names etc) is written once and thereafter referenced by its index in a table of
+// previously seen strings. Because sessions contain many references to the same assets, runs and nodes, this is
+// considerably smaller than the equivalent JSON. The first byte of the magic header can't start a JSON document so
+// ReadSession can accept either format.
+var compactMagic = []byte{0x00, 'g', 'f', 0x01}
+
+const (
+	compactTagNull byte = iota
+	compactTagFalse
+	compactTagTrue
+	compactTagInt
+	compactTagNumber
+	compactTagString
+	compactTagStringRef
+	compactTagArray
+	compactTagObject
+	compactTagEnd
+)
+
+// MarshalSessionCompact marshals the given session into the compact binary format
+func MarshalSessionCompact(session flows.Session) ([]byte, error) {
+	data, err := jsonx.Marshal(session)
+	if err != nil {
+		return nil, err
+	}
+
+	return EncodeCompactSession(data)
+}
+
+// EncodeCompactSession converts the given session JSON into the compact binary format
+func EncodeCompactSession(data json.RawMessage) ([]byte, error) {
+	e := &compactEncoder{
+		out:     bytes.NewBuffer(make([]byte, 0, len(data)/2)),
+		strings: make(map[string]int),
+	}
+	e.out.Write(compactMagic)
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	// the decoder doesn't complain if input ends inside an object or array so track nesting ourselves
+	depth, tokens := 0, 0
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to encode session")
+		}
+		if delim, isDelim := token.(json.Delim); isDelim {
+			if delim == '{' || delim == '[' {
+				depth++
+			} else {
+				depth--
+			}
+		}
+		e.writeToken(token)
+		tokens++
+	}
+
+	if depth != 0 || tokens == 0 {
+		return nil, errors.Wrap(io.ErrUnexpectedEOF, "unable to encode session")
+	}
+
+	return e.out.Bytes(), nil
+}
+
+// DecodeCompactSession converts the given compact binary session back into JSON
+func DecodeCompactSession(data []byte) (json.RawMessage, error) {
+	if !isCompactSession(data) {
+		return nil, errors.New("unable to decode session: missing compact header")
+	}
+
+	d := &compactDecoder{in: data[len(compactMagic):], out: bytes.NewBuffer(make([]byte, 0, len(data)*2))}
+
+	if err := d.readValue(); err != nil {
+		return nil, errors.Wrap(err, "unable to decode session")
+	}
+	if d.pos != len(d.in) {
+		return nil, errors.New("unable to decode session: unexpected trailing data")
+	}
+
+	return d.out.Bytes(), nil
+}
+
+// checks whether the given data has the compact session header
+func isCompactSession(data []byte) bool {
+	return bytes.HasPrefix(data, compactMagic)
+}
+
+type compactEncoder struct {
+	out     *bytes.Buffer
+	strings map[string]int
+	scratch [binary.MaxVarintLen64]byte
+}
+
+func (e *compactEncoder) writeToken(token json.Token) {
+	switch typed := token.(type) {
+	case nil:
+		e.out.WriteByte(compactTagNull)
+	case bool:
+		if typed {
+			e.out.WriteByte(compactTagTrue)
+		} else {
+			e.out.WriteByte(compactTagFalse)
+		}
+	case json.Number:
+		// integers which survive a round trip are written as varints, anything else as the original literal
+		if i, err := strconv.ParseInt(string(typed), 10, 64); err == nil && strconv.FormatInt(i, 10) == string(typed) {
+			e.out.WriteByte(compactTagInt)
+			e.out.Write(e.scratch[:binary.PutVarint(e.scratch[:], i)])
+		} else {
+			e.out.WriteByte(compactTagNumber)
+			e.writeString(string(typed))
+		}
+	case string:
+		e.writeString(typed)
+	case json.Delim:
+		switch typed {
+		case '[':
+			e.out.WriteByte(compactTagArray)
+		case '{':
+			e.out.WriteByte(compactTagObject)
+		default:
+			e.out.WriteByte(compactTagEnd)
+		}
+	}
+}
+
+func (e *compactEncoder) writeString(s string) {
+	if index, seen := e.strings[s]; seen {
+		e.out.WriteByte(compactTagStringRef)
+		e.writeUvarint(uint64(index))
+		return
+	}
+
+	e.strings[s] = len(e.strings)
+	e.out.WriteByte(compactTagString)
+	e.writeUvarint(uint64(len(s)))
+	e.out.WriteString(s)
+}
+
+func (e *compactEncoder) writeUvarint(v uint64) {
+	e.out.Write(e.scratch[:binary.PutUvarint(e.scratch[:], v)])
+}
+
+type compactDecoder struct {
+	in  []byte
+	pos int
+	out *bytes.Buffer
+
+	// previously seen strings, already escaped as JSON
+	strings [][]byte
+}
+
+func (d *compactDecoder) readValue() error {
+	tag, err := d.readByte()
+	if err != nil {
+		return err
+	}
+
+	switch tag {
+	case compactTagNull:
+		d.out.WriteString("null")
+	case compactTagFalse:
+		d.out.WriteString("false")
+	case compactTagTrue:
+		d.out.WriteString("true")
+	case compactTagInt:
+		i, n := binary.Varint(d.in[d.pos:])
+		if n <= 0 {
+			return errors.New("invalid integer")
+		}
+		d.pos += n
+		d.out.WriteString(strconv.FormatInt(i, 10))
+	case compactTagNumber:
+		s, err := d.readStringTag()
+		if err != nil {
+			return err
+		}
+		// numbers are stored by their escaped string so strip the quotes
+		d.out.Write(s[1 : len(s)-1])
+	case compactTagString, compactTagStringRef:
+		s, err := d.readString(tag)
+		if err != nil {
+			return err
+		}
+		d.out.Write(s)
+	case compactTagArray:
+		return d.readContainer('[', ']', false)
+	case compactTagObject:
+		return d.readContainer('{', '}', true)
+	default:
+		return errors.Errorf("invalid tag %d at position %d", tag, d.pos-1)
+	}
+	return nil
+}
+
+func (d *compactDecoder) readContainer(open, close byte, keyed bool) error {
+	d.out.WriteByte(open)
+
+	for i := 0; ; i++ {
+		if d.pos >= len(d.in) {
+			return io.ErrUnexpectedEOF
+		}
+		if d.in[d.pos] == compactTagEnd {
+			d.pos++
+			break
+		}
+		if i > 0 {
+			d.out.WriteByte(',')
+		}
+		if keyed {
+			key, err := d.readStringTag()
+			if err != nil {
+				return err
+			}
+			d.out.Write(key)
+			d.out.WriteByte(':')
+		}
+		if err := d.readValue(); err != nil {
+			return err
+		}
+	}
+
+	d.out.WriteByte(close)
+	return nil
+}
+
+func (d *compactDecoder) readStringTag() ([]byte, error) {
+	tag, err := d.readByte()
+	if err != nil {
+		return nil, err
+	}
+	if tag != compactTagString && tag != compactTagStringRef {
+		return nil, errors.Errorf("expected string at position %d", d.pos-1)
+	}
+	return d.readString(tag)
+}
+
+func (d *compactDecoder) readString(tag byte) ([]byte, error) {
+	v, err := d.readUvarint()
+	if err != nil {
+		return nil, err
+	}
+
+	if tag == compactTagStringRef {
+		if v >= uint64(len(d.strings)) {
+			return nil, errors.Errorf("invalid string reference %d", v)
+		}
+		return d.strings[v], nil
+	}
+
+	if v > uint64(len(d.in)-d.pos) {
+		return nil, io.ErrUnexpectedEOF
+	}
+	raw := string(d.in[d.pos : d.pos+int(v)])
+	d.pos += int(v)
+
+	escaped, err := jsonx.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	d.strings = append(d.strings, escaped)
+	return escaped, nil
+}
+
+func (d *compactDecoder) readByte() (byte, error) {
+	if d.pos >= len(d.in) {
+		return 0, io.ErrUnexpectedEOF
+	}
+	b := d.in[d.pos]
+	d.pos++
+	return b, nil
+}
+
+func (d *compactDecoder) readUvarint() (uint64, error) {
+	v, n := binary.Uvarint(d.in[d.pos:])
+	if n <= 0 {
+		return 0, errors.New("invalid length")
+	}
+	d.pos += n
+	return v, nil
+}
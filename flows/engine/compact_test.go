@@ -0,0 +1,129 @@
+package engine_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nyaruka/gocommon/jsonx"
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/envs"
+	"github.com/nyaruka/goflow/flows/engine"
+	"github.com/nyaruka/goflow/test"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompactSessionEncoding(t *testing.T) {
+	testFiles, err := filepath.Glob("../../test/testdata/runner/*.test*.json")
+	require.NoError(t, err)
+
+	numSessions := 0
+
+	for _, testFile := range testFiles {
+		testJSON, err := os.ReadFile(testFile)
+		require.NoError(t, err)
+
+		snapshot := &struct {
+			Outputs []struct {
+				Session json.RawMessage `json:"session"`
+			} `json:"outputs"`
+		}{}
+		jsonx.MustUnmarshal(testJSON, snapshot)
+
+		for i, output := range snapshot.Outputs {
+			compact, err := engine.EncodeCompactSession(output.Session)
+			require.NoError(t, err, "error encoding output[%d] in %s", i, testFile)
+
+			assert.Less(t, len(compact), len(output.Session), "compact session for output[%d] in %s not smaller than JSON", i, testFile)
+
+			decoded, err := engine.DecodeCompactSession(compact)
+			require.NoError(t, err, "error decoding output[%d] in %s", i, testFile)
+			test.AssertEqualJSON(t, output.Session, decoded, "decoded session mismatch for output[%d] in %s", i, testFile)
+
+			numSessions++
+		}
+	}
+
+	assert.Greater(t, numSessions, 20)
+
+	// numbers which aren't integers keep their original representation
+	compact, err := engine.EncodeCompactSession([]byte(`{"a":[1,-20,1.50,1e3,12345678901234567890],"b":"1.50","c":[true,false,null,"",{}]}`))
+	require.NoError(t, err)
+	decoded, err := engine.DecodeCompactSession(compact)
+	require.NoError(t, err)
+	assert.Equal(t, `{"a":[1,-20,1.50,1e3,12345678901234567890],"b":"1.50","c":[true,false,null,"",{}]}`, string(decoded))
+
+	_, err = engine.EncodeCompactSession([]byte(`{"a":`))
+	assert.EqualError(t, err, "unable to encode session: unexpected EOF")
+
+	_, err = engine.DecodeCompactSession([]byte(`{"a":1}`))
+	assert.EqualError(t, err, "unable to decode session: missing compact header")
+
+	_, err = engine.DecodeCompactSession(compact[:len(compact)-3])
+	assert.EqualError(t, err, "unable to decode session: unexpected EOF")
+
+	_, err = engine.DecodeCompactSession(append(compact, 0x00))
+	assert.EqualError(t, err, "unable to decode session: unexpected trailing data")
+}
+
+func TestReadCompactSession(t *testing.T) {
+	session, _, err := test.CreateTestSession("", envs.RedactionPolicyNone)
+	require.NoError(t, err)
+
+	sessionJSON, err := jsonx.Marshal(session)
+	require.NoError(t, err)
+
+	compact, err := engine.MarshalSessionCompact(session)
+	require.NoError(t, err)
+
+	assert.Less(t, len(compact), len(sessionJSON)*2/3)
+
+	// engine can read either format
+	eng := engine.NewBuilder().Build()
+	read, err := eng.ReadSession(session.Assets(), compact, assets.PanicOnMissing)
+	require.NoError(t, err)
+
+	readJSON, err := jsonx.Marshal(read)
+	require.NoError(t, err)
+	test.AssertEqualJSON(t, sessionJSON, readJSON, "session read from compact format mismatch")
+
+	_, err = eng.ReadSession(session.Assets(), compact[:20], assets.PanicOnMissing)
+	assert.EqualError(t, err, "unable to decode session: unexpected EOF")
+}
+
+func BenchmarkSessionJSON(b *testing.B) {
+	session, _, err := test.CreateTestSession("", envs.RedactionPolicyNone)
+	require.NoError(b, err)
+
+	eng := engine.NewBuilder().Build()
+	b.ResetTimer()
+
+	var size int
+	for n := 0; n < b.N; n++ {
+		data, _ := jsonx.Marshal(session)
+		eng.ReadSession(session.Assets(), data, assets.PanicOnMissing)
+		size = len(data)
+	}
+
+	b.ReportMetric(float64(size), "bytes/session")
+}
+
+func BenchmarkSessionCompact(b *testing.B) {
+	session, _, err := test.CreateTestSession("", envs.RedactionPolicyNone)
+	require.NoError(b, err)
+
+	eng := engine.NewBuilder().Build()
+	b.ResetTimer()
+
+	var size int
+	for n := 0; n < b.N; n++ {
+		data, _ := engine.MarshalSessionCompact(session)
+		eng.ReadSession(session.Assets(), data, assets.PanicOnMissing)
+		size = len(data)
+	}
+
+	b.ReportMetric(float64(size), "bytes/session")
+}
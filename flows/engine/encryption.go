@@ -0,0 +1,173 @@
+package engine
+
+import (
+	"encoding/json"
+
+	"github.com/nyaruka/gocommon/jsonx"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/events"
+
+	"github.com/pkg/errors"
+)
+
+// properties of session parts which contain PII and so are encrypted when the engine has a key provider. The session
+// contact itself is encrypted by the contact.
+var (
+	piiTriggerProperties = []string{"contact", "connection", "msg", "run_summary"}
+	piiInputProperties   = []string{"urn", "text", "attachments"}
+	piiResultProperties  = []string{"value", "input", "extra"}
+	piiWaitProperties    = []string{"urn"}
+	piiEventProperties   = map[string][]string{
+		events.TypeAirtimeTransferred:           {"sender", "recipient", "http_logs"},
+		events.TypeBroadcastCreated:             {"translations", "contacts", "urns"},
+		events.TypeContactFieldChanged:          {"value"},
+		events.TypeContactFieldsChanged:         {"values"},
+		events.TypeContactNameChanged:           {"name"},
+		events.TypeContactRefreshed:             {"contact"},
+		events.TypeContactURNsChanged:           {"urns"},
+		events.TypeDialWait:                     {"urn"},
+		events.TypeEmailCreated:                 {"addresses", "subject", "body"},
+		events.TypeEmailSent:                    {"to", "subject", "body"},
+		events.TypeError:                        {"text"},
+		events.TypeIVRCreated:                   {"msg"},
+		events.TypeMsgCreated:                   {"msg"},
+		events.TypeMsgReceived:                  {"msg"},
+		events.TypePersonalizedBroadcastCreated: {"messages"},
+		events.TypeResthookCalled:               {"payload"},
+		events.TypeRunResultChanged:             piiResultProperties,
+		events.TypeServiceCalled:                {"http_logs"},
+		events.TypeSessionTriggered:             {"contacts", "urns", "run_summary"},
+		events.TypeTicketNoteAdded:              {"note"},
+		events.TypeTicketOpened:                 {"ticket"},
+		events.TypeWebhookCalled:                {"url", "request", "response"},
+	}
+)
+
+type valueTransform func(json.RawMessage) (json.RawMessage, error)
+
+// encrypts the PII in the trigger, input and runs of the given session envelope
+func encryptSessionPII(e *sessionEnvelope, kp flows.KeyProvider) error {
+	return transformSessionPII(e, func(v json.RawMessage) (json.RawMessage, error) {
+		encrypted, err := flows.EncryptPII(kp, v)
+		if err != nil {
+			return nil, err
+		}
+		return jsonx.Marshal(encrypted)
+	})
+}
+
+// decrypts the PII in the trigger, input and runs of the given session envelope, leaving any values which aren't
+// encrypted as they are
+func decryptSessionPII(e *sessionEnvelope, kp flows.KeyProvider) error {
+	return transformSessionPII(e, func(v json.RawMessage) (json.RawMessage, error) {
+		var s string
+		if json.Unmarshal(v, &s) != nil || !flows.IsEncryptedPII(s) {
+			return v, nil
+		}
+		return flows.DecryptPII(kp, s)
+	})
+}
+
+func transformSessionPII(e *sessionEnvelope, fn valueTransform) error {
+	var err error
+
+	if e.Trigger, err = transformProperties(e.Trigger, piiTriggerProperties, fn); err != nil {
+		return errors.Wrap(err, "unable to transform trigger")
+	}
+	if e.Input, err = transformProperties(e.Input, piiInputProperties, fn); err != nil {
+		return errors.Wrap(err, "unable to transform input")
+	}
+	if e.Wait, err = transformProperties(e.Wait, piiWaitProperties, fn); err != nil {
+		return errors.Wrap(err, "unable to transform wait")
+	}
+	for i := range e.Runs {
+		if e.Runs[i], err = transformRunPII(e.Runs[i], fn); err != nil {
+			return errors.Wrapf(err, "unable to transform run %d", i)
+		}
+	}
+	return nil
+}
+
+func transformRunPII(data json.RawMessage, fn valueTransform) (json.RawMessage, error) {
+	run := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(data, &run); err != nil {
+		return nil, err
+	}
+
+	if run["events"] != nil {
+		var evts []json.RawMessage
+		if err := json.Unmarshal(run["events"], &evts); err != nil {
+			return nil, err
+		}
+
+		for i := range evts {
+			typed := &struct {
+				Type string `json:"type"`
+			}{}
+			if err := json.Unmarshal(evts[i], typed); err != nil {
+				return nil, err
+			}
+
+			var err error
+			if evts[i], err = transformProperties(evts[i], piiEventProperties[typed.Type], fn); err != nil {
+				return nil, err
+			}
+		}
+
+		run["events"] = jsonx.MustMarshal(evts)
+	}
+
+	if run["params"] != nil {
+		var err error
+		if run["params"], err = fn(run["params"]); err != nil {
+			return nil, err
+		}
+	}
+
+	if run["results"] != nil {
+		var results map[string]json.RawMessage
+		if err := json.Unmarshal(run["results"], &results); err != nil {
+			return nil, err
+		}
+
+		for key := range results {
+			var err error
+			if results[key], err = transformProperties(results[key], piiResultProperties, fn); err != nil {
+				return nil, err
+			}
+		}
+
+		run["results"] = jsonx.MustMarshal(results)
+	}
+
+	return jsonx.Marshal(run)
+}
+
+// transforms the values of the given properties of a JSON object
+func transformProperties(data json.RawMessage, props []string, fn valueTransform) (json.RawMessage, error) {
+	if len(data) == 0 || len(props) == 0 {
+		return data, nil
+	}
+
+	obj := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, err
+	}
+
+	changed := false
+	for _, prop := range props {
+		if v, exists := obj[prop]; exists && string(v) != "null" {
+			transformed, err := fn(v)
+			if err != nil {
+				return nil, err
+			}
+			obj[prop] = transformed
+			changed = true
+		}
+	}
+
+	if !changed {
+		return data, nil
+	}
+	return jsonx.Marshal(obj)
+}
@@ -0,0 +1,206 @@
+package engine_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/nyaruka/gocommon/httpx"
+	"github.com/nyaruka/gocommon/jsonx"
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/envs"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/engine"
+	"github.com/nyaruka/goflow/flows/triggers"
+	"github.com/nyaruka/goflow/test"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionEncryption(t *testing.T) {
+	session, _, err := test.CreateTestSession("", envs.RedactionPolicyNone)
+	require.NoError(t, err)
+
+	plainJSON, err := jsonx.Marshal(session)
+	require.NoError(t, err)
+
+	pii := []string{
+		"+12024561111", // contact URN
+		"+12065551212", // trigger and input URN
+		"Ryan Lewis",   // contact name
+		"AACC55",       // contact field value
+		"Hi there",     // trigger and input text
+		"a reporter",   // result input
+		"problem",      // ticket body
+	}
+	for _, s := range pii {
+		assert.Contains(t, string(plainJSON), s)
+	}
+
+	kp := flows.NewStaticKeyProvider("k1", map[string][]byte{"k1": []byte("0123456789abcdef0123456789abcdef")})
+	eng := engine.NewBuilder().WithKeyProvider(kp).Build()
+
+	// an engine with a key provider can still read plaintext sessions
+	session, err = eng.ReadSession(session.Assets(), plainJSON, assets.PanicOnMissing)
+	require.NoError(t, err)
+
+	encryptedJSON, err := jsonx.Marshal(session)
+	require.NoError(t, err)
+
+	for _, s := range pii {
+		assert.NotContains(t, string(encryptedJSON), s)
+	}
+	assert.Contains(t, string(encryptedJSON), string(session.UUID()))
+
+	// reading an encrypted session decrypts it
+	decrypted, err := eng.ReadSession(session.Assets(), encryptedJSON, assets.PanicOnMissing)
+	require.NoError(t, err)
+
+	assert.True(t, session.Contact().Equal(decrypted.Contact()))
+	test.AssertEqualJSON(t, jsonx.MustMarshal(session.Trigger()), jsonx.MustMarshal(decrypted.Trigger()), "trigger mismatch")
+	test.AssertEqualJSON(t, jsonx.MustMarshal(session.Input()), jsonx.MustMarshal(decrypted.Input()), "input mismatch")
+	require.Equal(t, len(session.Runs()), len(decrypted.Runs()))
+	for i := range session.Runs() {
+		test.AssertEqualJSON(t, jsonx.MustMarshal(session.Runs()[i]), jsonx.MustMarshal(decrypted.Runs()[i]), "run %d mismatch", i)
+	}
+
+	// and re-marshaling it encrypts it again
+	reencryptedJSON, err := jsonx.Marshal(decrypted)
+	require.NoError(t, err)
+	for _, s := range pii {
+		assert.NotContains(t, string(reencryptedJSON), s)
+	}
+
+	// encrypted sessions can't be read without a key provider
+	plainEng := engine.NewBuilder().Build()
+	_, err = plainEng.ReadSession(session.Assets(), encryptedJSON, assets.PanicOnMissing)
+	assert.Error(t, err)
+}
+
+func TestSessionEncryptionOfServiceEvents(t *testing.T) {
+	defer httpx.SetRequestor(httpx.DefaultRequestor)
+
+	httpx.SetRequestor(httpx.NewMockRequestor(map[string][]httpx.MockResponse{
+		"http://localhost/?cmd=success&name=Ryan%20Lewis": {
+			httpx.NewMockResponse(200, nil, `{"name": "Ryan Lewis", "urn": "tel:+12024561111"}`),
+		},
+	}))
+
+	assetsJSON, err := os.ReadFile("testdata/encryption.json")
+	require.NoError(t, err)
+
+	sa, err := test.CreateSessionAssets(assetsJSON, "")
+	require.NoError(t, err)
+
+	contact, err := flows.ReadContact(sa, []byte(`{
+		"uuid": "ba96bf7f-bc2a-4873-a7c7-254d1927c4e3",
+		"name": "Ryan Lewis",
+		"urns": ["tel:+12024561111"],
+		"created_on": "2018-06-20T11:40:30.123456789Z"
+	}`), assets.PanicOnMissing)
+	require.NoError(t, err)
+
+	flow, err := sa.Flows().Get("4a83a0ba-4e0f-4a21-b4ff-6c7e9e8a6a5e")
+	require.NoError(t, err)
+
+	trigger := triggers.NewBuilder(envs.NewBuilder().Build(), flow.Reference(), contact).Manual().Build()
+	session, _, err := test.NewEngine().NewSession(sa, trigger)
+	require.NoError(t, err)
+
+	plainJSON, err := jsonx.Marshal(session)
+	require.NoError(t, err)
+
+	eventTypes := make([]string, 0)
+	for _, e := range session.Runs()[0].Events() {
+		eventTypes = append(eventTypes, e.Type())
+	}
+	assert.Subset(t, eventTypes, []string{"contact_fields_changed", "email_sent", "ticket_opened", "airtime_transferred", "personalized_broadcast_created", "webhook_called"})
+
+	// check that no URN or name is left in plaintext in any of the events or results from calling services, including
+	// the other contacts looked up or created whose properties are saved as extra on results
+	pii := []string{"2024561111", "Ryan Lewis", "RYAN LEWIS", "Ryan%20Lewis", "ryan.lewis", "2065552020", "Bob Referrer", "2065554040", "Cathy Quincy", "2065553030", "Maria Amiga", "2065556060"}
+	for _, s := range pii {
+		assert.Contains(t, string(plainJSON), s)
+	}
+
+	kp := flows.NewStaticKeyProvider("k1", map[string][]byte{"k1": []byte("0123456789abcdef0123456789abcdef")})
+	eng := engine.NewBuilder().WithKeyProvider(kp).Build()
+
+	session, err = eng.ReadSession(sa, plainJSON, assets.PanicOnMissing)
+	require.NoError(t, err)
+
+	encryptedJSON, err := jsonx.Marshal(session)
+	require.NoError(t, err)
+
+	for _, s := range pii {
+		assert.NotContains(t, string(encryptedJSON), s)
+	}
+
+	decrypted, err := eng.ReadSession(sa, encryptedJSON, assets.PanicOnMissing)
+	require.NoError(t, err)
+	test.AssertEqualJSON(t, jsonx.MustMarshal(session.Runs()[0]), jsonx.MustMarshal(decrypted.Runs()[0]), "run mismatch")
+}
+
+func TestSessionEncryptionOfDialWait(t *testing.T) {
+	assetsJSON, err := os.ReadFile("testdata/encryption.json")
+	require.NoError(t, err)
+
+	sa, err := test.CreateSessionAssets(assetsJSON, "")
+	require.NoError(t, err)
+
+	trigger, err := triggers.ReadTrigger(sa, []byte(`{
+		"type": "manual",
+		"environment": {},
+		"flow": {"uuid": "7d2b0c3e-8f1a-4e5b-9c6d-2a3b4c5d6e7f", "name": "Dial"},
+		"contact": {
+			"uuid": "ba96bf7f-bc2a-4873-a7c7-254d1927c4e3",
+			"name": "Ryan Lewis",
+			"urns": ["tel:+12024561111"],
+			"created_on": "2018-06-20T11:40:30.123456789Z"
+		},
+		"connection": {
+			"channel": {"uuid": "a78930fe-6a40-4aa8-99c3-e61b02f45ca1", "name": "Twilio"},
+			"urn": "tel:+12024561111"
+		},
+		"triggered_on": "2018-06-20T11:40:30.123456789Z"
+	}`), assets.PanicOnMissing)
+	require.NoError(t, err)
+
+	session, _, err := test.NewEngine().NewSession(sa, trigger)
+	require.NoError(t, err)
+	require.Equal(t, flows.SessionStatusWaiting, session.Status())
+
+	plainJSON, err := jsonx.Marshal(session)
+	require.NoError(t, err)
+
+	eventTypes := make([]string, 0)
+	for _, e := range session.Runs()[0].Events() {
+		eventTypes = append(eventTypes, e.Type())
+	}
+	assert.Equal(t, []string{"error", "dial_wait"}, eventTypes)
+
+	// check that neither the number being dialed nor contact data in error messages is left in plaintext
+	pii := []string{"2024561111", "2065559090", "Ryan Lewis"}
+	for _, s := range pii {
+		assert.Contains(t, string(plainJSON), s)
+	}
+
+	kp := flows.NewStaticKeyProvider("k1", map[string][]byte{"k1": []byte("0123456789abcdef0123456789abcdef")})
+	eng := engine.NewBuilder().WithKeyProvider(kp).Build()
+
+	session, err = eng.ReadSession(sa, plainJSON, assets.PanicOnMissing)
+	require.NoError(t, err)
+
+	encryptedJSON, err := jsonx.Marshal(session)
+	require.NoError(t, err)
+
+	for _, s := range pii {
+		assert.NotContains(t, string(encryptedJSON), s)
+	}
+
+	decrypted, err := eng.ReadSession(sa, encryptedJSON, assets.PanicOnMissing)
+	require.NoError(t, err)
+	test.AssertEqualJSON(t, jsonx.MustMarshal(session.Runs()[0]), jsonx.MustMarshal(decrypted.Runs()[0]), "run mismatch")
+	assert.Equal(t, session.Wait(), decrypted.Wait())
+	assert.Equal(t, session.Trigger().Connection(), decrypted.Trigger().Connection())
+}
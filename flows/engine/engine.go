@@ -1,7 +1,9 @@
 package engine
 
 import (
+	"context"
 	"encoding/json"
+	"time"
 
 	"github.com/nyaruka/gocommon/uuids"
 	"github.com/nyaruka/goflow/assets"
@@ -11,12 +13,24 @@ import (
 // an instance of the engine
 type engine struct {
 	services          *services
+	middleware        middlewares
+	tracer            flows.Tracer
+	metrics           flows.Metrics
+	keyProvider       flows.KeyProvider
 	maxStepsPerSprint int
+	maxSprintDuration time.Duration
+	pauseOnStepLimit  bool
+	maxPausedSteps    int
 	maxTemplateChars  int
 }
 
 // NewSession creates a new session
 func (e *engine) NewSession(sa flows.SessionAssets, trigger flows.Trigger) (flows.Session, flows.Sprint, error) {
+	return e.NewSessionWithContext(context.Background(), sa, trigger)
+}
+
+// NewSessionWithContext creates a new session, passing the given context to any actions which call services
+func (e *engine) NewSessionWithContext(ctx context.Context, sa flows.SessionAssets, trigger flows.Trigger) (flows.Session, flows.Sprint, error) {
 	s := &session{
 		uuid:       flows.SessionUUID(uuids.New()),
 		engine:     e,
@@ -27,19 +41,26 @@ func (e *engine) NewSession(sa flows.SessionAssets, trigger flows.Trigger) (flow
 		runsByUUID: make(map[flows.RunUUID]flows.FlowRun),
 	}
 
-	sprint, err := s.start(trigger)
+	sprint, err := s.start(ctx, trigger)
 
 	return s, sprint, err
 }
 
-// ReadSession reads an existing session
+// ReadSession reads an existing session from either JSON or the compact binary format
 func (e *engine) ReadSession(sa flows.SessionAssets, data json.RawMessage, missing assets.MissingCallback) (flows.Session, error) {
 	return readSession(e, sa, data, missing)
 }
 
-func (e *engine) Services() flows.Services { return e.services }
-func (e *engine) MaxStepsPerSprint() int   { return e.maxStepsPerSprint }
-func (e *engine) MaxTemplateChars() int    { return e.maxTemplateChars }
+func (e *engine) Services() flows.Services         { return e.services }
+func (e *engine) Middleware() flows.Middleware     { return e.middleware }
+func (e *engine) Tracer() flows.Tracer             { return e.tracer }
+func (e *engine) Metrics() flows.Metrics           { return e.metrics }
+func (e *engine) KeyProvider() flows.KeyProvider   { return e.keyProvider }
+func (e *engine) MaxStepsPerSprint() int           { return e.maxStepsPerSprint }
+func (e *engine) MaxSprintDuration() time.Duration { return e.maxSprintDuration }
+func (e *engine) PauseOnStepLimit() bool           { return e.pauseOnStepLimit }
+func (e *engine) MaxPausedSteps() int              { return e.maxPausedSteps }
+func (e *engine) MaxTemplateChars() int            { return e.maxTemplateChars }
 
 var _ flows.Engine = (*engine)(nil)
 
@@ -57,7 +78,10 @@ func NewBuilder() *Builder {
 	return &Builder{
 		eng: &engine{
 			services:          newEmptyServices(),
+			tracer:            noopTracer{},
+			metrics:           noopMetrics{},
 			maxStepsPerSprint: 100,
+			maxPausedSteps:    1000,
 			maxTemplateChars:  10000,
 		},
 	}
@@ -93,12 +117,63 @@ func (b *Builder) WithAirtimeServiceFactory(f AirtimeServiceFactory) *Builder {
 	return b
 }
 
+// WithContactServiceFactory sets the contact service factory
+func (b *Builder) WithContactServiceFactory(f ContactServiceFactory) *Builder {
+	b.eng.services.contact = f
+	return b
+}
+
+// WithMiddleware adds middleware which will be invoked in the order added for every event and modifier
+func (b *Builder) WithMiddleware(m ...flows.Middleware) *Builder {
+	b.eng.middleware = append(b.eng.middleware, m...)
+	return b
+}
+
+// WithTracer sets the tracer used to record spans of flow execution
+func (b *Builder) WithTracer(t flows.Tracer) *Builder {
+	b.eng.tracer = t
+	return b
+}
+
+// WithMetrics sets the sink for metrics reported during flow execution
+func (b *Builder) WithMetrics(m flows.Metrics) *Builder {
+	b.eng.metrics = m
+	return b
+}
+
+// WithKeyProvider sets the key provider used to encrypt contact PII and message content when sessions are marshaled,
+// and to decrypt it when they are read
+func (b *Builder) WithKeyProvider(kp flows.KeyProvider) *Builder {
+	b.eng.keyProvider = kp
+	return b
+}
+
 // WithMaxStepsPerSprint sets the maximum number of steps allowed in a single sprint
 func (b *Builder) WithMaxStepsPerSprint(max int) *Builder {
 	b.eng.maxStepsPerSprint = max
 	return b
 }
 
+// WithMaxSprintDuration sets the maximum time a single sprint can take before the session is paused, zero meaning no limit
+func (b *Builder) WithMaxSprintDuration(max time.Duration) *Builder {
+	b.eng.maxSprintDuration = max
+	return b
+}
+
+// WithPauseOnStepLimit sets whether sessions are paused rather than failed when they hit the step limit
+func (b *Builder) WithPauseOnStepLimit(pause bool) *Builder {
+	b.eng.pauseOnStepLimit = pause
+	return b
+}
+
+// WithMaxPausedSteps sets the maximum number of steps a session can take without waiting, across any sprints which
+// paused and continued it, before it fails as though it had hit the step limit. It only applies to sessions which can
+// pause, i.e. if pausing on the step limit is enabled or there is a maximum sprint duration.
+func (b *Builder) WithMaxPausedSteps(max int) *Builder {
+	b.eng.maxPausedSteps = max
+	return b
+}
+
 // WithMaxTemplateChars sets the maximum number of characters allowed from an evaluated template
 func (b *Builder) WithMaxTemplateChars(max int) *Builder {
 	b.eng.maxTemplateChars = max
@@ -16,6 +16,7 @@ func TestBuilder(t *testing.T) {
 	eng := engine.NewBuilder().WithMaxStepsPerSprint(123).Build()
 
 	assert.Equal(t, 123, eng.MaxStepsPerSprint())
+	assert.Equal(t, 1000, eng.MaxPausedSteps())
 
 	_, err := eng.Services().Email(nil)
 	assert.EqualError(t, err, "no email service factory configured")
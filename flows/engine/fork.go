@@ -0,0 +1,207 @@
+package engine
+
+import (
+	"context"
+	"sync"
+
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/routers"
+	"github.com/nyaruka/goflow/flows/runs"
+
+	"github.com/pkg/errors"
+)
+
+// Branches of a fork each execute in their own goroutine, but only one has a turn at any time, so they never touch
+// session state concurrently. When a branch needs to block waiting on a service, it hands back its turn along with
+// the blocking function, and once every branch has either finished or done the same, those functions are invoked
+// concurrently while no branch has a turn. Turns are always given out in branch order so that execution is
+// deterministic for a given set of service responses.
+type branch struct {
+	turn  chan struct{}
+	yield chan func() // sent the function the branch is blocked on, or nil when the branch has finished
+	steps []flows.Step
+
+	join     flows.NodeUUID
+	err      error
+	panicked interface{}
+}
+
+func newBranch() *branch {
+	return &branch{turn: make(chan struct{}), yield: make(chan func())}
+}
+
+func (b *branch) start(ctx context.Context, walk func(context.Context) (flows.NodeUUID, error)) {
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				b.panicked = r
+			}
+			b.yield <- nil
+		}()
+
+		<-b.turn
+		b.join, b.err = walk(flows.WithBlocking(ctx, b.blocking))
+	}()
+}
+
+// hands back our turn along with the given function, and then waits for it to be invoked and to be given another turn
+func (b *branch) blocking(fn func()) {
+	var panicked interface{}
+
+	b.yield <- func() {
+		defer func() { panicked = recover() }()
+		fn()
+	}
+	<-b.turn
+
+	if panicked != nil {
+		panic(panicked)
+	}
+}
+
+// gives each unfinished branch a turn in order and then invokes the functions they're blocked on, until they have all
+// finished. The steps added to the path of the given run during each turn are recorded against that branch.
+func runBranches(run flows.FlowRun, branches []*branch) {
+	active := branches
+	for len(active) > 0 {
+		blocked := make([]*branch, 0, len(active))
+		calls := make([]func(), 0, len(active))
+
+		for _, b := range active {
+			numSteps := len(run.Path())
+
+			b.turn <- struct{}{}
+			call := <-b.yield
+
+			b.steps = append(b.steps, run.Path()[numSteps:]...)
+
+			if call != nil {
+				blocked = append(blocked, b)
+				calls = append(calls, call)
+			}
+		}
+
+		wg := &sync.WaitGroup{}
+		for _, call := range calls {
+			wg.Add(1)
+			go func(call func()) {
+				defer wg.Done()
+				call()
+			}(call)
+		}
+		wg.Wait()
+
+		active = blocked
+	}
+
+	for _, b := range branches {
+		if b.panicked != nil {
+			panic(b.panicked)
+		}
+	}
+}
+
+// forks execution of the given run into a branch for each exit of the given fork router, and returns the join node
+// where those branches meet again
+func (s *session) fork(ctx context.Context, sprint flows.Sprint, run flows.FlowRun, node flows.Node, step flows.Step, router *routers.ForkRouter) (flows.NodeUUID, error) {
+	exits := router.Branches()
+	numSteps := len(run.Path())
+	branches := make([]*branch, len(exits))
+
+	for i, exitUUID := range exits {
+		destination := noDestination
+		for _, exit := range node.Exits() {
+			if exit.UUID() == exitUUID {
+				destination = exit.DestinationUUID()
+			}
+		}
+
+		branches[i] = newBranch()
+		branches[i].start(ctx, func(ctx context.Context) (flows.NodeUUID, error) {
+			return s.walkBranch(ctx, sprint, run, step, destination)
+		})
+	}
+
+	runBranches(run, branches)
+
+	// the path of a run is linear so rather than leaving the steps of branches in the order they were taken, each
+	// branch is recorded in turn, starting with a step on the fork node which leaves by the exit of that branch
+	path := append([]flows.Step{}, run.Path()[:numSteps]...)
+	for i, b := range branches {
+		branchStep := step
+		if i > 0 {
+			branchStep = runs.NewStep(node, step.ArrivedOn())
+			path = append(path, branchStep)
+		}
+		branchStep.Leave(exits[i])
+		path = append(path, b.steps...)
+	}
+	run.SetPath(path)
+
+	join := noDestination
+
+	for _, b := range branches {
+		if b.err != nil {
+			return noDestination, b.err
+		}
+		if b.join != noDestination {
+			if join != noDestination && b.join != join {
+				s.failure(sprint, run, step, errors.Errorf("branches of fork on node[uuid=%s] reach different join nodes", node.UUID()))
+				return noDestination, nil
+			}
+			join = b.join
+		}
+	}
+
+	// one of the branches may have failed the run
+	if run.Status() == flows.RunStatusFailed {
+		return noDestination, nil
+	}
+
+	return join, nil
+}
+
+// visits nodes from the given destination until we reach a join node, which is returned. A branch which ends without
+// reaching a join node returns no destination.
+func (s *session) walkBranch(ctx context.Context, sprint flows.Sprint, run flows.FlowRun, step flows.Step, destination flows.NodeUUID) (flows.NodeUUID, error) {
+	var err error
+	afterFork := false
+
+	for destination != noDestination && run.Status() != flows.RunStatusFailed {
+		node := run.Flow().GetNode(destination)
+		if node == nil {
+			return noDestination, errors.Errorf("unable to find destination node %s in flow %s", destination, run.Flow().UUID())
+		}
+
+		// the join of a nested fork is visited like any other node
+		if _, isJoin := node.Router().(*routers.JoinRouter); isJoin && !afterFork {
+			return destination, nil
+		}
+
+		if node.Router() != nil && node.Router().Wait() != nil {
+			s.failure(sprint, run, step, errors.Errorf("branch can't enter node[uuid=%s] which has a wait", node.UUID()))
+			return noDestination, nil
+		}
+
+		// steps in branches count towards the limit for the sprint like any other
+		s.numSteps++
+		if s.numSteps > s.Engine().MaxStepsPerSprint() {
+			s.failure(sprint, run, step, errors.Errorf("step limit exceeded, stopping execution of branch before entering '%s'", destination))
+			return noDestination, nil
+		}
+
+		_, afterFork = node.Router().(*routers.ForkRouter)
+
+		if step, destination, err = s.visitNode(ctx, sprint, run, node, nil); err != nil {
+			return noDestination, err
+		}
+
+		if s.pushedFlow != nil {
+			s.pushedFlow = nil
+			s.failure(sprint, run, step, errors.New("branch can't enter another flow"))
+			return noDestination, nil
+		}
+	}
+
+	return noDestination, nil
+}
@@ -0,0 +1,189 @@
+package engine_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/envs"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/engine"
+	"github.com/nyaruka/goflow/flows/events"
+	"github.com/nyaruka/goflow/flows/routers"
+	"github.com/nyaruka/goflow/flows/triggers"
+	"github.com/nyaruka/goflow/services/webhooks"
+	"github.com/nyaruka/goflow/test"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForkAndJoin(t *testing.T) {
+	// a server where the slow endpoint can only respond once the fast one has been called, which means the calls must
+	// be made in parallel, and gives up if that doesn't happen
+	fastCalled := make(chan struct{}, 1)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("cmd") == "slow" {
+			select {
+			case <-fastCalled:
+			case <-time.After(5 * time.Second):
+				w.WriteHeader(http.StatusGatewayTimeout)
+				return
+			}
+		} else {
+			fastCalled <- struct{}{}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"ok": true}`))
+	}))
+	defer server.Close()
+
+	assetsJSON, err := os.ReadFile("testdata/fork.json")
+	require.NoError(t, err)
+
+	sa, err := test.CreateSessionAssets(assetsJSON, server.URL)
+	require.NoError(t, err)
+
+	// no retries as their random backoffs aren't safe for concurrent use
+	eng := engine.NewBuilder().WithWebhookServiceFactory(webhooks.NewServiceFactory(http.DefaultClient, nil, nil, nil, 10000)).Build()
+
+	startFlow := func(flowUUID assets.FlowUUID) (flows.Session, flows.Sprint) {
+		flow, err := sa.Flows().Get(flowUUID)
+		require.NoError(t, err)
+
+		contact := flows.NewEmptyContact(sa, "Bob", envs.NilLanguage, nil)
+		trigger := triggers.NewBuilder(envs.NewBuilder().Build(), flow.Reference(), contact).Manual().Build()
+
+		session, sprint, err := eng.NewSession(sa, trigger)
+		require.NoError(t, err)
+		return session, sprint
+	}
+
+	eventTypes := func(sprint flows.Sprint) []string {
+		types := make([]string, len(sprint.Events()))
+		for i, e := range sprint.Events() {
+			types[i] = e.Type()
+		}
+		return types
+	}
+
+	// webhooks in different branches are called in parallel
+	session, sprint := startFlow("eb3d7873-04c3-405b-965c-982bd7a7bf5e")
+
+	assert.Equal(t, flows.SessionStatusCompleted, session.Status())
+
+	// but events are logged in branch order regardless of which call finished first
+	assert.Equal(t, []string{"webhook_called", "run_result_changed", "webhook_called", "run_result_changed", "run_result_changed", "msg_created"}, eventTypes(sprint))
+	assert.Contains(t, sprint.Events()[0].(*events.WebhookCalledEvent).URL, "cmd=slow")
+	assert.Equal(t, 200, sprint.Events()[0].(*events.WebhookCalledEvent).StatusCode, "webhook calls weren't made in parallel")
+	assert.Contains(t, sprint.Events()[2].(*events.WebhookCalledEvent).URL, "cmd=fast")
+	assert.Equal(t, "Success and Success", sprint.Events()[5].(*events.MsgCreatedEvent).Msg.Text())
+
+	// and the path records each branch in turn, starting with a step on the fork node
+	run := session.Runs()[0]
+	assert.Equal(t, []flows.NodeUUID{
+		"87751d4c-a850-4e2c-84dc-da6a797d76de", // fork, leaving by branch 1
+		"61b339ff-2481-44e5-998b-88dbaa99e079", // slow webhook
+		"87751d4c-a850-4e2c-84dc-da6a797d76de", // fork, leaving by branch 2
+		"7b87a9e2-5fef-4911-bf22-a27b02c7bff2", // fast webhook
+		"75d0dd66-cf72-4858-a4b6-6f8c462804db", // join
+		"3a46e6b0-99f9-46b1-9d45-af1cb0caae1c", // send message
+	}, pathNodes(run))
+	assertPathWalkable(t, run)
+	assert.Equal(t, []string{"fast", "lookups", "slow"}, resultKeys(run))
+
+	// forks can be nested inside the branches of other forks
+	session, sprint = startFlow("5e4945ed-7592-4260-8396-ae9b0d53ba11")
+
+	assert.Equal(t, flows.SessionStatusCompleted, session.Status())
+	assert.Equal(t, "1 2 3", sprint.Events()[len(sprint.Events())-1].(*events.MsgCreatedEvent).Msg.Text())
+	assert.Equal(t, 10, len(session.Runs()[0].Path()))
+	assertPathWalkable(t, session.Runs()[0])
+
+	// branches can't contain waits
+	session, sprint = startFlow("dcb6be69-f1a3-4b9f-947a-f48d473effcf")
+
+	assert.Equal(t, flows.SessionStatusFailed, session.Status())
+	assert.Equal(t, []string{"run_result_changed", "failure"}, eventTypes(sprint))
+	assert.Regexp(t, "branch can't enter node\\[uuid=.+\\] which has a wait", sprint.Events()[1].(*events.FailureEvent).Text)
+
+	// or end at different join nodes
+	session, sprint = startFlow("6a5c6b21-e917-4332-92af-8f73e5c106a2")
+
+	assert.Equal(t, flows.SessionStatusFailed, session.Status())
+	assert.Equal(t, []string{"failure"}, eventTypes(sprint))
+	assert.Regexp(t, "branches of fork on node\\[uuid=.+\\] reach different join nodes", sprint.Events()[0].(*events.FailureEvent).Text)
+}
+
+func TestForkStepLimit(t *testing.T) {
+	assetsJSON, err := os.ReadFile("testdata/fork.json")
+	require.NoError(t, err)
+
+	sa, err := test.CreateSessionAssets(assetsJSON, "")
+	require.NoError(t, err)
+
+	flow, err := sa.Flows().Get("5e4945ed-7592-4260-8396-ae9b0d53ba11")
+	require.NoError(t, err)
+
+	contact := flows.NewEmptyContact(sa, "Bob", envs.NilLanguage, nil)
+	trigger := triggers.NewBuilder(envs.NewBuilder().Build(), flow.Reference(), contact).Manual().Build()
+
+	// steps taken in branches count towards the step limit of the sprint
+	eng := engine.NewBuilder().WithMaxStepsPerSprint(6).Build()
+
+	session, sprint, err := eng.NewSession(sa, trigger)
+	require.NoError(t, err)
+
+	assert.Equal(t, flows.SessionStatusFailed, session.Status())
+	lastEvent := sprint.Events()[len(sprint.Events())-1]
+	assert.Equal(t, "failure", lastEvent.Type())
+	assert.Contains(t, lastEvent.(*events.FailureEvent).Text, "step limit exceeded")
+}
+
+func pathNodes(run flows.FlowRun) []flows.NodeUUID {
+	nodes := make([]flows.NodeUUID, len(run.Path()))
+	for i, step := range run.Path() {
+		nodes[i] = step.NodeUUID()
+	}
+	return nodes
+}
+
+// asserts that each step of the given run's path leaves by an exit to the node of the next step, unless the next step
+// is a repeated step on a fork node and so starts another branch of that fork
+func assertPathWalkable(t *testing.T, run flows.FlowRun) {
+	path := run.Path()
+	visited := map[flows.NodeUUID]bool{path[0].NodeUUID(): true}
+
+	for i := 0; i < len(path)-1; i++ {
+		next := run.Flow().GetNode(path[i+1].NodeUUID())
+		_, isFork := next.Router().(*routers.ForkRouter)
+		isBranchStart := isFork && visited[next.UUID()]
+		visited[next.UUID()] = true
+
+		if isBranchStart {
+			continue
+		}
+
+		var destination flows.NodeUUID
+		for _, exit := range run.Flow().GetNode(path[i].NodeUUID()).Exits() {
+			if exit.UUID() == path[i].ExitUUID() {
+				destination = exit.DestinationUUID()
+			}
+		}
+		assert.Equal(t, next.UUID(), destination, "step %d doesn't lead to step %d", i, i+1)
+	}
+}
+
+func resultKeys(run flows.FlowRun) []string {
+	keys := make([]string, 0, len(run.Results()))
+	for key := range run.Results() {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
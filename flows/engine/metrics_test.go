@@ -0,0 +1,124 @@
+package engine_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/nyaruka/gocommon/urns"
+	"github.com/nyaruka/gocommon/uuids"
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/envs"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/engine"
+	"github.com/nyaruka/goflow/flows/resumes"
+	"github.com/nyaruka/goflow/flows/triggers"
+	"github.com/nyaruka/goflow/services/webhooks"
+	"github.com/nyaruka/goflow/test"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrometheusMetrics(t *testing.T) {
+	metrics := engine.NewPrometheusMetrics().WithBuckets("latency", 1, 0.5)
+
+	metrics.IncCounter("requests_total", map[string]string{"method": "GET"})
+	metrics.IncCounter("requests_total", map[string]string{"method": "GET"})
+	metrics.IncCounter("requests_total", map[string]string{"method": `P"O\ST`})
+	metrics.IncCounter("errors_total", nil)
+	metrics.ObserveHistogram("latency", 0.25, map[string]string{"service": "webhook"})
+	metrics.ObserveHistogram("latency", 0.75, map[string]string{"service": "webhook"})
+	metrics.ObserveHistogram("latency", 3, map[string]string{"service": "webhook"})
+	metrics.ObserveHistogram("size", 0.02, nil)
+
+	b := &bytes.Buffer{}
+	_, err := metrics.WriteTo(b)
+	require.NoError(t, err)
+
+	assert.Equal(t, `# TYPE errors_total counter
+errors_total 1
+# TYPE requests_total counter
+requests_total{method="GET"} 2
+requests_total{method="P\"O\\ST"} 1
+# TYPE latency histogram
+latency_bucket{service="webhook",le="0.5"} 1
+latency_bucket{service="webhook",le="1"} 2
+latency_bucket{service="webhook",le="+Inf"} 3
+latency_sum{service="webhook"} 4
+latency_count{service="webhook"} 3
+# TYPE size histogram
+size_bucket{le="0.005"} 0
+size_bucket{le="0.01"} 0
+size_bucket{le="0.025"} 1
+size_bucket{le="0.05"} 1
+size_bucket{le="0.1"} 1
+size_bucket{le="0.25"} 1
+size_bucket{le="0.5"} 1
+size_bucket{le="1"} 1
+size_bucket{le="2.5"} 1
+size_bucket{le="5"} 1
+size_bucket{le="10"} 1
+size_bucket{le="+Inf"} 1
+size_sum 0.02
+size_count 1
+`, b.String())
+}
+
+func TestEngineMetrics(t *testing.T) {
+	server := test.NewTestHTTPServer(49996)
+	defer server.Close()
+
+	assetsJSON, err := os.ReadFile("../../test/testdata/runner/two_questions.json")
+	require.NoError(t, err)
+
+	sa, err := test.CreateSessionAssets(assetsJSON, server.URL)
+	require.NoError(t, err)
+
+	flow, err := sa.Flows().Get(assets.FlowUUID("615b8a0f-588c-4d20-a05f-363b0b4ce6f4"))
+	require.NoError(t, err)
+
+	metrics := engine.NewPrometheusMetrics()
+	eng := engine.NewBuilder().
+		WithWebhookServiceFactory(webhooks.NewServiceFactory(http.DefaultClient, nil, nil, nil, 10000)).
+		WithMetrics(metrics).
+		Build()
+
+	env := envs.NewBuilder().Build()
+	contact := flows.NewEmptyContact(sa, "Bob", envs.NilLanguage, nil)
+	trigger := triggers.NewBuilder(env, flow.Reference(), contact).Manual().Build()
+
+	session, _, err := eng.NewSession(sa, trigger)
+	require.NoError(t, err)
+
+	for _, text := range []string{"red", "coke"} {
+		msg := flows.NewMsgIn(flows.MsgUUID(uuids.New()), urns.NilURN, nil, text, nil)
+		_, err := session.ResumeWithContext(context.Background(), resumes.NewMsg(env, session.Contact(), msg))
+		require.NoError(t, err)
+	}
+
+	b := &bytes.Buffer{}
+	_, err = metrics.WriteTo(b)
+	require.NoError(t, err)
+
+	output := b.String()
+
+	for _, line := range []string{
+		`goflow_sessions_started_total 1`,
+		`goflow_sessions_resumed_total 2`,
+		`goflow_session_statuses_total{status="completed"} 1`,
+		`goflow_session_statuses_total{status="waiting"} 2`,
+		`goflow_events_total{type="msg_created"} 3`,
+		`goflow_events_total{type="webhook_called"} 1`,
+		`goflow_template_errors_total{flow_uuid="615b8a0f-588c-4d20-a05f-363b0b4ce6f4"} 2`,
+		`goflow_sprint_steps_bucket{le="1"} 3`,
+		`goflow_sprint_steps_sum 3`,
+		`goflow_sprint_steps_count 3`,
+		`goflow_service_call_duration_seconds_count{service="webhook"} 1`,
+	} {
+		assert.Contains(t, strings.Split(output, "\n"), line)
+	}
+}
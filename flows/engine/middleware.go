@@ -0,0 +1,36 @@
+package engine
+
+import (
+	"github.com/nyaruka/goflow/flows"
+)
+
+// a chain of middleware which is invoked in order, with the output of each middleware being the input to the next
+type middlewares []flows.Middleware
+
+func (m middlewares) Event(session flows.Session, event flows.Event) []flows.Event {
+	evts := []flows.Event{event}
+
+	for _, mw := range m {
+		next := make([]flows.Event, 0, len(evts))
+		for _, e := range evts {
+			next = append(next, mw.Event(session, e)...)
+		}
+		evts = next
+	}
+	return evts
+}
+
+func (m middlewares) Modifier(session flows.Session, modifier flows.Modifier) []flows.Modifier {
+	mods := []flows.Modifier{modifier}
+
+	for _, mw := range m {
+		next := make([]flows.Modifier, 0, len(mods))
+		for _, md := range mods {
+			next = append(next, mw.Modifier(session, md)...)
+		}
+		mods = next
+	}
+	return mods
+}
+
+var _ flows.Middleware = (middlewares)(nil)
@@ -0,0 +1,103 @@
+package engine_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/nyaruka/gocommon/urns"
+	"github.com/nyaruka/goflow/envs"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/engine"
+	"github.com/nyaruka/goflow/flows/events"
+	"github.com/nyaruka/goflow/flows/modifiers"
+	"github.com/nyaruka/goflow/flows/resumes"
+	"github.com/nyaruka/goflow/flows/triggers"
+	"github.com/nyaruka/goflow/test"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// middleware which enforces a quota on messages, and vetoes language changes
+type testMiddleware struct {
+	msgQuota int
+	seen     []string
+}
+
+func (m *testMiddleware) Event(s flows.Session, e flows.Event) []flows.Event {
+	m.seen = append(m.seen, e.Type())
+
+	if e.Type() == events.TypeMsgCreated {
+		if m.msgQuota == 0 {
+			return []flows.Event{events.NewErrorf("message quota exceeded")}
+		}
+		m.msgQuota--
+	}
+	return []flows.Event{e}
+}
+
+func (m *testMiddleware) Modifier(s flows.Session, mod flows.Modifier) []flows.Modifier {
+	if mod.Type() == modifiers.TypeLanguage {
+		return nil
+	}
+	return []flows.Modifier{mod}
+}
+
+// middleware which adds an extra event after every error
+type enrichMiddleware struct{}
+
+func (m *enrichMiddleware) Event(s flows.Session, e flows.Event) []flows.Event {
+	if e.Type() == events.TypeError {
+		return []flows.Event{e, events.NewErrorf("an error occurred")}
+	}
+	return []flows.Event{e}
+}
+
+func (m *enrichMiddleware) Modifier(s flows.Session, mod flows.Modifier) []flows.Modifier {
+	return []flows.Modifier{mod}
+}
+
+func TestMiddleware(t *testing.T) {
+	assetsJSON, err := os.ReadFile("../../test/testdata/runner/two_questions.json")
+	require.NoError(t, err)
+
+	sa, err := test.CreateSessionAssets(assetsJSON, "")
+	require.NoError(t, err)
+
+	flow, err := sa.Flows().Get("615b8a0f-588c-4d20-a05f-363b0b4ce6f4")
+	require.NoError(t, err)
+
+	mw := &testMiddleware{msgQuota: 0}
+	eng := engine.NewBuilder().WithMiddleware(mw, &enrichMiddleware{}).Build()
+
+	env := envs.NewBuilder().Build()
+	contact := flows.NewEmptyContact(sa, "Bob", envs.NilLanguage, nil)
+	trigger := triggers.NewBuilder(env, flow.Reference(), contact).Manual().Build()
+
+	session, sprint, err := eng.NewSession(sa, trigger)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"error", "msg_created", "msg_wait"}, mw.seen)
+
+	// message was replaced by an error, and each error was followed by the error added by the second middleware
+	eventTypes := func(evts []flows.Event) []string {
+		types := make([]string, len(evts))
+		for i := range evts {
+			types[i] = evts[i].Type()
+		}
+		return types
+	}
+	assert.Equal(t, []string{"error", "error", "error", "error", "msg_wait"}, eventTypes(sprint.Events()))
+	assert.Equal(t, eventTypes(sprint.Events()), eventTypes(session.Runs()[0].Events()))
+	assert.Equal(t, "message quota exceeded", sprint.Events()[2].(*events.ErrorEvent).Text)
+
+	// resuming will try to change the contact language but that modifier will be vetoed
+	mw.msgQuota = 1
+	msg := flows.NewMsgIn(flows.MsgUUID("8d3c4a5e-3c6e-4a5b-9c3a-6b8e5d2d6a3f"), urns.NilURN, nil, "RED", nil)
+	sprint, err = session.Resume(resumes.NewMsg(nil, nil, msg))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"msg_received", "run_result_changed", "msg_created", "msg_wait"}, eventTypes(sprint.Events()))
+	assert.Equal(t, 0, len(sprint.Modifiers()))
+	assert.Equal(t, envs.NilLanguage, session.Contact().Language())
+}
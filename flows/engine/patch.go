@@ -0,0 +1,414 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+
+	"github.com/nyaruka/gocommon/jsonx"
+
+	"github.com/pkg/errors"
+)
+
+// ValueChange is a change to a single JSON value. A nil old value means the value was added and a nil new value
+// means it was removed.
+type ValueChange struct {
+	Old json.RawMessage `json:"old,omitempty"`
+	New json.RawMessage `json:"new,omitempty"`
+}
+
+func (c *ValueChange) invert() *ValueChange {
+	return &ValueChange{Old: c.New, New: c.Old}
+}
+
+// ListPatch is a change to a JSON list where items are mostly appended, e.g. the path or events of a run. The
+// first Keep items are unchanged and the items after those are replaced.
+type ListPatch struct {
+	Keep int               `json:"keep"`
+	Old  []json.RawMessage `json:"old,omitempty"`
+	New  []json.RawMessage `json:"new,omitempty"`
+}
+
+func (p *ListPatch) invert() *ListPatch {
+	return &ListPatch{Keep: p.Keep, Old: p.New, New: p.Old}
+}
+
+// RunPatch is a change to a single run of a session. If the run was added or removed, the entire run is
+// recorded in the patch along with its position in the list of runs, otherwise only changes to the run's fields,
+// path, events and results.
+type RunPatch struct {
+	UUID    string                  `json:"uuid"`
+	Index   int                     `json:"index,omitempty"`
+	Added   json.RawMessage         `json:"added,omitempty"`
+	Removed json.RawMessage         `json:"removed,omitempty"`
+	Fields  map[string]*ValueChange `json:"fields,omitempty"`
+	Path    *ListPatch              `json:"path,omitempty"`
+	Events  *ListPatch              `json:"events,omitempty"`
+	Results map[string]*ValueChange `json:"results,omitempty"`
+}
+
+func (p *RunPatch) invert() *RunPatch {
+	inverted := &RunPatch{UUID: p.UUID, Index: p.Index, Added: p.Removed, Removed: p.Added, Fields: invertChanges(p.Fields), Results: invertChanges(p.Results)}
+	if p.Path != nil {
+		inverted.Path = p.Path.invert()
+	}
+	if p.Events != nil {
+		inverted.Events = p.Events.invert()
+	}
+	return inverted
+}
+
+// SessionPatch is a reversible change between two serialized versions of the same session
+type SessionPatch struct {
+	Fields map[string]*ValueChange `json:"fields,omitempty"`
+	Runs   []*RunPatch             `json:"runs,omitempty"`
+}
+
+// IsEmpty returns whether this patch contains no changes
+func (p *SessionPatch) IsEmpty() bool {
+	return len(p.Fields) == 0 && len(p.Runs) == 0
+}
+
+// Invert returns a patch which reverses this patch
+func (p *SessionPatch) Invert() *SessionPatch {
+	inverted := &SessionPatch{Fields: invertChanges(p.Fields)}
+	for _, runPatch := range p.Runs {
+		inverted.Runs = append(inverted.Runs, runPatch.invert())
+	}
+	return inverted
+}
+
+// DiffSessions creates a patch which describes the changes between the two given serialized sessions, e.g. before
+// and after a resume. Runs are matched by UUID and can be added or removed, but runs which exist in both sessions
+// can't be reordered.
+func DiffSessions(before, after json.RawMessage) (*SessionPatch, error) {
+	beforeFields, beforeRuns, err := readSessionForPatch(before)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read before session")
+	}
+	afterFields, afterRuns, err := readSessionForPatch(after)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read after session")
+	}
+
+	patch := &SessionPatch{Fields: diffObjects(beforeFields, afterFields)}
+
+	afterByUUID := make(map[string]map[string]json.RawMessage, len(afterRuns))
+	for _, run := range afterRuns {
+		afterByUUID[runUUID(run)] = run
+	}
+	beforeByUUID := make(map[string]map[string]json.RawMessage, len(beforeRuns))
+	kept := make([]string, 0, len(beforeRuns))
+
+	for i, beforeRun := range beforeRuns {
+		uuid := runUUID(beforeRun)
+		beforeByUUID[uuid] = beforeRun
+
+		afterRun := afterByUUID[uuid]
+		if afterRun == nil {
+			patch.Runs = append(patch.Runs, &RunPatch{UUID: uuid, Index: i, Removed: marshalObject(beforeRun)})
+			continue
+		}
+
+		kept = append(kept, uuid)
+
+		if runPatch := diffRuns(uuid, beforeRun, afterRun); runPatch != nil {
+			patch.Runs = append(patch.Runs, runPatch)
+		}
+	}
+
+	// record new runs, checking that the runs that were kept are still in the same order
+	numKept := 0
+	for i, afterRun := range afterRuns {
+		uuid := runUUID(afterRun)
+		if beforeByUUID[uuid] == nil {
+			patch.Runs = append(patch.Runs, &RunPatch{UUID: uuid, Index: i, Added: marshalObject(afterRun)})
+		} else if uuid != kept[numKept] {
+			return nil, errors.Errorf("run %s has been reordered", uuid)
+		} else {
+			numKept++
+		}
+	}
+
+	return patch, nil
+}
+
+// ApplySessionPatch applies the given patch to the given serialized session, returning the patched session. An
+// error is returned if the session isn't the one the patch was created from.
+func ApplySessionPatch(session json.RawMessage, patch *SessionPatch) (json.RawMessage, error) {
+	fields, runs, err := readSessionForPatch(session)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read session")
+	}
+
+	if err := applyChanges(fields, patch.Fields, "session"); err != nil {
+		return nil, err
+	}
+
+	// first apply changes to existing runs and removals
+	added := make([]*RunPatch, 0)
+
+	for _, runPatch := range patch.Runs {
+		if runPatch.Added != nil {
+			added = append(added, runPatch)
+			continue
+		}
+
+		index := -1
+		for i, run := range runs {
+			if runUUID(run) == runPatch.UUID {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			return nil, errors.Errorf("patch can't be applied to session without run %s", runPatch.UUID)
+		}
+
+		if runPatch.Removed != nil {
+			runs = append(runs[:index], runs[index+1:]...)
+			continue
+		}
+
+		if err := applyRunPatch(runs[index], runPatch); err != nil {
+			return nil, err
+		}
+	}
+
+	// then insert new runs at their positions
+	sort.SliceStable(added, func(i, j int) bool { return added[i].Index < added[j].Index })
+
+	for _, runPatch := range added {
+		run := make(map[string]json.RawMessage)
+		if err := json.Unmarshal(runPatch.Added, &run); err != nil {
+			return nil, errors.Wrapf(err, "unable to read added run %s", runPatch.UUID)
+		}
+		if runPatch.Index > len(runs) {
+			return nil, errors.Errorf("patch can't be applied to session without runs before run %s", runPatch.UUID)
+		}
+
+		runs = append(runs, nil)
+		copy(runs[runPatch.Index+1:], runs[runPatch.Index:])
+		runs[runPatch.Index] = run
+	}
+
+	marshaledRuns := make([]json.RawMessage, len(runs))
+	for i, run := range runs {
+		marshaledRuns[i] = marshalObject(run)
+	}
+	fields["runs"] = jsonx.MustMarshal(marshaledRuns)
+
+	return marshalObject(fields), nil
+}
+
+// creates a patch for a run that exists in both sessions, or nil if there are no changes
+func diffRuns(uuid string, before, after map[string]json.RawMessage) *RunPatch {
+	beforePath, afterPath := readList(before["path"]), readList(after["path"])
+	beforeEvents, afterEvents := readList(before["events"]), readList(after["events"])
+	beforeResults, afterResults := readObject(before["results"]), readObject(after["results"])
+
+	patch := &RunPatch{
+		UUID:    uuid,
+		Fields:  diffObjects(withoutKeys(before, "path", "events", "results"), withoutKeys(after, "path", "events", "results")),
+		Path:    diffLists(beforePath, afterPath),
+		Events:  diffLists(beforeEvents, afterEvents),
+		Results: diffObjects(beforeResults, afterResults),
+	}
+
+	if len(patch.Fields) == 0 && patch.Path == nil && patch.Events == nil && len(patch.Results) == 0 {
+		return nil
+	}
+	return patch
+}
+
+func applyRunPatch(run map[string]json.RawMessage, patch *RunPatch) error {
+	description := "run " + patch.UUID
+
+	if err := applyChanges(run, patch.Fields, description); err != nil {
+		return err
+	}
+
+	for _, list := range []struct {
+		key   string
+		patch *ListPatch
+	}{{"path", patch.Path}, {"events", patch.Events}} {
+		if list.patch == nil {
+			continue
+		}
+		items, err := applyListPatch(readList(run[list.key]), list.patch)
+		if err != nil {
+			return errors.Wrapf(err, "patch can't be applied to %s of %s", list.key, description)
+		}
+		if len(items) > 0 {
+			run[list.key] = jsonx.MustMarshal(items)
+		} else {
+			delete(run, list.key)
+		}
+	}
+
+	if len(patch.Results) > 0 {
+		results := readObject(run["results"])
+		if err := applyChanges(results, patch.Results, "results of "+description); err != nil {
+			return err
+		}
+		if len(results) > 0 {
+			run["results"] = marshalObject(results)
+		} else {
+			delete(run, "results")
+		}
+	}
+
+	return nil
+}
+
+// creates changes for all the keys which differ between the two given objects
+func diffObjects(before, after map[string]json.RawMessage) map[string]*ValueChange {
+	changes := make(map[string]*ValueChange)
+
+	for key, oldValue := range before {
+		newValue, exists := after[key]
+		if !exists {
+			changes[key] = &ValueChange{Old: oldValue}
+		} else if !bytes.Equal(oldValue, newValue) {
+			changes[key] = &ValueChange{Old: oldValue, New: newValue}
+		}
+	}
+	for key, newValue := range after {
+		if _, exists := before[key]; !exists {
+			changes[key] = &ValueChange{New: newValue}
+		}
+	}
+
+	if len(changes) == 0 {
+		return nil
+	}
+	return changes
+}
+
+// applies the given changes to the given object, checking that current values are what the changes expect
+func applyChanges(obj map[string]json.RawMessage, changes map[string]*ValueChange, description string) error {
+	keys := make([]string, 0, len(changes))
+	for key := range changes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		change := changes[key]
+		current, exists := obj[key]
+
+		if (change.Old == nil && exists) || (change.Old != nil && !bytes.Equal(compactJSON(change.Old), current)) {
+			return errors.Errorf("patch can't be applied to %s because '%s' has changed", description, key)
+		}
+
+		if change.New != nil {
+			obj[key] = compactJSON(change.New)
+		} else {
+			delete(obj, key)
+		}
+	}
+	return nil
+}
+
+// creates a patch for the items after the common prefix of the two given lists, or nil if they're the same
+func diffLists(before, after []json.RawMessage) *ListPatch {
+	keep := 0
+	for keep < len(before) && keep < len(after) && bytes.Equal(before[keep], after[keep]) {
+		keep++
+	}
+
+	if keep == len(before) && keep == len(after) {
+		return nil
+	}
+	return &ListPatch{Keep: keep, Old: before[keep:], New: after[keep:]}
+}
+
+func applyListPatch(items []json.RawMessage, patch *ListPatch) ([]json.RawMessage, error) {
+	if len(items) != patch.Keep+len(patch.Old) {
+		return nil, errors.Errorf("expected %d items, found %d", patch.Keep+len(patch.Old), len(items))
+	}
+	for i, old := range patch.Old {
+		if !bytes.Equal(compactJSON(old), items[patch.Keep+i]) {
+			return nil, errors.Errorf("item %d has changed", patch.Keep+i)
+		}
+	}
+
+	patched := make([]json.RawMessage, 0, patch.Keep+len(patch.New))
+	patched = append(patched, items[:patch.Keep]...)
+	for _, item := range patch.New {
+		patched = append(patched, compactJSON(item))
+	}
+	return patched, nil
+}
+
+// reads the top-level fields of a session, separating out its runs
+func readSessionForPatch(data json.RawMessage) (map[string]json.RawMessage, []map[string]json.RawMessage, error) {
+	fields := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(compactJSON(data), &fields); err != nil {
+		return nil, nil, err
+	}
+
+	runs := make([]map[string]json.RawMessage, 0)
+	for _, run := range readList(fields["runs"]) {
+		runFields := readObject(run)
+		if runUUID(runFields) == "" {
+			return nil, nil, errors.New("session contains run without UUID")
+		}
+		runs = append(runs, runFields)
+	}
+	delete(fields, "runs")
+
+	return fields, runs, nil
+}
+
+func runUUID(run map[string]json.RawMessage) string {
+	var uuid string
+	json.Unmarshal(run["uuid"], &uuid)
+	return uuid
+}
+
+func readList(data json.RawMessage) []json.RawMessage {
+	var items []json.RawMessage
+	json.Unmarshal(data, &items)
+	return items
+}
+
+func readObject(data json.RawMessage) map[string]json.RawMessage {
+	obj := make(map[string]json.RawMessage)
+	json.Unmarshal(data, &obj)
+	return obj
+}
+
+func marshalObject(obj map[string]json.RawMessage) json.RawMessage {
+	return jsonx.MustMarshal(obj)
+}
+
+func withoutKeys(obj map[string]json.RawMessage, keys ...string) map[string]json.RawMessage {
+	filtered := make(map[string]json.RawMessage, len(obj))
+	for k, v := range obj {
+		filtered[k] = v
+	}
+	for _, k := range keys {
+		delete(filtered, k)
+	}
+	return filtered
+}
+
+func invertChanges(changes map[string]*ValueChange) map[string]*ValueChange {
+	if changes == nil {
+		return nil
+	}
+	inverted := make(map[string]*ValueChange, len(changes))
+	for k, c := range changes {
+		inverted[k] = c.invert()
+	}
+	return inverted
+}
+
+func compactJSON(data json.RawMessage) json.RawMessage {
+	b := &bytes.Buffer{}
+	if err := json.Compact(b, data); err != nil {
+		return data
+	}
+	return b.Bytes()
+}
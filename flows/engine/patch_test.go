@@ -0,0 +1,118 @@
+package engine_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nyaruka/gocommon/jsonx"
+	"github.com/nyaruka/goflow/flows/engine"
+	"github.com/nyaruka/goflow/test"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionPatches(t *testing.T) {
+	testFiles, err := filepath.Glob("../../test/testdata/runner/*.test*.json")
+	require.NoError(t, err)
+
+	numPatches := 0
+
+	for _, testFile := range testFiles {
+		testJSON, err := os.ReadFile(testFile)
+		require.NoError(t, err)
+
+		snapshot := &struct {
+			Outputs []struct {
+				Session json.RawMessage `json:"session"`
+			} `json:"outputs"`
+		}{}
+		jsonx.MustUnmarshal(testJSON, snapshot)
+
+		for i := 1; i < len(snapshot.Outputs); i++ {
+			before, after := snapshot.Outputs[i-1].Session, snapshot.Outputs[i].Session
+
+			patch, err := engine.DiffSessions(before, after)
+			require.NoError(t, err, "error diffing output[%d] in %s", i, testFile)
+
+			// patch should be smaller than the session it produces
+			patchJSON := jsonx.MustMarshal(patch)
+			assert.Less(t, len(patchJSON), len(after), "patch for output[%d] in %s not smaller than session", i, testFile)
+
+			// patch should survive being serialized
+			patch = &engine.SessionPatch{}
+			jsonx.MustUnmarshal(patchJSON, patch)
+
+			patched, err := engine.ApplySessionPatch(before, patch)
+			require.NoError(t, err, "error applying patch for output[%d] in %s", i, testFile)
+			test.AssertEqualJSON(t, after, patched, "patched session mismatch for output[%d] in %s", i, testFile)
+
+			reverted, err := engine.ApplySessionPatch(after, patch.Invert())
+			require.NoError(t, err, "error reverting patch for output[%d] in %s", i, testFile)
+			test.AssertEqualJSON(t, before, reverted, "reverted session mismatch for output[%d] in %s", i, testFile)
+
+			// patch can't be applied to the session it produced, unless it's empty because the session didn't change
+			if !patch.IsEmpty() {
+				_, err = engine.ApplySessionPatch(after, patch)
+				assert.Error(t, err, "patch for output[%d] in %s re-applied to its own output", i, testFile)
+			}
+
+			numPatches++
+		}
+	}
+
+	assert.Greater(t, numPatches, 20)
+}
+
+func TestSessionPatchRuns(t *testing.T) {
+	before := []byte(`{"status": "waiting", "runs": [{"uuid": "r1", "status": "waiting"}, {"uuid": "r2", "status": "active"}, {"uuid": "r3", "status": "waiting"}]}`)
+	after := []byte(`{"status": "completed", "runs": [{"uuid": "r4", "status": "completed"}, {"uuid": "r1", "status": "completed", "results": {"color": {"value": "red"}}}, {"uuid": "r3", "status": "waiting"}, {"uuid": "r5", "status": "completed"}]}`)
+
+	patch, err := engine.DiffSessions(before, after)
+	require.NoError(t, err)
+
+	test.AssertEqualJSON(t, []byte(`{
+		"fields": {"status": {"old": "waiting", "new": "completed"}},
+		"runs": [
+			{
+				"uuid": "r1",
+				"fields": {"status": {"old": "waiting", "new": "completed"}},
+				"results": {"color": {"new": {"value": "red"}}}
+			},
+			{"uuid": "r2", "index": 1, "removed": {"status": "active", "uuid": "r2"}},
+			{"uuid": "r4", "added": {"status": "completed", "uuid": "r4"}},
+			{"uuid": "r5", "index": 3, "added": {"status": "completed", "uuid": "r5"}}
+		]
+	}`), jsonx.MustMarshal(patch), "patch mismatch")
+
+	patched, err := engine.ApplySessionPatch(before, patch)
+	require.NoError(t, err)
+	test.AssertEqualJSON(t, after, patched, "patched session mismatch")
+
+	reverted, err := engine.ApplySessionPatch(after, patch.Invert())
+	require.NoError(t, err)
+	test.AssertEqualJSON(t, before, reverted, "reverted session mismatch")
+
+	// no changes gives an empty patch
+	patch, err = engine.DiffSessions(before, before)
+	require.NoError(t, err)
+	assert.True(t, patch.IsEmpty())
+
+	// runs which exist in both sessions can't be reordered
+	_, err = engine.DiffSessions(before, []byte(`{"runs": [{"uuid": "r3"}, {"uuid": "r1"}]}`))
+	assert.EqualError(t, err, "run r3 has been reordered")
+
+	_, err = engine.DiffSessions([]byte(`{"runs": [{}]}`), after)
+	assert.EqualError(t, err, "unable to read before session: session contains run without UUID")
+
+	// patches check that they're being applied to the right session
+	patch, _ = engine.DiffSessions(before, after)
+
+	_, err = engine.ApplySessionPatch([]byte(`{"status": "active", "runs": []}`), patch)
+	assert.EqualError(t, err, "patch can't be applied to session because 'status' has changed")
+
+	_, err = engine.ApplySessionPatch([]byte(`{"status": "waiting", "runs": []}`), patch)
+	assert.EqualError(t, err, "patch can't be applied to session without run r1")
+}
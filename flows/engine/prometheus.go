@@ -0,0 +1,211 @@
+package engine
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/nyaruka/goflow/flows"
+)
+
+// DefaultBuckets are the histogram buckets used for metrics which haven't been given their own
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+type histogramSeries struct {
+	labels map[string]string
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+// PrometheusMetrics is a metrics sink which aggregates metrics in memory so that they can be exported in the
+// Prometheus text format
+type PrometheusMetrics struct {
+	mutex      sync.Mutex
+	buckets    map[string][]float64
+	counters   map[string]map[string]float64
+	histograms map[string]map[string]*histogramSeries
+}
+
+// NewPrometheusMetrics creates a new Prometheus metrics sink
+func NewPrometheusMetrics() *PrometheusMetrics {
+	return &PrometheusMetrics{
+		buckets: map[string][]float64{
+			flows.MetricSprintSteps: {1, 2, 5, 10, 25, 50, 100},
+		},
+		counters:   make(map[string]map[string]float64),
+		histograms: make(map[string]map[string]*histogramSeries),
+	}
+}
+
+// WithBuckets sets the buckets used for the named histogram
+func (m *PrometheusMetrics) WithBuckets(name string, buckets ...float64) *PrometheusMetrics {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	sorted := make([]float64, len(buckets))
+	copy(sorted, buckets)
+	sort.Float64s(sorted)
+
+	m.buckets[name] = sorted
+	return m
+}
+
+// IncCounter increments the named counter
+func (m *PrometheusMetrics) IncCounter(name string, labels map[string]string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	key := formatLabels(labels, "", "")
+
+	if m.counters[name] == nil {
+		m.counters[name] = make(map[string]float64)
+	}
+	m.counters[name][key]++
+}
+
+// ObserveHistogram adds an observation to the named histogram
+func (m *PrometheusMetrics) ObserveHistogram(name string, value float64, labels map[string]string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	key := formatLabels(labels, "", "")
+	buckets := m.bucketsFor(name)
+
+	series := m.histograms[name]
+	if series == nil {
+		series = make(map[string]*histogramSeries)
+		m.histograms[name] = series
+	}
+	if series[key] == nil {
+		labelsCopy := make(map[string]string, len(labels))
+		for k, v := range labels {
+			labelsCopy[k] = v
+		}
+		series[key] = &histogramSeries{labels: labelsCopy, counts: make([]uint64, len(buckets))}
+	}
+
+	h := series[key]
+	for i, upper := range buckets {
+		if value <= upper {
+			h.counts[i]++
+		}
+	}
+	h.sum += value
+	h.count++
+}
+
+// WriteTo writes all metrics in the Prometheus text format to the given writer
+func (m *PrometheusMetrics) WriteTo(w io.Writer) (int64, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	b := &bytes.Buffer{}
+
+	counterNames := make([]string, 0, len(m.counters))
+	for name := range m.counters {
+		counterNames = append(counterNames, name)
+	}
+
+	for _, name := range sortedStrings(counterNames) {
+		fmt.Fprintf(b, "# TYPE %s counter\n", name)
+
+		series := m.counters[name]
+		keys := make([]string, 0, len(series))
+		for key := range series {
+			keys = append(keys, key)
+		}
+
+		for _, key := range sortedStrings(keys) {
+			fmt.Fprintf(b, "%s%s %s\n", name, key, formatFloat(series[key]))
+		}
+	}
+
+	histogramNames := make([]string, 0, len(m.histograms))
+	for name := range m.histograms {
+		histogramNames = append(histogramNames, name)
+	}
+
+	for _, name := range sortedStrings(histogramNames) {
+		fmt.Fprintf(b, "# TYPE %s histogram\n", name)
+
+		buckets := m.bucketsFor(name)
+		series := m.histograms[name]
+		keys := make([]string, 0, len(series))
+		for key := range series {
+			keys = append(keys, key)
+		}
+
+		for _, key := range sortedStrings(keys) {
+			h := series[key]
+			for i, upper := range buckets {
+				fmt.Fprintf(b, "%s_bucket%s %d\n", name, formatLabels(h.labels, "le", formatFloat(upper)), h.counts[i])
+			}
+			fmt.Fprintf(b, "%s_bucket%s %d\n", name, formatLabels(h.labels, "le", "+Inf"), h.count)
+			fmt.Fprintf(b, "%s_sum%s %s\n", name, key, formatFloat(h.sum))
+			fmt.Fprintf(b, "%s_count%s %d\n", name, key, h.count)
+		}
+	}
+
+	return b.WriteTo(w)
+}
+
+// ServeHTTP serves all metrics in the Prometheus text format
+func (m *PrometheusMetrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	m.WriteTo(w)
+}
+
+func (m *PrometheusMetrics) bucketsFor(name string) []float64 {
+	if buckets, ok := m.buckets[name]; ok {
+		return buckets
+	}
+	return DefaultBuckets
+}
+
+var _ flows.Metrics = (*PrometheusMetrics)(nil)
+
+// formats the given labels, and optionally an extra label, as {k1="v1",k2="v2"} with keys sorted
+func formatLabels(labels map[string]string, extraKey, extraValue string) string {
+	if len(labels) == 0 && extraKey == "" {
+		return ""
+	}
+
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+
+	pairs := make([]string, 0, len(labels)+1)
+	for _, k := range sortedStrings(keys) {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, k, escapeLabelValue(labels[k])))
+	}
+	if extraKey != "" {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, extraKey, extraValue))
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabelValue(v string) string {
+	return labelValueEscaper.Replace(v)
+}
+
+func formatFloat(f float64) string {
+	if math.IsInf(f, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+func sortedStrings(keys []string) []string {
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,218 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/events"
+	"github.com/nyaruka/goflow/utils"
+
+	"github.com/pkg/errors"
+)
+
+// DivergenceType is the type of a difference found when replaying a session
+type DivergenceType string
+
+// possible types of divergence
+const (
+	DivergenceTypeStatus DivergenceType = "status"
+	DivergenceTypeRuns   DivergenceType = "runs"
+	DivergenceTypeFlow   DivergenceType = "flow"
+	DivergenceTypeNode   DivergenceType = "node"
+	DivergenceTypeExit   DivergenceType = "exit"
+	DivergenceTypeResult DivergenceType = "result"
+	DivergenceTypeEvent  DivergenceType = "event"
+)
+
+// Divergence is a point where a replayed session differs from the original session
+type Divergence struct {
+	Type      DivergenceType `json:"type"`
+	RunIndex  int            `json:"run_index"`
+	StepIndex int            `json:"step_index"`
+	Key       string         `json:"key,omitempty"`
+	Expected  string         `json:"expected"`
+	Actual    string         `json:"actual"`
+}
+
+func (d *Divergence) String() string {
+	return fmt.Sprintf("%s differs in run[%d] at step[%d]%s: expected '%s', got '%s'", d.Type, d.RunIndex, d.StepIndex, d.key(), d.Expected, d.Actual)
+}
+
+func (d *Divergence) key() string {
+	if d.Key != "" {
+		return fmt.Sprintf(" for '%s'", d.Key)
+	}
+	return ""
+}
+
+// ReplayReport is the result of replaying a session
+type ReplayReport struct {
+	Session     flows.Session `json:"-"`
+	Divergences []*Divergence `json:"divergences"`
+}
+
+// Diverged returns whether the replayed session differs from the original
+func (r *ReplayReport) Diverged() bool { return len(r.Divergences) > 0 }
+
+func (r *ReplayReport) add(type_ DivergenceType, runIndex, stepIndex int, key, expected, actual string) {
+	r.Divergences = append(r.Divergences, &Divergence{Type: type_, RunIndex: runIndex, StepIndex: stepIndex, Key: key, Expected: expected, Actual: actual})
+}
+
+// Replay starts a new session with the given trigger and resumes it with each of the given resumes, against
+// the given session assets which may contain newer flow revisions. The resulting session is then compared with
+// the original session and a report returned of where they diverge. Only things which are deterministic are
+// compared, i.e. the flows, nodes and exits of each run's path, the run results and the types of events. If the new
+// session ends before all resumes have been applied, the remaining resumes are skipped and that is reported as a
+// status divergence.
+func Replay(eng flows.Engine, sa flows.SessionAssets, original json.RawMessage, trigger flows.Trigger, resumes []flows.Resume) (*ReplayReport, error) {
+	expected, err := eng.ReadSession(sa, original, assets.IgnoreMissing)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read original session")
+	}
+
+	actual, _, err := eng.NewSession(sa, trigger)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to start session")
+	}
+
+	numResumed := 0
+	for i, resume := range resumes {
+		// a session which completes or fails earlier than the original can't be resumed any further
+		if actual.Status() != flows.SessionStatusWaiting {
+			break
+		}
+
+		if _, err := actual.Resume(resume); err != nil {
+			return nil, errors.Wrapf(err, "unable to resume session with resume[%d]", i)
+		}
+		numResumed++
+	}
+
+	report := &ReplayReport{Session: actual, Divergences: make([]*Divergence, 0)}
+
+	if numResumed < len(resumes) {
+		report.add(DivergenceTypeStatus, 0, 0, fmt.Sprintf("resume[%d]", numResumed), string(flows.SessionStatusWaiting), string(actual.Status()))
+	} else if expected.Status() != actual.Status() {
+		report.add(DivergenceTypeStatus, 0, 0, "", string(expected.Status()), string(actual.Status()))
+	}
+	if len(expected.Runs()) != len(actual.Runs()) {
+		report.add(DivergenceTypeRuns, 0, 0, "", fmt.Sprint(len(expected.Runs())), fmt.Sprint(len(actual.Runs())))
+	}
+
+	for i := 0; i < len(expected.Runs()) && i < len(actual.Runs()); i++ {
+		compareRuns(report, i, expected.Runs()[i], actual.Runs()[i])
+	}
+
+	return report, nil
+}
+
+// compares the two given runs, recording the first place where their paths diverge
+func compareRuns(report *ReplayReport, runIndex int, expected, actual flows.FlowRun) {
+	if expected.FlowReference().UUID != actual.FlowReference().UUID {
+		report.add(DivergenceTypeFlow, runIndex, 0, "", string(expected.FlowReference().UUID), string(actual.FlowReference().UUID))
+		return
+	}
+
+	expectedPath, actualPath := expected.Path(), actual.Path()
+
+	for s := 0; s < len(expectedPath) || s < len(actualPath); s++ {
+		if s >= len(expectedPath) || s >= len(actualPath) {
+			report.add(DivergenceTypeNode, runIndex, s, "", nodeAt(expectedPath, s), nodeAt(actualPath, s))
+			break
+		}
+		if expectedPath[s].NodeUUID() != actualPath[s].NodeUUID() {
+			report.add(DivergenceTypeNode, runIndex, s, "", nodeAt(expectedPath, s), nodeAt(actualPath, s))
+			break
+		}
+
+		// compare the events logged on the step
+		expectedEvents := eventsForStep(expected, expectedPath[s].UUID())
+		actualEvents := eventsForStep(actual, actualPath[s].UUID())
+
+		if expectedEvents != actualEvents {
+			report.add(DivergenceTypeEvent, runIndex, s, "", expectedEvents, actualEvents)
+			break
+		}
+		if expectedPath[s].ExitUUID() != actualPath[s].ExitUUID() {
+			report.add(DivergenceTypeExit, runIndex, s, "", string(expectedPath[s].ExitUUID()), string(actualPath[s].ExitUUID()))
+			break
+		}
+	}
+
+	// compare results by key
+	keys := make(map[string]bool)
+	for k := range expected.Results() {
+		keys[k] = true
+	}
+	for k := range actual.Results() {
+		keys[k] = true
+	}
+	sortedKeys := make([]string, 0, len(keys))
+	for k := range keys {
+		sortedKeys = append(sortedKeys, k)
+	}
+	sort.Strings(sortedKeys)
+
+	for _, k := range sortedKeys {
+		expectedResult, actualResult := resultSummary(expected.Results().Get(k)), resultSummary(actual.Results().Get(k))
+		if expectedResult != actualResult {
+			report.add(DivergenceTypeResult, runIndex, stepIndexForResult(expected, actual, k), k, expectedResult, actualResult)
+		}
+	}
+}
+
+func nodeAt(path []flows.Step, i int) string {
+	if i < len(path) {
+		return string(path[i].NodeUUID())
+	}
+	return ""
+}
+
+// summarizes the events logged on the given step, including the text of any messages
+func eventsForStep(run flows.FlowRun, stepUUID flows.StepUUID) string {
+	summary := ""
+	for _, e := range run.Events() {
+		if e.StepUUID() != stepUUID {
+			continue
+		}
+		if summary != "" {
+			summary += ", "
+		}
+		switch typed := e.(type) {
+		case *events.MsgCreatedEvent:
+			summary += fmt.Sprintf("%s[text=%s]", typed.Type(), typed.Msg.Text())
+		case *events.IVRCreatedEvent:
+			summary += fmt.Sprintf("%s[text=%s]", typed.Type(), typed.Msg.Text())
+		default:
+			summary += e.Type()
+		}
+	}
+	return summary
+}
+
+func resultSummary(r *flows.Result) string {
+	if r == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s (%s)", r.Value, r.Category)
+}
+
+// finds the index of the step in either run where the given result was last changed
+func stepIndexForResult(expected, actual flows.FlowRun, key string) int {
+	for _, run := range []flows.FlowRun{actual, expected} {
+		for i := len(run.Events()) - 1; i >= 0; i-- {
+			typed, isResultEvent := run.Events()[i].(*events.RunResultChangedEvent)
+			if isResultEvent && utils.Snakify(typed.Name) == key {
+				for s, step := range run.Path() {
+					if step.UUID() == typed.StepUUID() {
+						return s
+					}
+				}
+			}
+		}
+	}
+	return 0
+}
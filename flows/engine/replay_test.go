@@ -0,0 +1,88 @@
+package engine_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/nyaruka/gocommon/jsonx"
+	"github.com/nyaruka/gocommon/urns"
+	"github.com/nyaruka/goflow/envs"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/engine"
+	"github.com/nyaruka/goflow/flows/resumes"
+	"github.com/nyaruka/goflow/flows/triggers"
+	"github.com/nyaruka/goflow/test"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplay(t *testing.T) {
+	assetsJSON, err := os.ReadFile("../../test/testdata/runner/two_questions.json")
+	require.NoError(t, err)
+
+	sa, err := test.CreateSessionAssets(assetsJSON, "")
+	require.NoError(t, err)
+
+	flow, err := sa.Flows().Get("615b8a0f-588c-4d20-a05f-363b0b4ce6f4")
+	require.NoError(t, err)
+
+	env := envs.NewBuilder().Build()
+	contact := flows.NewEmptyContact(sa, "Bob", envs.NilLanguage, nil)
+	trigger := triggers.NewBuilder(env, flow.Reference(), contact).Manual().Build()
+	resumez := []flows.Resume{
+		resumes.NewMsg(nil, nil, flows.NewMsgIn(flows.MsgUUID("8d3c4a5e-3c6e-4a5b-9c3a-6b8e5d2d6a3f"), urns.NilURN, nil, "RED", nil)),
+	}
+
+	// create the original session
+	eng := engine.NewBuilder().Build()
+	session, _, err := eng.NewSession(sa, trigger)
+	require.NoError(t, err)
+	_, err = session.Resume(resumez[0])
+	require.NoError(t, err)
+
+	originalJSON := jsonx.MustMarshal(session)
+
+	// replaying against the same flows shouldn't diverge
+	report, err := engine.Replay(eng, sa, originalJSON, trigger, resumez)
+	require.NoError(t, err)
+	assert.False(t, report.Diverged())
+	assert.Equal(t, flows.SessionStatusWaiting, report.Session.Status())
+
+	// change the first message and the case that matches red
+	editedJSON := test.JSONReplace(assetsJSON, []string{"flows", "[0]", "nodes", "[0]", "actions", "[0]", "text"}, []byte(`"What is your favorite color?"`))
+	editedJSON = test.JSONReplace(editedJSON, []string{"flows", "[0]", "nodes", "[0]", "router", "cases", "[0]", "arguments", "[0]"}, []byte(`"rouge"`))
+
+	editedSA, err := test.CreateSessionAssets(editedJSON, "")
+	require.NoError(t, err)
+
+	report, err = engine.Replay(eng, editedSA, originalJSON, trigger, resumez)
+	require.NoError(t, err)
+	assert.True(t, report.Diverged())
+
+	require.Equal(t, 2, len(report.Divergences))
+	assert.Equal(t, engine.DivergenceTypeEvent, report.Divergences[0].Type)
+	assert.Equal(t, "event differs in run[0] at step[0]: expected 'error, msg_created[text=Hi Bob! What is your favorite color? (red/blue) Your number is ], msg_wait, msg_received, run_result_changed', got 'msg_created[text=What is your favorite color?], msg_wait, msg_received, run_result_changed'", report.Divergences[0].String())
+	assert.Equal(t, &engine.Divergence{Type: engine.DivergenceTypeResult, RunIndex: 0, StepIndex: 0, Key: "favorite_color", Expected: "RED (Red)", Actual: "RED (Other)"}, report.Divergences[1])
+
+	// remove the wait from the first question so that the session completes before it can be resumed
+	editedJSON = test.JSONDelete(assetsJSON, []string{"flows", "[0]", "nodes", "[0]", "router"})
+	editedJSON = test.JSONReplace(editedJSON, []string{"flows", "[0]", "nodes", "[0]", "exits"}, []byte(`[{"uuid": "3a4b6f1d-6bd6-4bd3-9e6e-8d7a1b5c9e21"}]`))
+
+	editedSA, err = test.CreateSessionAssets(editedJSON, "")
+	require.NoError(t, err)
+
+	report, err = engine.Replay(eng, editedSA, originalJSON, trigger, resumez)
+	require.NoError(t, err)
+	assert.True(t, report.Diverged())
+	assert.Equal(t, flows.SessionStatusCompleted, report.Session.Status())
+
+	require.Equal(t, 3, len(report.Divergences))
+	assert.Equal(t, "status differs in run[0] at step[0] for 'resume[0]': expected 'waiting', got 'completed'", report.Divergences[0].String())
+	assert.Equal(t, engine.DivergenceTypeEvent, report.Divergences[1].Type)
+	assert.Equal(t, &engine.Divergence{Type: engine.DivergenceTypeResult, RunIndex: 0, StepIndex: 0, Key: "favorite_color", Expected: "RED (Red)", Actual: ""}, report.Divergences[2])
+
+	// original session must be valid
+	_, err = engine.Replay(eng, sa, []byte(`{}`), trigger, resumez)
+	assert.EqualError(t, err, "unable to read original session: unable to read session: field 'trigger' is required, field 'status' is required")
+}
@@ -0,0 +1,261 @@
+package engine
+
+import (
+	"sort"
+	"time"
+
+	"github.com/nyaruka/gocommon/jsonx"
+	"github.com/nyaruka/gocommon/urns"
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/envs"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/events"
+	"github.com/nyaruka/goflow/flows/modifiers"
+	"github.com/nyaruka/goflow/flows/routers/waits"
+)
+
+// recreates the activated wait from the event which was logged when a wait began
+func activatedWaitFromEvent(event flows.Event) flows.ActivatedWait {
+	switch typed := event.(type) {
+	case *events.MsgWaitEvent:
+		return waits.NewActivatedMsgWait(typed.TimeoutSeconds, typed.Hint)
+	case *events.DialWaitEvent:
+		return waits.NewActivatedDialWait(typed.URN)
+	case *events.DelayWaitEvent:
+		return waits.NewActivatedDelayWait(typed.Until, typed.TimeoutSeconds)
+	}
+	return nil
+}
+
+// sorts the given events by their creation time
+func sortEvents(evts []flows.Event) {
+	sort.SliceStable(evts, func(i, j int) bool { return evts[i].CreatedOn().Before(evts[j].CreatedOn()) })
+}
+
+// the numbers of steps and events of a run which are kept when rewinding
+type runCut struct {
+	steps  int
+	events int
+}
+
+// gets the index of the step with the given UUID in the path of the given run
+func stepIndex(run flows.FlowRun, stepUUID flows.StepUUID) int {
+	for i, s := range run.Path() {
+		if s.UUID() == stepUUID {
+			return i
+		}
+	}
+	return -1
+}
+
+// gets the index of the given child among the child runs of the given parent
+func childIndex(parent, child flows.FlowRun, runs []flows.FlowRun) int {
+	index := 0
+	for _, r := range runs {
+		if r == child {
+			return index
+		}
+		if r.ParentInSession() == parent {
+			index++
+		}
+	}
+	return -1
+}
+
+// counts the flow entered events in the given events, each of which corresponds to a child run
+func countEntered(evts []flows.Event) int {
+	count := 0
+	for _, e := range evts {
+		if e.Type() == events.TypeFlowEntered {
+			count++
+		}
+	}
+	return count
+}
+
+// works out how much of the given parent run to keep so that it ends with entering the given child run
+func enteredCut(parent, child flows.FlowRun, runs []flows.FlowRun) runCut {
+	index := childIndex(parent, child, runs)
+
+	entered := 0
+	for i, e := range parent.Events() {
+		if e.Type() == events.TypeFlowEntered {
+			if entered == index {
+				return runCut{steps: stepIndex(parent, e.StepUUID()) + 1, events: i + 1}
+			}
+			entered++
+		}
+	}
+
+	return runCut{steps: len(parent.Path()), events: len(parent.Events())}
+}
+
+func isKept(run flows.FlowRun, kept []flows.FlowRun) bool {
+	for _, r := range kept {
+		if r == run {
+			return true
+		}
+	}
+	return false
+}
+
+// works out what the session contact looked like after the given events by replaying them onto the contact from
+// the trigger, or the contact from the first resume which refreshed it if the trigger didn't have one
+func (s *session) contactAt(evts []flows.Event) *flows.Contact {
+	var contact *flows.Contact
+	if s.trigger.Contact() != nil {
+		contact = s.trigger.Contact().Clone()
+	}
+
+	for _, event := range evts {
+		if refreshed, ok := event.(*events.ContactRefreshedEvent); ok {
+			if c, err := flows.ReadContact(s.assets, refreshed.Contact, assets.IgnoreMissing); err == nil {
+				contact = c
+			}
+			continue
+		}
+		if contact == nil {
+			continue
+		}
+
+		switch typed := event.(type) {
+		case *events.ContactNameChangedEvent:
+			contact.SetName(typed.Name)
+		case *events.ContactLanguageChangedEvent:
+			contact.SetLanguage(envs.Language(typed.Language))
+		case *events.ContactTimezoneChangedEvent:
+			var tz *time.Location
+			if typed.Timezone != "" {
+				tz, _ = time.LoadLocation(typed.Timezone)
+			}
+			contact.SetTimezone(tz)
+		case *events.ContactStatusChangedEvent:
+			contact.SetStatus(typed.Status)
+		case *events.ContactFieldChangedEvent:
+			field := s.assets.Fields().Get(typed.Field.Key)
+			if field != nil {
+				contact.Fields().Set(field, typed.Value)
+			}
+		case *events.ContactURNsChangedEvent:
+			contact.ClearURNs()
+			for _, urn := range typed.URNs {
+				contact.AddURN(urn, nil)
+			}
+		case *events.ContactGroupsChangedEvent:
+			for _, ref := range typed.GroupsAdded {
+				if group := s.assets.Groups().Get(ref.UUID); group != nil && contact.Groups().FindByUUID(ref.UUID) == nil {
+					contact.Groups().Add(group)
+				}
+			}
+			for _, ref := range typed.GroupsRemoved {
+				if group := s.assets.Groups().Get(ref.UUID); group != nil {
+					contact.Groups().Remove(group)
+				}
+			}
+		case *events.TicketOpenedEvent:
+			ticketJSON, _ := jsonx.Marshal(typed.Ticket)
+			if ticket, err := flows.ReadTicket(s.assets, ticketJSON, assets.IgnoreMissing); err == nil {
+				contact.Tickets().Add(ticket)
+			}
+		case *events.TicketClosedEvent:
+			if ticket := contact.Tickets().FindByUUID(typed.TicketUUID); ticket != nil {
+				ticket.SetStatus(flows.TicketStatusClosed)
+			}
+		case *events.TicketReopenedEvent:
+			if ticket := contact.Tickets().FindByUUID(typed.TicketUUID); ticket != nil {
+				ticket.SetStatus(flows.TicketStatusOpen)
+			}
+		case *events.TicketAssignedEvent:
+			if ticket := contact.Tickets().FindByUUID(typed.TicketUUID); ticket != nil {
+				var assignee *flows.User
+				if typed.Assignee != nil {
+					assignee = s.assets.Users().Get(typed.Assignee.Email)
+				}
+				ticket.SetAssignee(assignee)
+			}
+		case *events.TicketTopicChangedEvent:
+			if ticket := contact.Tickets().FindByUUID(typed.TicketUUID); ticket != nil {
+				ticket.SetTopic(s.assets.Topics().Get(typed.Topic.UUID))
+			}
+		}
+	}
+
+	if contact != nil {
+		contact.ReevaluateQueryBasedGroups(s.env)
+	}
+
+	return contact
+}
+
+// creates the modifiers needed to change the given contact back to the given previous state
+func revertContactModifiers(sa flows.SessionAssets, contact, previous *flows.Contact) []flows.Modifier {
+	mods := make([]flows.Modifier, 0)
+
+	if previous.Status() != contact.Status() {
+		mods = append(mods, modifiers.NewStatus(previous.Status()))
+	}
+	if previous.Name() != contact.Name() {
+		mods = append(mods, modifiers.NewName(previous.Name()))
+	}
+	if previous.Language() != contact.Language() {
+		mods = append(mods, modifiers.NewLanguage(previous.Language()))
+	}
+	if timezoneName(previous.Timezone()) != timezoneName(contact.Timezone()) {
+		mods = append(mods, modifiers.NewTimezone(previous.Timezone()))
+	}
+
+	previousURNs := previous.URNs().RawURNs()
+	if !urnsEqual(previousURNs, contact.URNs().RawURNs()) {
+		mods = append(mods, modifiers.NewURNs(previousURNs, modifiers.URNsSet))
+	}
+
+	for _, field := range sa.Fields().All() {
+		previousValue := previous.Fields().Get(field)
+		if !previousValue.Equals(contact.Fields().Get(field)) {
+			text := ""
+			if previousValue != nil {
+				text = previousValue.Text.Native()
+			}
+			mods = append(mods, modifiers.NewField(field, text))
+		}
+	}
+
+	toAdd, toRemove := make([]*flows.Group, 0), make([]*flows.Group, 0)
+	for _, group := range previous.Groups().All() {
+		if !group.UsesQuery() && contact.Groups().FindByUUID(group.UUID()) == nil {
+			toAdd = append(toAdd, group)
+		}
+	}
+	for _, group := range contact.Groups().All() {
+		if !group.UsesQuery() && previous.Groups().FindByUUID(group.UUID()) == nil {
+			toRemove = append(toRemove, group)
+		}
+	}
+	if len(toRemove) > 0 {
+		mods = append(mods, modifiers.NewGroups(toRemove, modifiers.GroupsRemove))
+	}
+	if len(toAdd) > 0 {
+		mods = append(mods, modifiers.NewGroups(toAdd, modifiers.GroupsAdd))
+	}
+
+	return mods
+}
+
+func urnsEqual(urns1, urns2 []urns.URN) bool {
+	if len(urns1) != len(urns2) {
+		return false
+	}
+	for i := range urns1 {
+		if urns1[i] != urns2[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func timezoneName(tz *time.Location) string {
+	if tz == nil {
+		return ""
+	}
+	return tz.String()
+}
@@ -21,12 +21,16 @@ type TicketServiceFactory func(flows.Session, *flows.Ticketer) (flows.TicketServ
 // AirtimeServiceFactory resolves a session to an airtime service
 type AirtimeServiceFactory func(flows.Session) (flows.AirtimeService, error)
 
+// ContactServiceFactory resolves a session to a contact service
+type ContactServiceFactory func(flows.Session) (flows.ContactService, error)
+
 type services struct {
 	email          EmailServiceFactory
 	webhook        WebhookServiceFactory
 	classification ClassificationServiceFactory
 	ticket         TicketServiceFactory
 	airtime        AirtimeServiceFactory
+	contact        ContactServiceFactory
 }
 
 func newEmptyServices() *services {
@@ -46,6 +50,9 @@ func newEmptyServices() *services {
 		airtime: func(flows.Session) (flows.AirtimeService, error) {
 			return nil, errors.New("no airtime service factory configured")
 		},
+		contact: func(flows.Session) (flows.ContactService, error) {
+			return nil, errors.New("no contact service factory configured")
+		},
 	}
 }
 
@@ -68,3 +75,7 @@ func (s *services) Ticket(session flows.Session, ticketer *flows.Ticketer) (flow
 func (s *services) Airtime(session flows.Session) (flows.AirtimeService, error) {
 	return s.airtime(session)
 }
+
+func (s *services) Contact(session flows.Session) (flows.ContactService, error) {
+	return s.contact(session)
+}
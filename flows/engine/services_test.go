@@ -22,4 +22,8 @@ func TestEmptyServices(t *testing.T) {
 	airtimeSvc, err := eng.Services().Airtime(nil)
 	assert.EqualError(t, err, "no airtime service factory configured")
 	assert.Nil(t, airtimeSvc)
+
+	contactSvc, err := eng.Services().Contact(nil)
+	assert.EqualError(t, err, "no contact service factory configured")
+	assert.Nil(t, contactSvc)
 }
@@ -1,8 +1,11 @@
 package engine
 
 import (
+	"context"
 	"encoding/json"
+	"time"
 
+	"github.com/nyaruka/gocommon/dates"
 	"github.com/nyaruka/gocommon/jsonx"
 	"github.com/nyaruka/goflow/assets"
 	"github.com/nyaruka/goflow/envs"
@@ -11,6 +14,7 @@ import (
 	"github.com/nyaruka/goflow/flows/events"
 	"github.com/nyaruka/goflow/flows/inputs"
 	"github.com/nyaruka/goflow/flows/resumes"
+	"github.com/nyaruka/goflow/flows/routers"
 	"github.com/nyaruka/goflow/flows/routers/waits"
 	"github.com/nyaruka/goflow/flows/runs"
 	"github.com/nyaruka/goflow/flows/triggers"
@@ -19,11 +23,19 @@ import (
 	"github.com/pkg/errors"
 )
 
+// where a paused session will continue from
+type continuation struct {
+	RunUUID  flows.RunUUID  `json:"run_uuid" validate:"required,uuid4"`
+	NodeUUID flows.NodeUUID `json:"node_uuid" validate:"required,uuid4"`
+	Steps    int            `json:"steps"` // steps taken since the session last waited
+}
+
 // used to spawn a new run or sub-flow in the event loop
 type pushedFlow struct {
 	flow      flows.Flow
 	parentRun flows.FlowRun
 	terminal  bool
+	params    *types.XObject
 }
 
 type session struct {
@@ -39,17 +51,29 @@ type session struct {
 	runs          []flows.FlowRun
 	status        flows.SessionStatus
 	wait          flows.ActivatedWait
+	continuation  *continuation
 	input         flows.Input
 
 	// state which is temporary to each call
-	batchStart bool
-	runsByUUID map[flows.RunUUID]flows.FlowRun
-	pushedFlow *pushedFlow
-	parentRun  flows.RunSummary
+	batchStart  bool
+	runsByUUID  map[flows.RunUUID]flows.FlowRun
+	pushedFlow  *pushedFlow
+	parentRun   flows.RunSummary
+	numSteps    int // steps taken in this sprint, including in the branches of forks
+	pausedSteps int
+
+	// tokens fetched for auth profiles, which are never saved with the session
+	authTokens map[assets.AuthProfileUUID]*authToken
 
 	engine flows.Engine
 }
 
+// an access token and when it expires, which is zero if it doesn't
+type authToken struct {
+	token     string
+	expiresOn time.Time
+}
+
 func (s *session) Assets() flows.SessionAssets { return s.assets }
 func (s *session) Trigger() flows.Trigger      { return s.trigger }
 func (s *session) CurrentResume() flows.Resume { return s.currentResume }
@@ -77,8 +101,25 @@ func (s *session) SetInput(input flows.Input) {
 
 func (s *session) BatchStart() bool { return s.batchStart }
 
-func (s *session) PushFlow(flow flows.Flow, parentRun flows.FlowRun, terminal bool) {
-	s.pushedFlow = &pushedFlow{flow: flow, parentRun: parentRun, terminal: terminal}
+// AuthToken gets the token fetched earlier in this session for the given auth profile, if it hasn't expired
+func (s *session) AuthToken(uuid assets.AuthProfileUUID) string {
+	t := s.authTokens[uuid]
+	if t == nil || (!t.expiresOn.IsZero() && !dates.Now().Before(t.expiresOn)) {
+		return ""
+	}
+	return t.token
+}
+
+// SetAuthToken saves a token fetched for the given auth profile for reuse in this session until it expires
+func (s *session) SetAuthToken(uuid assets.AuthProfileUUID, token string, expiresOn time.Time) {
+	if s.authTokens == nil {
+		s.authTokens = make(map[assets.AuthProfileUUID]*authToken)
+	}
+	s.authTokens[uuid] = &authToken{token: token, expiresOn: expiresOn}
+}
+
+func (s *session) PushFlow(flow flows.Flow, parentRun flows.FlowRun, terminal bool, params *types.XObject) {
+	s.pushedFlow = &pushedFlow{flow: flow, parentRun: parentRun, terminal: terminal, params: params}
 }
 
 func (s *session) Runs() []flows.FlowRun { return s.runs }
@@ -168,22 +209,28 @@ func (s *session) Engine() flows.Engine { return s.engine }
 //------------------------------------------------------------------------------------------
 
 // Start initializes this session with the given trigger and runs the flow to the first wait
-func (s *session) start(trigger flows.Trigger) (flows.Sprint, error) {
+func (s *session) start(ctx context.Context, trigger flows.Trigger) (flows.Sprint, error) {
+	ctx, span := s.engine.Tracer().StartSpan(ctx, flows.SpanSprint, map[string]string{flows.SpanAttrSessionUUID: string(s.uuid)})
+	defer span.End()
+
+	s.engine.Metrics().IncCounter(flows.MetricSessionsStarted, nil)
+	defer s.recordSprint(s.countSteps())
+
 	sprint := NewEmptySprint()
 
 	if err := s.prepareForSprint(); err != nil {
 		return sprint, err
 	}
 
-	if err := s.trigger.Initialize(s, sprint.LogEvent); err != nil {
+	if err := s.trigger.Initialize(s, s.eventLogger(sprint, nil, nil)); err != nil {
 		return sprint, err
 	}
 
 	// ensure groups are correct
-	s.ensureQueryBasedGroups(sprint.LogEvent)
+	s.ensureQueryBasedGroups(s.eventLogger(sprint, nil, nil))
 
 	// off to the races...
-	if err := s.continueUntilWait(sprint, nil, noDestination, nil, trigger); err != nil {
+	if err := s.continueUntilWait(ctx, sprint, nil, noDestination, nil, trigger); err != nil {
 		return sprint, err
 	}
 
@@ -192,6 +239,14 @@ func (s *session) start(trigger flows.Trigger) (flows.Sprint, error) {
 
 // Resume tries to resume a waiting session
 func (s *session) Resume(resume flows.Resume) (flows.Sprint, error) {
+	return s.ResumeWithContext(context.Background(), resume)
+}
+
+// ResumeWithContext tries to resume a waiting session, passing the given context to any actions which call services
+func (s *session) ResumeWithContext(ctx context.Context, resume flows.Resume) (flows.Sprint, error) {
+	ctx, span := s.engine.Tracer().StartSpan(ctx, flows.SpanSprint, map[string]string{flows.SpanAttrSessionUUID: string(s.uuid)})
+	defer span.End()
+
 	sprint := NewEmptySprint()
 
 	if err := s.prepareForSprint(); err != nil {
@@ -207,9 +262,12 @@ func (s *session) Resume(resume flows.Resume) (flows.Sprint, error) {
 		return sprint, errors.Errorf("session doesn't contain any runs which are waiting")
 	}
 
-	if err := s.tryToResume(sprint, waitingRun, resume); err != nil {
+	s.engine.Metrics().IncCounter(flows.MetricSessionsResumed, nil)
+	defer s.recordSprint(s.countSteps())
+
+	if err := s.tryToResume(ctx, sprint, waitingRun, resume); err != nil {
 		// if we got an error, add it to the log and shut everything down
-		failure(sprint, waitingRun, nil, err)
+		s.failure(sprint, waitingRun, nil, err)
 
 		s.status = flows.SessionStatusFailed
 	}
@@ -217,8 +275,149 @@ func (s *session) Resume(resume flows.Resume) (flows.Sprint, error) {
 	return sprint, nil
 }
 
+// Continue continues a session which was paused because it exceeded the budget of its last sprint
+func (s *session) Continue() (flows.Sprint, error) {
+	return s.ContinueWithContext(context.Background())
+}
+
+// ContinueWithContext continues a paused session, passing the given context to any actions which call services
+func (s *session) ContinueWithContext(ctx context.Context) (flows.Sprint, error) {
+	ctx, span := s.engine.Tracer().StartSpan(ctx, flows.SpanSprint, map[string]string{flows.SpanAttrSessionUUID: string(s.uuid)})
+	defer span.End()
+
+	sprint := NewEmptySprint()
+
+	if err := s.prepareForSprint(); err != nil {
+		return sprint, err
+	}
+
+	if s.status != flows.SessionStatusPaused {
+		return sprint, errors.Errorf("only paused sessions can be continued")
+	}
+
+	run, err := s.GetRun(s.continuation.RunUUID)
+	if err != nil {
+		return sprint, err
+	}
+	if run.Flow() == nil {
+		return sprint, errors.New("can't continue run with missing flow asset")
+	}
+
+	defer s.recordSprint(s.countSteps())
+
+	destination := s.continuation.NodeUUID
+	step, _, _ := run.PathLocation()
+
+	s.pausedSteps = s.continuation.Steps
+	s.status = flows.SessionStatusActive
+	s.continuation = nil
+
+	// off to the races again...
+	if err := s.continueUntilWait(ctx, sprint, run, destination, step, nil); err != nil {
+		return sprint, err
+	}
+
+	return sprint, nil
+}
+
+// Rewind rewinds this session back to the wait at the given step, discarding all steps, results and events
+// which came after it. Changes made to the contact after that wait are reverted with modifiers which are
+// returned in the sprint.
+func (s *session) Rewind(stepUUID flows.StepUUID) (flows.Sprint, error) {
+	sprint := NewEmptySprint()
+
+	run, step := s.FindStep(stepUUID)
+	if step == nil {
+		return sprint, errors.Errorf("unable to find step with UUID '%s'", stepUUID)
+	}
+
+	// find the event logged when the run began waiting at that step
+	waitIndex := -1
+	for i, e := range run.Events() {
+		if e.StepUUID() == stepUUID && (e.Type() == events.TypeMsgWait || e.Type() == events.TypeDialWait || e.Type() == events.TypeDelayWait) {
+			waitIndex = i
+		}
+	}
+	if waitIndex < 0 {
+		return sprint, errors.Errorf("can't rewind to step '%s' as session didn't wait there", stepUUID)
+	}
+	waitEvent := run.Events()[waitIndex]
+
+	// the waiting run keeps everything up to the wait, and its ancestors keep everything up to where they entered
+	// the next run in the chain
+	cuts := map[flows.FlowRun]runCut{run: {steps: stepIndex(run, stepUUID) + 1, events: waitIndex + 1}}
+	for child, parent := run, run.ParentInSession(); parent != nil; child, parent = parent, parent.ParentInSession() {
+		cuts[parent] = enteredCut(parent, child, s.runs)
+	}
+
+	// runs created before the waiting run which aren't its ancestors had already ended, and runs created after it
+	// are only kept if they were entered from what remains of their parent
+	keptRuns := make([]flows.FlowRun, 0, len(s.runs))
+	keptEvents := make([]flows.Event, 0)
+	afterRun := false
+	for _, r := range s.runs {
+		cut, isCut := cuts[r]
+
+		if isCut {
+			status := flows.RunStatusActive
+			if r == run {
+				status = flows.RunStatusWaiting
+			}
+			r.Rewind(cut.steps, cut.events, status)
+		} else if afterRun {
+			parent := r.ParentInSession()
+			if parent == nil || !isKept(parent, keptRuns) || countEntered(parent.Events()) <= childIndex(parent, r, s.runs) {
+				delete(s.runsByUUID, r.UUID())
+				continue
+			}
+		}
+
+		if r == run {
+			afterRun = true
+		}
+
+		keptRuns = append(keptRuns, r)
+		keptEvents = append(keptEvents, r.Events()...)
+	}
+	sortEvents(keptEvents)
+
+	s.runs = keptRuns
+	s.status = flows.SessionStatusWaiting
+	s.wait = activatedWaitFromEvent(waitEvent)
+	s.currentResume = nil
+	s.input = nil
+
+	// restore the input to the last message received before the wait
+	for _, e := range keptEvents {
+		if typed, ok := e.(*events.MsgReceivedEvent); ok {
+			s.input = inputs.NewMsg(s.assets, &typed.Msg, typed.CreatedOn())
+		}
+	}
+
+	// revert any changes made to the contact after the wait
+	if s.contact != nil {
+		if previous := s.contactAt(keptEvents); previous != nil {
+			logEvent := s.eventLogger(sprint, nil, nil)
+
+			for _, mod := range revertContactModifiers(s.assets, s.contact, previous) {
+				for _, m := range s.engine.Middleware().Modifier(s, mod) {
+					m.Apply(s.env, s.assets, s.contact, logEvent)
+					sprint.LogModifier(m)
+				}
+			}
+
+			// tickets aren't changed by modifiers so they are restored directly
+			s.contact.SetTickets(previous.Tickets())
+		}
+	}
+
+	return sprint, nil
+}
+
 // prepares the session for starting/resuming
 func (s *session) prepareForSprint() error {
+	s.pausedSteps = 0
+
 	if s.parentRun == nil {
 		// if we have a trigger with a parent run, load that
 		triggerWithRun, hasRun := s.trigger.(flows.TriggerWithRun)
@@ -234,7 +433,7 @@ func (s *session) prepareForSprint() error {
 }
 
 // Resume resumes a waiting session
-func (s *session) tryToResume(sprint flows.Sprint, waitingRun flows.FlowRun, resume flows.Resume) error {
+func (s *session) tryToResume(ctx context.Context, sprint flows.Sprint, waitingRun flows.FlowRun, resume flows.Resume) error {
 	// if flow for this run is a missing asset, we have a problem
 	if waitingRun.Flow() == nil {
 		return errors.New("can't resume run with missing flow asset")
@@ -252,17 +451,14 @@ func (s *session) tryToResume(sprint flows.Sprint, waitingRun flows.FlowRun, res
 
 	// try to end our wait which will return and log an error if it can't be ended with this resume
 	if err := node.Router().Wait().End(resume); err != nil {
-		sprint.LogEvent(events.NewError(err))
+		s.eventLogger(sprint, nil, nil)(events.NewError(err))
 		return nil
 	}
 	s.wait = nil
 	s.status = flows.SessionStatusActive
 	s.currentResume = resume
 
-	logEvent := func(e flows.Event) {
-		waitingRun.LogEvent(step, e)
-		sprint.LogEvent(e)
-	}
+	logEvent := s.eventLogger(sprint, waitingRun, step)
 
 	// resumes are allowed to make state changes
 	resume.Apply(waitingRun, logEvent)
@@ -272,17 +468,17 @@ func (s *session) tryToResume(sprint flows.Sprint, waitingRun flows.FlowRun, res
 
 	_, isTimeout := resume.(*resumes.WaitTimeoutResume)
 
-	destination, err := s.findResumeDestination(sprint, waitingRun, isTimeout)
+	destination, err := s.findResumeDestination(ctx, sprint, waitingRun, isTimeout)
 	if err != nil {
 		return err
 	}
 
 	// off to the races again...
-	return s.continueUntilWait(sprint, waitingRun, destination, step, nil)
+	return s.continueUntilWait(ctx, sprint, waitingRun, destination, step, nil)
 }
 
 // finds the next destination in a run that may have been waiting or a parent paused for a child subflow
-func (s *session) findResumeDestination(sprint flows.Sprint, run flows.FlowRun, isTimeout bool) (flows.NodeUUID, error) {
+func (s *session) findResumeDestination(ctx context.Context, sprint flows.Sprint, run flows.FlowRun, isTimeout bool) (flows.NodeUUID, error) {
 	// we might have no immediate destination in this run, but continueUntilWait can resume a parent run
 	if run.Status() != flows.RunStatusActive {
 		return noDestination, nil
@@ -292,13 +488,10 @@ func (s *session) findResumeDestination(sprint flows.Sprint, run flows.FlowRun,
 	if err != nil {
 		return noDestination, err
 	}
-	logEvent := func(e flows.Event) {
-		run.LogEvent(step, e)
-		sprint.LogEvent(e)
-	}
+	logEvent := s.eventLogger(sprint, run, step)
 
 	// see if this node can now pick a destination
-	destination, err := s.pickNodeExit(sprint, run, node, step, isTimeout, logEvent)
+	destination, err := s.pickNodeExit(ctx, sprint, run, node, step, isTimeout, logEvent)
 	if err != nil {
 		return noDestination, err
 	}
@@ -307,8 +500,13 @@ func (s *session) findResumeDestination(sprint flows.Sprint, run flows.FlowRun,
 }
 
 // the main flow execution loop
-func (s *session) continueUntilWait(sprint flows.Sprint, currentRun flows.FlowRun, destination flows.NodeUUID, step flows.Step, trigger flows.Trigger) (err error) {
-	numNewSteps := 0
+func (s *session) continueUntilWait(ctx context.Context, sprint flows.Sprint, currentRun flows.FlowRun, destination flows.NodeUUID, step flows.Step, trigger flows.Trigger) (err error) {
+	s.numSteps = 0
+
+	var startedOn time.Time
+	if s.Engine().MaxSprintDuration() > 0 {
+		startedOn = dates.Now()
+	}
 
 	for {
 		// if we have a flow trigger handle that first to find our destination in the new flow
@@ -322,7 +520,7 @@ func (s *session) continueUntilWait(sprint flows.Sprint, currentRun flows.FlowRu
 
 			// create a new run for it
 			flow := s.pushedFlow.flow
-			currentRun = runs.NewRun(s, s.pushedFlow.flow, currentRun)
+			currentRun = runs.NewRun(s, s.pushedFlow.flow, currentRun, s.pushedFlow.params)
 			s.addRun(currentRun)
 
 			// our destination is the first node in that flow... if such a node exists
@@ -356,13 +554,17 @@ func (s *session) continueUntilWait(sprint flows.Sprint, currentRun flows.FlowRu
 						return errors.New("can't resume parent run with missing flow asset")
 					}
 
-					if destination, err = s.findResumeDestination(sprint, currentRun, false); err != nil {
-						failure(sprint, currentRun, step, errors.Wrapf(err, "can't resume run as node no longer exists"))
+					if childRun.Status() == flows.RunStatusCompleted {
+						s.returnOutputs(sprint, childRun, currentRun)
+					}
+
+					if destination, err = s.findResumeDestination(ctx, sprint, currentRun, false); err != nil {
+						s.failure(sprint, currentRun, step, errors.Wrapf(err, "can't resume run as node no longer exists"))
 					}
 				} else {
 					// if we did fail then that needs to bubble back up through the run hierarchy
 					step, _, _ := currentRun.PathLocation()
-					failure(sprint, currentRun, step, errors.Errorf("child run for flow '%s' ended in error, ending execution", childRun.Flow().UUID()))
+					s.failure(sprint, currentRun, step, errors.Errorf("child run for flow '%s' ended in error, ending execution", childRun.Flow().UUID()))
 				}
 
 			} else {
@@ -380,11 +582,22 @@ func (s *session) continueUntilWait(sprint flows.Sprint, currentRun flows.FlowRu
 
 		// if we now have a destination, go there
 		if destination != noDestination {
-			numNewSteps++
+			s.numSteps++
 
-			if numNewSteps > s.Engine().MaxStepsPerSprint() {
+			// a session which keeps pausing without ever waiting is usually also a sign of a loop, but that only applies
+			// to sessions which can pause or have been continued after pausing
+			mayPause := s.Engine().PauseOnStepLimit() || s.Engine().MaxSprintDuration() > 0 || s.pausedSteps > 0
+			canPause := !mayPause || s.pausedSteps+s.numSteps <= s.Engine().MaxPausedSteps()
+
+			if canPause && s.numSteps > s.Engine().MaxStepsPerSprint() && s.Engine().PauseOnStepLimit() {
+				s.pause(currentRun, destination, s.pausedSteps+s.numSteps-1)
+				return nil
+			} else if canPause && s.numSteps > 1 && s.Engine().MaxSprintDuration() > 0 && dates.Now().Sub(startedOn) > s.Engine().MaxSprintDuration() {
+				s.pause(currentRun, destination, s.pausedSteps+s.numSteps-1)
+				return nil
+			} else if s.numSteps > s.Engine().MaxStepsPerSprint() || !canPause {
 				// we've hit the step limit - usually a sign of a loop
-				failure(sprint, currentRun, step, errors.Errorf("step limit exceeded, stopping execution before entering '%s'", destination))
+				s.failure(sprint, currentRun, step, errors.Errorf("step limit exceeded, stopping execution before entering '%s'", destination))
 				destination = noDestination
 			} else {
 				node := currentRun.Flow().GetNode(destination)
@@ -392,7 +605,7 @@ func (s *session) continueUntilWait(sprint flows.Sprint, currentRun flows.FlowRu
 					return errors.Errorf("unable to find destination node %s in flow %s", destination, currentRun.Flow().UUID())
 				}
 
-				step, destination, err = s.visitNode(sprint, currentRun, node, trigger)
+				step, destination, err = s.visitNode(ctx, sprint, currentRun, node, trigger)
 				if err != nil {
 					return err
 				}
@@ -409,13 +622,41 @@ func (s *session) continueUntilWait(sprint flows.Sprint, currentRun flows.FlowRu
 	}
 }
 
+// executes the given action, passing on the context if the action can use it
+func (s *session) executeAction(ctx context.Context, action flows.Action, run flows.FlowRun, node flows.Node, step flows.Step, logModifier flows.ModifierCallback, logEvent flows.EventCallback) error {
+	attrs := nodeSpanAttrs(run, node)
+	attrs[flows.SpanAttrActionType] = action.Type()
+	attrs[flows.SpanAttrActionUUID] = string(action.UUID())
+
+	ctx, span := s.engine.Tracer().StartSpan(ctx, flows.SpanExecuteAction, attrs)
+	defer span.End()
+
+	// record spans for any HTTP calls made by services that this action uses
+	tracer, logToStep := s.engine.Tracer(), logEvent
+	logEvent = func(e flows.Event) {
+		traceHTTP(ctx, tracer, e, attrs)
+		logToStep(e)
+	}
+
+	if ca, ok := action.(flows.ContextAction); ok {
+		return ca.ExecuteWithContext(ctx, run, step, logModifier, logEvent)
+	}
+	return action.Execute(run, step, logModifier, logEvent)
+}
+
+// pauses this session so that it can be continued later from the given destination in the given run
+func (s *session) pause(run flows.FlowRun, destination flows.NodeUUID, steps int) {
+	s.status = flows.SessionStatusPaused
+	s.continuation = &continuation{RunUUID: run.UUID(), NodeUUID: destination, Steps: steps}
+}
+
 // visits the given node, creating a step in our current run path
-func (s *session) visitNode(sprint flows.Sprint, run flows.FlowRun, node flows.Node, trigger flows.Trigger) (flows.Step, flows.NodeUUID, error) {
+func (s *session) visitNode(ctx context.Context, sprint flows.Sprint, run flows.FlowRun, node flows.Node, trigger flows.Trigger) (flows.Step, flows.NodeUUID, error) {
+	ctx, span := s.engine.Tracer().StartSpan(ctx, flows.SpanVisitNode, nodeSpanAttrs(run, node))
+	defer span.End()
+
 	step := run.CreateStep(node)
-	logEvent := func(e flows.Event) {
-		run.LogEvent(step, e)
-		sprint.LogEvent(e)
-	}
+	logEvent := s.eventLogger(sprint, run, step)
 
 	// this might be the first run of the session in which case a trigger might need to initialize the run
 	if trigger != nil {
@@ -427,7 +668,7 @@ func (s *session) visitNode(sprint flows.Sprint, run flows.FlowRun, node flows.N
 	// execute our node's actions
 	if node.Actions() != nil {
 		for _, action := range node.Actions() {
-			if err := action.Execute(run, step, sprint.LogModifier, logEvent); err != nil {
+			if err := s.executeAction(ctx, action, run, node, step, sprint.LogModifier, logEvent); err != nil {
 				return step, noDestination, errors.Wrapf(err, "error executing action[type=%s,uuid=%s]", action.Type(), action.UUID())
 			}
 
@@ -450,10 +691,12 @@ func (s *session) visitNode(sprint flows.Sprint, run flows.FlowRun, node flows.N
 		wait = node.Router().Wait()
 	}
 
+	isTimeout := false
+
 	if wait != nil {
 
 		// waits have the option to skip themselves
-		activatedWait := wait.Begin(run, logEvent)
+		activatedWait, timedOut := wait.Begin(run, logEvent)
 		if activatedWait != nil {
 			// mark ouselves as waiting and hand back to
 			run.SetStatus(flows.RunStatusWaiting)
@@ -462,15 +705,30 @@ func (s *session) visitNode(sprint flows.Sprint, run flows.FlowRun, node flows.N
 
 			return step, noDestination, nil
 		}
+
+		// a skipped wait may have timed out immediately, e.g. a delay which is already over
+		if timedOut {
+			logEvent(events.NewWaitTimedOut())
+			isTimeout = true
+		}
+	}
+
+	// a fork router sends us down each of its branches until they join again
+	if fork, isFork := node.Router().(*routers.ForkRouter); isFork {
+		destinationUUID, err := s.fork(ctx, sprint, run, node, step, fork)
+		return step, destinationUUID, err
 	}
 
 	// use our node's router to determine where to go next
-	destinationUUID, err := s.pickNodeExit(sprint, run, node, step, false, logEvent)
+	destinationUUID, err := s.pickNodeExit(ctx, sprint, run, node, step, isTimeout, logEvent)
 	return step, destinationUUID, err
 }
 
 // picks the exit to use on the given node
-func (s *session) pickNodeExit(sprint flows.Sprint, run flows.FlowRun, node flows.Node, step flows.Step, isTimeout bool, logEvent flows.EventCallback) (flows.NodeUUID, error) {
+func (s *session) pickNodeExit(ctx context.Context, sprint flows.Sprint, run flows.FlowRun, node flows.Node, step flows.Step, isTimeout bool, logEvent flows.EventCallback) (flows.NodeUUID, error) {
+	_, span := s.engine.Tracer().StartSpan(ctx, flows.SpanPickNodeExit, nodeSpanAttrs(run, node))
+	defer span.End()
+
 	var exitUUID flows.ExitUUID
 	var err error
 
@@ -486,7 +744,7 @@ func (s *session) pickNodeExit(sprint flows.Sprint, run flows.FlowRun, node flow
 		}
 		// router didn't error.. but it failed to pick a category
 		if exitUUID == "" {
-			failure(sprint, run, step, errors.Errorf("router on node[uuid=%s] failed to pick a category", node.UUID()))
+			s.failure(sprint, run, step, errors.Errorf("router on node[uuid=%s] failed to pick a category", node.UUID()))
 			return noDestination, nil
 		}
 	} else if len(node.Exits()) > 0 {
@@ -523,14 +781,69 @@ func (s *session) ensureQueryBasedGroups(logEvent flows.EventCallback) {
 
 const noDestination = flows.NodeUUID("")
 
+// saves the declared outputs of a completed child run as results on its parent
+func (s *session) returnOutputs(sprint flows.Sprint, child flows.FlowRun, parent flows.FlowRun) {
+	step, _, _ := parent.PathLocation()
+	logEvent := s.eventLogger(sprint, parent, step)
+
+	for _, output := range child.Flow().Outputs() {
+		value := child.Results().Get(output.Key)
+		if value == nil {
+			continue
+		}
+
+		if _, err := output.Type.Convert(parent.Environment(), types.NewXText(value.Value)); err != nil {
+			logEvent(events.NewErrorf("value '%s' of output '%s' isn't a valid %s", value.Value, output.Key, output.Type))
+			continue
+		}
+
+		result := flows.NewResult(output.Name, value.Value, value.Category, value.CategoryLocalized, step.NodeUUID(), value.Input, value.Extra, dates.Now())
+		parent.SaveResult(result)
+		logEvent(events.NewRunResultChanged(result))
+	}
+}
+
 // utility to fail the session and log a failure event
-func failure(sprint flows.Sprint, run flows.FlowRun, step flows.Step, err error) {
+func (s *session) failure(sprint flows.Sprint, run flows.FlowRun, step flows.Step, err error) {
 	event := events.NewFailure(err)
 	if run != nil {
 		run.Exit(flows.RunStatusFailed)
-		run.LogEvent(step, event)
 	}
-	sprint.LogEvent(event)
+	s.eventLogger(sprint, run, step)(event)
+}
+
+// counts the total number of steps in all runs of this session
+func (s *session) countSteps() int {
+	count := 0
+	for _, run := range s.runs {
+		count += len(run.Path())
+	}
+	return count
+}
+
+// reports metrics for a sprint which began when this session had the given number of steps
+func (s *session) recordSprint(stepsBefore int) {
+	s.engine.Metrics().IncCounter(flows.MetricSessionStatuses, map[string]string{"status": string(s.status)})
+	s.engine.Metrics().ObserveHistogram(flows.MetricSprintSteps, float64(s.countSteps()-stepsBefore), nil)
+}
+
+// creates an event callback which passes events through the engine middleware before logging them to the
+// given sprint, and to the given run if there is one
+func (s *session) eventLogger(sprint flows.Sprint, run flows.FlowRun, step flows.Step) flows.EventCallback {
+	return func(e flows.Event) {
+		if step != nil {
+			e.SetStepUUID(step.UUID())
+		}
+
+		for _, evt := range s.engine.Middleware().Event(s, e) {
+			s.engine.Metrics().IncCounter(flows.MetricEvents, map[string]string{"type": evt.Type()})
+
+			if run != nil {
+				run.LogEvent(step, evt)
+			}
+			sprint.LogEvent(evt)
+		}
+	}
 }
 
 //------------------------------------------------------------------------------------------
@@ -538,15 +851,16 @@ func failure(sprint flows.Sprint, run flows.FlowRun, step flows.Step, err error)
 //------------------------------------------------------------------------------------------
 
 type sessionEnvelope struct {
-	UUID        flows.SessionUUID   `json:"uuid"` // TODO validate:"required"`
-	Type        flows.FlowType      `json:"type"` // TODO validate:"required"`
-	Environment json.RawMessage     `json:"environment"`
-	Trigger     json.RawMessage     `json:"trigger" validate:"required"`
-	Contact     *json.RawMessage    `json:"contact,omitempty"`
-	Runs        []json.RawMessage   `json:"runs"`
-	Status      flows.SessionStatus `json:"status" validate:"required"`
-	Wait        json.RawMessage     `json:"wait,omitempty"`
-	Input       json.RawMessage     `json:"input,omitempty" validate:"omitempty"`
+	UUID         flows.SessionUUID   `json:"uuid"` // TODO validate:"required"`
+	Type         flows.FlowType      `json:"type"` // TODO validate:"required"`
+	Environment  json.RawMessage     `json:"environment"`
+	Trigger      json.RawMessage     `json:"trigger" validate:"required"`
+	Contact      *json.RawMessage    `json:"contact,omitempty"`
+	Runs         []json.RawMessage   `json:"runs"`
+	Status       flows.SessionStatus `json:"status" validate:"required"`
+	Wait         json.RawMessage     `json:"wait,omitempty"`
+	Continuation *continuation       `json:"continuation,omitempty"`
+	Input        json.RawMessage     `json:"input,omitempty" validate:"omitempty"`
 }
 
 // ReadSession decodes a session from the passed in JSON
@@ -554,10 +868,23 @@ func readSession(eng flows.Engine, sessionAssets flows.SessionAssets, data json.
 	e := &sessionEnvelope{}
 	var err error
 
+	if isCompactSession(data) {
+		if data, err = DecodeCompactSession(data); err != nil {
+			return nil, err
+		}
+	}
+
 	if err = utils.UnmarshalAndValidate(data, e); err != nil {
 		return nil, errors.Wrap(err, "unable to read session")
 	}
 
+	kp := eng.KeyProvider()
+	if kp != nil {
+		if err = decryptSessionPII(e, kp); err != nil {
+			return nil, errors.Wrap(err, "unable to decrypt session")
+		}
+	}
+
 	s := &session{
 		engine:     eng,
 		assets:     sessionAssets,
@@ -582,7 +909,7 @@ func readSession(eng flows.Engine, sessionAssets flows.SessionAssets, data json.
 
 	// read our contact
 	if e.Contact != nil {
-		if s.contact, err = flows.ReadContact(s.Assets(), *e.Contact, missing); err != nil {
+		if s.contact, err = flows.ReadEncryptedContact(s.Assets(), *e.Contact, kp, missing); err != nil {
 			return nil, errors.Wrap(err, "unable to read contact")
 		}
 	}
@@ -614,6 +941,10 @@ func readSession(eng flows.Engine, sessionAssets flows.SessionAssets, data json.
 	if s.status == flows.SessionStatusWaiting && s.wait == nil {
 		return nil, errors.Errorf("session has status of \"waiting\" but no wait object")
 	}
+	if s.status == flows.SessionStatusPaused && e.Continuation == nil {
+		return nil, errors.Errorf("session has status of \"paused\" but no continuation")
+	}
+	s.continuation = e.Continuation
 
 	return s, nil
 }
@@ -621,18 +952,20 @@ func readSession(eng flows.Engine, sessionAssets flows.SessionAssets, data json.
 // MarshalJSON marshals this session into JSON
 func (s *session) MarshalJSON() ([]byte, error) {
 	e := &sessionEnvelope{
-		UUID:   s.uuid,
-		Type:   s.type_,
-		Status: s.status,
+		UUID:         s.uuid,
+		Type:         s.type_,
+		Status:       s.status,
+		Continuation: s.continuation,
 	}
 	var err error
+	kp := s.engine.KeyProvider()
 
 	if e.Environment, err = jsonx.Marshal(s.env); err != nil {
 		return nil, err
 	}
 	if s.contact != nil {
 		var contactJSON json.RawMessage
-		contactJSON, err = jsonx.Marshal(s.contact)
+		contactJSON, err = s.contact.MarshalEncrypted(kp)
 		if err != nil {
 			return nil, err
 		}
@@ -663,5 +996,11 @@ func (s *session) MarshalJSON() ([]byte, error) {
 		}
 	}
 
+	if kp != nil {
+		if err = encryptSessionPII(e, kp); err != nil {
+			return nil, errors.Wrap(err, "unable to encrypt session")
+		}
+	}
+
 	return jsonx.Marshal(e)
 }
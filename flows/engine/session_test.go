@@ -1,14 +1,19 @@
 package engine_test
 
 import (
+	"context"
 	"encoding/json"
+	"net/http"
 	"os"
 	"sort"
 	"testing"
 	"time"
 
+	"github.com/buger/jsonparser"
 	"github.com/nyaruka/gocommon/dates"
+	"github.com/nyaruka/gocommon/httpx"
 	"github.com/nyaruka/gocommon/jsonx"
+	"github.com/nyaruka/gocommon/urns"
 	"github.com/nyaruka/gocommon/uuids"
 	"github.com/nyaruka/goflow/assets"
 	"github.com/nyaruka/goflow/assets/static"
@@ -399,3 +404,285 @@ func TestFindStep(t *testing.T) {
 	assert.Nil(t, run)
 	assert.Nil(t, step)
 }
+
+func TestRewind(t *testing.T) {
+	defer dates.SetNowSource(dates.DefaultNowSource)
+
+	dates.SetNowSource(dates.NewSequentialNowSource(time.Date(2018, 4, 11, 13, 24, 30, 123456000, time.UTC)))
+
+	assetsJSON, err := os.ReadFile("../../test/testdata/runner/two_questions.json")
+	require.NoError(t, err)
+
+	session, sprint, err := test.CreateSession(assetsJSON, assets.FlowUUID("615b8a0f-588c-4d20-a05f-363b0b4ce6f4"))
+	require.NoError(t, err)
+
+	run := session.Runs()[0]
+	firstWait := sprint.Events()[len(sprint.Events())-1]
+	assert.Equal(t, "msg_wait", firstWait.Type())
+
+	// answer the first question which changes the contact language and waits again
+	session, _, err = test.ResumeSession(session, assetsJSON, "RED")
+	require.NoError(t, err)
+
+	run = session.Runs()[0]
+	assert.Equal(t, flows.SessionStatusWaiting, session.Status())
+	assert.Equal(t, 2, len(run.Path()))
+	assert.Equal(t, "RED", run.Results().Get("favorite_color").Value)
+	assert.Equal(t, envs.Language("fra"), session.Contact().Language())
+
+	// can't rewind to a step which doesn't exist or isn't a wait
+	_, err = session.Rewind(flows.StepUUID("4f33917a-d562-4c20-88bd-f1a4c6827848"))
+	assert.EqualError(t, err, "unable to find step with UUID '4f33917a-d562-4c20-88bd-f1a4c6827848'")
+
+	// rewind back to the first question
+	sprint, err = session.Rewind(firstWait.StepUUID())
+	require.NoError(t, err)
+
+	assert.Equal(t, flows.SessionStatusWaiting, session.Status())
+	assert.Equal(t, "msg", session.Wait().Type())
+	assert.Equal(t, 600, *session.Wait().TimeoutSeconds())
+	assert.Nil(t, session.Input())
+	assert.Equal(t, flows.RunStatusWaiting, run.Status())
+	assert.Equal(t, 1, len(run.Path()))
+	assert.Nil(t, run.Results().Get("favorite_color"))
+	assert.Equal(t, firstWait, run.Events()[len(run.Events())-1])
+
+	// contact language change has been reverted
+	assert.Equal(t, envs.NilLanguage, session.Contact().Language())
+	require.Equal(t, 1, len(sprint.Modifiers()))
+	assert.Equal(t, "language", sprint.Modifiers()[0].Type())
+	require.Equal(t, 1, len(sprint.Events()))
+	assert.Equal(t, "contact_language_changed", sprint.Events()[0].Type())
+
+	// and we can resume again with a different answer
+	session, _, err = test.ResumeSession(session, assetsJSON, "blue")
+	require.NoError(t, err)
+
+	run = session.Runs()[0]
+	assert.Equal(t, 2, len(run.Path()))
+	assert.Equal(t, "blue", run.Results().Get("favorite_color").Value)
+}
+
+func TestRewindWithSameTimestamps(t *testing.T) {
+	defer dates.SetNowSource(dates.DefaultNowSource)
+
+	// every step and event has the same timestamp so rewinding can only rely on their order
+	dates.SetNowSource(dates.NewFixedNowSource(time.Date(2018, 4, 11, 13, 24, 30, 123456000, time.UTC)))
+
+	assetsJSON, err := os.ReadFile("../../test/testdata/runner/two_questions.json")
+	require.NoError(t, err)
+
+	session, sprint, err := test.CreateSession(assetsJSON, assets.FlowUUID("615b8a0f-588c-4d20-a05f-363b0b4ce6f4"))
+	require.NoError(t, err)
+
+	firstWait := sprint.Events()[len(sprint.Events())-1]
+	numEvents := len(session.Runs()[0].Events())
+
+	session, _, err = test.ResumeSession(session, assetsJSON, "RED")
+	require.NoError(t, err)
+
+	run := session.Runs()[0]
+	assert.Equal(t, 2, len(run.Path()))
+	assert.Greater(t, len(run.Events()), numEvents)
+
+	_, err = session.Rewind(firstWait.StepUUID())
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, len(run.Path()))
+	assert.Equal(t, numEvents, len(run.Events()))
+	assert.Equal(t, firstWait, run.Events()[numEvents-1])
+	assert.Nil(t, run.Results().Get("favorite_color"))
+	assert.Equal(t, envs.NilLanguage, session.Contact().Language())
+}
+
+func TestRewindWithSubflows(t *testing.T) {
+	defer dates.SetNowSource(dates.DefaultNowSource)
+
+	dates.SetNowSource(dates.NewFixedNowSource(time.Date(2018, 4, 11, 13, 24, 30, 123456000, time.UTC)))
+
+	assetsJSON, err := os.ReadFile("../../test/testdata/runner/subflow_loop_with_wait.json")
+	require.NoError(t, err)
+
+	session, sprint, err := test.CreateSession(assetsJSON, assets.FlowUUID("76f0a02f-3b75-4b86-9064-e9195e1b3a02"))
+	require.NoError(t, err)
+	require.Equal(t, 2, len(session.Runs()))
+
+	firstWait := sprint.Events()[len(sprint.Events())-1]
+	numParentSteps, numParentEvents := len(session.Runs()[0].Path()), len(session.Runs()[0].Events())
+
+	// answering the question enters the child flow again from the child run
+	session, _, err = test.ResumeSession(session, assetsJSON, "Ben")
+	require.NoError(t, err)
+	require.Equal(t, 3, len(session.Runs()))
+
+	parent, child := session.Runs()[0], session.Runs()[1]
+
+	_, err = session.Rewind(firstWait.StepUUID())
+	require.NoError(t, err)
+
+	// the run entered after the wait is removed, and the others are truncated
+	assert.Equal(t, []flows.FlowRun{parent, child}, session.Runs())
+	assert.Equal(t, flows.RunStatusActive, parent.Status())
+	assert.Equal(t, numParentSteps, len(parent.Path()))
+	assert.Equal(t, numParentEvents, len(parent.Events()))
+	assert.Equal(t, flows.RunStatusWaiting, child.Status())
+	assert.Equal(t, 1, len(child.Path()))
+	assert.Equal(t, firstWait, child.Events()[len(child.Events())-1])
+}
+
+func TestPauseAndContinue(t *testing.T) {
+	defer dates.SetNowSource(dates.DefaultNowSource)
+
+	dates.SetNowSource(dates.NewSequentialNowSource(time.Date(2018, 4, 11, 13, 24, 30, 123456000, time.UTC)))
+
+	assetsJSON, err := os.ReadFile("../../test/testdata/runner/node_loop.json")
+	require.NoError(t, err)
+
+	sa, err := test.CreateSessionAssets(assetsJSON, "")
+	require.NoError(t, err)
+
+	env := envs.NewBuilder().Build()
+	contact := flows.NewEmptyContact(sa, "Bob", envs.NilLanguage, nil)
+	trigger := triggers.NewBuilder(env, assets.NewFlowReference("25a2d8b2-ae7c-4fed-964a-506fb8c3f0c0", "Node Loop"), contact).Manual().Build()
+
+	// hitting the step limit pauses the session
+	eng := engine.NewBuilder().WithMaxStepsPerSprint(5).WithPauseOnStepLimit(true).Build()
+
+	session, sprint, err := eng.NewSession(sa, trigger)
+	require.NoError(t, err)
+
+	assert.Equal(t, flows.SessionStatusPaused, session.Status())
+	assert.Nil(t, session.Wait())
+	assert.Equal(t, flows.RunStatusActive, session.Runs()[0].Status())
+	assert.Equal(t, 5, len(session.Runs()[0].Path()))
+	assert.Equal(t, 5, len(sprint.Events()))
+
+	_, err = session.Resume(resumes.NewWaitTimeout(nil, nil))
+	assert.EqualError(t, err, "only waiting sessions can be resumed")
+
+	// paused session can be saved and read back
+	sessionJSON, err := jsonx.Marshal(session)
+	require.NoError(t, err)
+	continuationJSON, _, _, err := jsonparser.Get(sessionJSON, "continuation")
+	require.NoError(t, err)
+	test.AssertEqualJSON(t, []byte(`{"run_uuid": "`+string(session.Runs()[0].UUID())+`", "node_uuid": "32bc60ad-5c86-465e-a6b8-049c44ecce49", "steps": 5}`), continuationJSON, "continuation mismatch")
+
+	session, err = eng.ReadSession(sa, sessionJSON, assets.PanicOnMissing)
+	require.NoError(t, err)
+
+	// and then continued for another sprint
+	sprint, err = session.Continue()
+	require.NoError(t, err)
+
+	assert.Equal(t, flows.SessionStatusPaused, session.Status())
+	assert.Equal(t, 10, len(session.Runs()[0].Path()))
+	assert.Equal(t, 5, len(sprint.Events()))
+
+	// but a session which never waits still fails once it exceeds the limit of steps across its continuations
+	eng = engine.NewBuilder().WithMaxStepsPerSprint(5).WithPauseOnStepLimit(true).WithMaxPausedSteps(12).Build()
+
+	session, _, err = eng.NewSession(sa, trigger)
+	require.NoError(t, err)
+	assert.Equal(t, flows.SessionStatusPaused, session.Status())
+
+	_, err = session.Continue()
+	require.NoError(t, err)
+	assert.Equal(t, flows.SessionStatusPaused, session.Status())
+
+	sprint, err = session.Continue()
+	require.NoError(t, err)
+
+	assert.Equal(t, flows.SessionStatusFailed, session.Status())
+	assert.Equal(t, 12, len(session.Runs()[0].Path()))
+	assert.Equal(t, "failure", sprint.Events()[len(sprint.Events())-1].Type())
+
+	// paused session must have a continuation
+	_, err = eng.ReadSession(sa, test.JSONDelete(sessionJSON, []string{"continuation"}), assets.PanicOnMissing)
+	assert.EqualError(t, err, `session has status of "paused" but no continuation`)
+
+	// exceeding the time budget also pauses the session
+	eng = engine.NewBuilder().WithMaxSprintDuration(10 * time.Second).Build()
+
+	session, _, err = eng.NewSession(sa, trigger)
+	require.NoError(t, err)
+
+	assert.Equal(t, flows.SessionStatusPaused, session.Status())
+	assert.Equal(t, 2, len(session.Runs()[0].Path()))
+
+	// without pausing, hitting the step limit still fails the session
+	eng = engine.NewBuilder().WithMaxStepsPerSprint(5).Build()
+
+	session, _, err = eng.NewSession(sa, trigger)
+	require.NoError(t, err)
+
+	assert.Equal(t, flows.SessionStatusFailed, session.Status())
+
+	_, err = session.Continue()
+	assert.EqualError(t, err, "only paused sessions can be continued")
+
+	// and the limit of steps across continuations doesn't apply to sessions which can't pause
+	eng = engine.NewBuilder().WithMaxStepsPerSprint(20).WithMaxPausedSteps(12).Build()
+
+	session, _, err = eng.NewSession(sa, trigger)
+	require.NoError(t, err)
+
+	assert.Equal(t, flows.SessionStatusFailed, session.Status())
+	assert.Equal(t, 20, len(session.Runs()[0].Path()))
+}
+
+type contextKey string
+
+// a webhook service which records the context values of calls made to it
+type contextWebhookService struct {
+	values []interface{}
+}
+
+func (s *contextWebhookService) Call(session flows.Session, request *http.Request) (*flows.WebhookCall, error) {
+	return s.CallWithContext(context.Background(), session, request)
+}
+
+func (s *contextWebhookService) CallWithContext(ctx context.Context, session flows.Session, request *http.Request) (*flows.WebhookCall, error) {
+	s.values = append(s.values, ctx.Value(contextKey("worker")))
+
+	return &flows.WebhookCall{Trace: &httpx.Trace{Request: request, RequestTrace: []byte("POST / HTTP/1.1\r\n\r\n"), StartTime: dates.Now(), EndTime: dates.Now()}}, nil
+}
+
+func TestContextPropagation(t *testing.T) {
+	assetsJSON, err := os.ReadFile("../../test/testdata/runner/two_questions.json")
+	require.NoError(t, err)
+
+	sa, err := test.CreateSessionAssets(assetsJSON, "")
+	require.NoError(t, err)
+
+	flow, err := sa.Flows().Get(assets.FlowUUID("615b8a0f-588c-4d20-a05f-363b0b4ce6f4"))
+	require.NoError(t, err)
+
+	svc := &contextWebhookService{}
+	eng := engine.NewBuilder().
+		WithWebhookServiceFactory(func(flows.Session) (flows.WebhookService, error) { return svc, nil }).
+		Build()
+
+	env := envs.NewBuilder().Build()
+	contact := flows.NewEmptyContact(sa, "Bob", envs.NilLanguage, nil)
+	trigger := triggers.NewBuilder(env, flow.Reference(), contact).Manual().Build()
+
+	ctx := context.WithValue(context.Background(), contextKey("worker"), "w1")
+
+	session, _, err := eng.NewSessionWithContext(ctx, sa, trigger)
+	require.NoError(t, err)
+
+	resumeWithText := func(ctx context.Context, text string) {
+		msg := flows.NewMsgIn(flows.MsgUUID(uuids.New()), urns.NilURN, nil, text, nil)
+		_, err := session.ResumeWithContext(ctx, resumes.NewMsg(env, session.Contact(), msg))
+		require.NoError(t, err)
+	}
+
+	resumeWithText(ctx, "red")
+	assert.Equal(t, flows.SessionStatusWaiting, session.Status())
+	assert.Equal(t, 0, len(svc.values))
+
+	// answering the second question takes us to the webhook which should be called with our context
+	resumeWithText(context.WithValue(context.Background(), contextKey("worker"), "w2"), "coke")
+
+	assert.Equal(t, []interface{}{"w2"}, svc.values)
+}
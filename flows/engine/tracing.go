@@ -0,0 +1,150 @@
+package engine
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/nyaruka/gocommon/dates"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/events"
+)
+
+// the default tracer which doesn't record anything
+type noopTracer struct{}
+
+func (t noopTracer) StartSpan(ctx context.Context, name string, attrs map[string]string) (context.Context, flows.Span) {
+	return ctx, noopSpan{}
+}
+
+func (t noopTracer) RecordSpan(ctx context.Context, name string, start, end time.Time, attrs map[string]string) {
+}
+
+type noopSpan struct{}
+
+func (s noopSpan) SetAttribute(key, value string) {}
+func (s noopSpan) End()                           {}
+
+var _ flows.Tracer = noopTracer{}
+
+// RecordedSpan is a span recorded by a span recorder
+type RecordedSpan struct {
+	ID        int
+	ParentID  int
+	Name      string
+	Attrs     map[string]string
+	StartedOn time.Time
+	EndedOn   time.Time
+}
+
+type spanContextKey struct{}
+
+// SpanRecorder is a tracer which records spans in memory, e.g. for testing
+type SpanRecorder struct {
+	mutex sync.Mutex
+	spans []*RecordedSpan
+}
+
+// NewSpanRecorder creates a new span recorder
+func NewSpanRecorder() *SpanRecorder {
+	return &SpanRecorder{}
+}
+
+// StartSpan starts a new span as a child of any span in the given context
+func (r *SpanRecorder) StartSpan(ctx context.Context, name string, attrs map[string]string) (context.Context, flows.Span) {
+	span := r.add(ctx, name, dates.Now(), attrs)
+
+	return context.WithValue(ctx, spanContextKey{}, span), &recordingSpan{recorder: r, span: span}
+}
+
+// RecordSpan records a completed span as a child of any span in the given context
+func (r *SpanRecorder) RecordSpan(ctx context.Context, name string, start, end time.Time, attrs map[string]string) {
+	span := r.add(ctx, name, start, attrs)
+
+	r.mutex.Lock()
+	span.EndedOn = end
+	r.mutex.Unlock()
+}
+
+// Spans returns the spans recorded so far in the order they were started
+func (r *SpanRecorder) Spans() []*RecordedSpan {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	spans := make([]*RecordedSpan, len(r.spans))
+	copy(spans, r.spans)
+	return spans
+}
+
+func (r *SpanRecorder) add(ctx context.Context, name string, start time.Time, attrs map[string]string) *RecordedSpan {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	span := &RecordedSpan{ID: len(r.spans) + 1, Name: name, Attrs: make(map[string]string, len(attrs)), StartedOn: start}
+	for k, v := range attrs {
+		span.Attrs[k] = v
+	}
+	if parent, ok := ctx.Value(spanContextKey{}).(*RecordedSpan); ok {
+		span.ParentID = parent.ID
+	}
+
+	r.spans = append(r.spans, span)
+	return span
+}
+
+type recordingSpan struct {
+	recorder *SpanRecorder
+	span     *RecordedSpan
+}
+
+func (s *recordingSpan) SetAttribute(key, value string) {
+	s.recorder.mutex.Lock()
+	s.span.Attrs[key] = value
+	s.recorder.mutex.Unlock()
+}
+
+func (s *recordingSpan) End() {
+	s.recorder.mutex.Lock()
+	s.span.EndedOn = dates.Now()
+	s.recorder.mutex.Unlock()
+}
+
+var _ flows.Tracer = (*SpanRecorder)(nil)
+
+// creates the attributes for spans within the given node
+func nodeSpanAttrs(run flows.FlowRun, node flows.Node) map[string]string {
+	return map[string]string{
+		flows.SpanAttrSessionUUID: string(run.Session().UUID()),
+		flows.SpanAttrFlowUUID:    string(run.FlowReference().UUID),
+		flows.SpanAttrNodeUUID:    string(node.UUID()),
+	}
+}
+
+// records spans for any HTTP calls to services which are included in the given event
+func traceHTTP(ctx context.Context, tracer flows.Tracer, event flows.Event, attrs map[string]string) {
+	var logs []*flows.HTTPLog
+
+	switch typed := event.(type) {
+	case *events.ServiceCalledEvent:
+		logs = typed.HTTPLogs
+	case *events.AirtimeTransferredEvent:
+		logs = typed.HTTPLogs
+	case *events.WebhookCalledEvent:
+		// webhook events are created once the call has completed
+		start := typed.CreatedOn().Add(-time.Duration(typed.ElapsedMS) * time.Millisecond)
+		logs = []*flows.HTTPLog{{HTTPTrace: typed.HTTPTrace, CreatedOn: start}}
+	}
+
+	for _, log := range logs {
+		logAttrs := make(map[string]string, len(attrs)+3)
+		for k, v := range attrs {
+			logAttrs[k] = v
+		}
+		logAttrs[flows.SpanAttrURL] = log.URL
+		logAttrs[flows.SpanAttrStatus] = string(log.Status)
+		logAttrs[flows.SpanAttrStatusCode] = strconv.Itoa(log.StatusCode)
+
+		tracer.RecordSpan(ctx, flows.SpanHTTP, log.CreatedOn, log.CreatedOn.Add(time.Duration(log.ElapsedMS)*time.Millisecond), logAttrs)
+	}
+}
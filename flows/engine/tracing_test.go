@@ -0,0 +1,104 @@
+package engine_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/nyaruka/gocommon/dates"
+	"github.com/nyaruka/gocommon/urns"
+	"github.com/nyaruka/gocommon/uuids"
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/envs"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/engine"
+	"github.com/nyaruka/goflow/flows/resumes"
+	"github.com/nyaruka/goflow/flows/triggers"
+	"github.com/nyaruka/goflow/services/webhooks"
+	"github.com/nyaruka/goflow/test"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTracing(t *testing.T) {
+	defer dates.SetNowSource(dates.DefaultNowSource)
+
+	dates.SetNowSource(dates.NewSequentialNowSource(time.Date(2018, 4, 11, 13, 24, 30, 123456000, time.UTC)))
+
+	server := test.NewTestHTTPServer(49995)
+	defer server.Close()
+
+	assetsJSON, err := os.ReadFile("../../test/testdata/runner/two_questions.json")
+	require.NoError(t, err)
+
+	sa, err := test.CreateSessionAssets(assetsJSON, server.URL)
+	require.NoError(t, err)
+
+	flow, err := sa.Flows().Get(assets.FlowUUID("615b8a0f-588c-4d20-a05f-363b0b4ce6f4"))
+	require.NoError(t, err)
+
+	recorder := engine.NewSpanRecorder()
+	eng := engine.NewBuilder().
+		WithWebhookServiceFactory(webhooks.NewServiceFactory(http.DefaultClient, nil, nil, nil, 10000)).
+		WithTracer(recorder).
+		Build()
+
+	env := envs.NewBuilder().Build()
+	contact := flows.NewEmptyContact(sa, "Bob", envs.NilLanguage, nil)
+	trigger := triggers.NewBuilder(env, flow.Reference(), contact).Manual().Build()
+
+	session, _, err := eng.NewSessionWithContext(context.Background(), sa, trigger)
+	require.NoError(t, err)
+
+	for _, text := range []string{"red", "coke"} {
+		msg := flows.NewMsgIn(flows.MsgUUID(uuids.New()), urns.NilURN, nil, text, nil)
+		_, err := session.ResumeWithContext(context.Background(), resumes.NewMsg(env, session.Contact(), msg))
+		require.NoError(t, err)
+	}
+
+	// summarize spans as their name and what they are a child of
+	spans := recorder.Spans()
+	summary := make([]string, len(spans))
+	for i, s := range spans {
+		summary[i] = fmt.Sprintf("%s<%d", s.Name, s.ParentID)
+		if s.Attrs[flows.SpanAttrActionType] != "" {
+			summary[i] += "[" + s.Attrs[flows.SpanAttrActionType] + "]"
+		}
+
+		assert.Equal(t, string(session.UUID()), s.Attrs[flows.SpanAttrSessionUUID])
+		assert.False(t, s.EndedOn.Before(s.StartedOn))
+	}
+
+	assert.Equal(t, []string{
+		"sprint<0",
+		"visit_node<1",
+		"execute_action<2[send_msg]",
+		"sprint<0",
+		"pick_node_exit<4",
+		"visit_node<4",
+		"execute_action<6[set_contact_language]",
+		"execute_action<6[send_msg]",
+		"sprint<0",
+		"pick_node_exit<9",
+		"visit_node<9",
+		"execute_action<11[call_webhook]",
+		"http<12[call_webhook]",
+		"execute_action<11[send_msg]",
+		"pick_node_exit<11",
+	}, summary)
+
+	assert.Equal(t, map[string]string{
+		flows.SpanAttrSessionUUID: string(session.UUID()),
+		flows.SpanAttrFlowUUID:    "615b8a0f-588c-4d20-a05f-363b0b4ce6f4",
+		flows.SpanAttrNodeUUID:    "cefd2817-38a8-4ddb-af97-34fffac7e6db",
+		flows.SpanAttrActionType:  "call_webhook",
+		flows.SpanAttrActionUUID:  "ce2b5142-453b-4e43-868e-abdafafaa878",
+		flows.SpanAttrURL:         "http://127.0.0.1:49995/?cmd=success",
+		flows.SpanAttrStatus:      "success",
+		flows.SpanAttrStatusCode:  "200",
+	}, spans[12].Attrs)
+}
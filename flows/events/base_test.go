@@ -41,6 +41,7 @@ func TestEventMarshaling(t *testing.T) {
 	tz, _ := time.LoadLocation("Africa/Kigali")
 	timeout := 500
 	gender := session.Assets().Fields().Get("gender")
+	age := session.Assets().Fields().Get("age")
 	mailgun := session.Assets().Ticketers().Get("19dc6346-9623-4fe4-be80-538d493ecdf5")
 	weather := session.Assets().Topics().Get("472a7a73-96cb-4736-b567-056d987cc5b4")
 	user := session.Assets().Users().Get("bob@nyaruka.com")
@@ -210,6 +211,35 @@ func TestEventMarshaling(t *testing.T) {
 				"value": null
 			}`,
 		},
+		{
+			events.NewContactFieldsChanged(
+				[]*flows.Field{gender, age},
+				map[string]*flows.Value{
+					"gender": flows.NewValue(types.NewXText("male"), nil, nil, "", "", ""),
+					"age":    nil, // value being cleared
+				},
+			),
+			`{
+				"created_on": "2018-10-18T14:20:30.000123456Z",
+				"fields": [
+					{
+						"key": "gender",
+						"name": "Gender"
+					},
+					{
+						"key": "age",
+						"name": "Age"
+					}
+				],
+				"type": "contact_fields_changed",
+				"values": {
+					"age": null,
+					"gender": {
+						"text": "male"
+					}
+				}
+			}`,
+		},
 		{
 			events.NewContactGroupsChanged(
 				[]*flows.Group{session.Assets().Groups().FindByName("Customers")},
@@ -404,6 +434,15 @@ func TestEventMarshaling(t *testing.T) {
 				"type": "error"
 			}`,
 		},
+		{
+			events.NewCodedErrorf("msg_text_split", "message text split into %d parts", 2),
+			`{
+				"type": "error",
+				"created_on": "2018-10-18T14:20:30.000123456Z",
+				"text": "message text split into 2 parts",
+				"code": "msg_text_split"
+			}`,
+		},
 		{
 			events.NewFailure(errors.New("503 is an failure")),
 			`{
@@ -429,6 +468,7 @@ func TestEventMarshaling(t *testing.T) {
 					nil,
 					nil,
 					nil,
+					nil,
 					flows.NilMsgTopic,
 				),
 			),
@@ -473,6 +513,15 @@ func TestEventMarshaling(t *testing.T) {
 				}
 			}`,
 		},
+		{
+			events.NewDelayWait(time.Date(2018, 10, 20, 14, 20, 30, 0, time.UTC), 172800),
+			`{
+				"type": "delay_wait",
+				"created_on": "2018-10-18T14:20:30.000123456Z",
+				"until": "2018-10-20T14:20:30Z",
+				"timeout_seconds": 172800
+			}`,
+		},
 		{
 			events.NewDialWait(urns.URN("tel:+1234567890")),
 			`{
@@ -481,6 +530,45 @@ func TestEventMarshaling(t *testing.T) {
 				"urn": "tel:+1234567890"
 			}`,
 		},
+		{
+			events.NewPersonalizedBroadcastCreated(
+				[]*events.PersonalizedMsg{
+					{
+						URN:      urns.URN("tel:+12345678900"),
+						Contact:  flows.NewContactReference(flows.ContactUUID("b2aaf598-1bb3-4c7d-b6bb-1f8dbe2ac16f"), "Jim"),
+						Language: envs.Language("spa"),
+						Text:     "Hola Jim",
+					},
+					{
+						URN:          urns.URN("tel:+12345678911"),
+						Language:     envs.Language("eng"),
+						Text:         "Hi there",
+						QuickReplies: []string{"Yes", "No"},
+					},
+				},
+			),
+			`{
+				"type": "personalized_broadcast_created",
+				"created_on": "2018-10-18T14:20:30.000123456Z",
+				"messages": [
+					{
+						"urn": "tel:+12345678900",
+						"contact": {
+							"uuid": "b2aaf598-1bb3-4c7d-b6bb-1f8dbe2ac16f",
+							"name": "Jim"
+						},
+						"language": "spa",
+						"text": "Hola Jim"
+					},
+					{
+						"urn": "tel:+12345678911",
+						"language": "eng",
+						"text": "Hi there",
+						"quick_replies": ["Yes", "No"]
+					}
+				]
+			}`,
+		},
 		{
 			events.NewSessionTriggered(
 				assets.NewFlowReference(assets.FlowUUID("e4d441f0-24e3-4627-85fb-1e99e733baf0"), "Collect Age"),
@@ -553,6 +641,64 @@ func TestEventMarshaling(t *testing.T) {
 				}
 			}`,
 		},
+		{
+			events.NewTicketNoteAdded(ticket, "Customer is a VIP"),
+			`{
+				"type": "ticket_note_added",
+				"created_on": "2018-10-18T14:20:30.000123456Z",
+				"ticket_uuid": "7481888c-07dd-47dc-bf22-ef7448696ffe",
+				"note": "Customer is a VIP"
+			}`,
+		},
+		{
+			events.NewTicketAssigned(ticket, user),
+			`{
+				"type": "ticket_assigned",
+				"created_on": "2018-10-18T14:20:30.000123456Z",
+				"ticket_uuid": "7481888c-07dd-47dc-bf22-ef7448696ffe",
+				"assignee": {
+					"email": "bob@nyaruka.com",
+					"name": "Bob"
+				}
+			}`,
+		},
+		{
+			events.NewTicketAssigned(ticket, nil),
+			`{
+				"type": "ticket_assigned",
+				"created_on": "2018-10-18T14:20:30.000123456Z",
+				"ticket_uuid": "7481888c-07dd-47dc-bf22-ef7448696ffe",
+				"assignee": null
+			}`,
+		},
+		{
+			events.NewTicketTopicChanged(ticket, weather),
+			`{
+				"type": "ticket_topic_changed",
+				"created_on": "2018-10-18T14:20:30.000123456Z",
+				"ticket_uuid": "7481888c-07dd-47dc-bf22-ef7448696ffe",
+				"topic": {
+					"uuid": "472a7a73-96cb-4736-b567-056d987cc5b4",
+					"name": "Weather"
+				}
+			}`,
+		},
+		{
+			events.NewTicketClosed(ticket),
+			`{
+				"type": "ticket_closed",
+				"created_on": "2018-10-18T14:20:30.000123456Z",
+				"ticket_uuid": "7481888c-07dd-47dc-bf22-ef7448696ffe"
+			}`,
+		},
+		{
+			events.NewTicketReopened(ticket),
+			`{
+				"type": "ticket_reopened",
+				"created_on": "2018-10-18T14:20:30.000123456Z",
+				"ticket_uuid": "7481888c-07dd-47dc-bf22-ef7448696ffe"
+			}`,
+		},
 		{
 			events.NewTicketerCalled(
 				assets.NewTicketerReference(assets.TicketerUUID("4b937f49-7fb7-43a5-8e57-14e2f028a471"), "Support"),
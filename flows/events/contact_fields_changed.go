@@ -0,0 +1,48 @@
+package events
+
+import (
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/flows"
+)
+
+func init() {
+	registerType(TypeContactFieldsChanged, func() flows.Event { return &ContactFieldsChangedEvent{} })
+}
+
+// TypeContactFieldsChanged is the type of our fields changed event
+const TypeContactFieldsChanged string = "contact_fields_changed"
+
+// ContactFieldsChangedEvent events are created when several custom field values of the contact have been changed
+// at once. The new values are keyed by field key, and a null value indicates that the field value has been cleared.
+//
+//   {
+//     "type": "contact_fields_changed",
+//     "created_on": "2006-01-02T15:04:05Z",
+//     "fields": [{"key": "gender", "name": "Gender"}, {"key": "age", "name": "Age"}],
+//     "values": {
+//       "gender": {"text": "Male"},
+//       "age": null
+//     }
+//   }
+//
+// @event contact_fields_changed
+type ContactFieldsChangedEvent struct {
+	baseEvent
+
+	Fields []*assets.FieldReference `json:"fields" validate:"required,min=1,dive"`
+	Values map[string]*flows.Value  `json:"values"`
+}
+
+// NewContactFieldsChanged returns a new fields changed event for the given fields and their new values
+func NewContactFieldsChanged(fields []*flows.Field, values map[string]*flows.Value) *ContactFieldsChangedEvent {
+	refs := make([]*assets.FieldReference, len(fields))
+	for i, field := range fields {
+		refs[i] = field.Reference()
+	}
+
+	return &ContactFieldsChangedEvent{
+		baseEvent: newBaseEvent(TypeContactFieldsChanged),
+		Fields:    refs,
+		Values:    values,
+	}
+}
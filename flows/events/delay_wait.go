@@ -0,0 +1,43 @@
+package events
+
+import (
+	"time"
+
+	"github.com/nyaruka/goflow/flows"
+)
+
+func init() {
+	registerType(TypeDelayWait, func() flows.Event { return &DelayWaitEvent{} })
+}
+
+// TypeDelayWait is the type of our delay wait event
+const TypeDelayWait string = "delay_wait"
+
+// DelayWaitEvent events are created when a flow pauses until a scheduled time. The caller should resume the flow
+// with a wait timeout after the number of seconds in the timeout.
+//
+//   {
+//     "type": "delay_wait",
+//     "created_on": "2019-01-02T15:04:05Z",
+//     "until": "2019-01-04T15:04:05Z",
+//     "timeout_seconds": 172800
+//   }
+//
+// @event delay_wait
+type DelayWaitEvent struct {
+	baseEvent
+
+	Until          time.Time `json:"until" validate:"required"`
+	TimeoutSeconds int       `json:"timeout_seconds"`
+}
+
+// NewDelayWait returns a new delay wait with the passed in time and timeout
+func NewDelayWait(until time.Time, timeoutSeconds int) *DelayWaitEvent {
+	return &DelayWaitEvent{
+		baseEvent:      newBaseEvent(TypeDelayWait),
+		Until:          until,
+		TimeoutSeconds: timeoutSeconds,
+	}
+}
+
+var _ flows.Event = (*DelayWaitEvent)(nil)
@@ -14,7 +14,8 @@ func init() {
 // TypeError is the type of our error events
 const TypeError string = "error"
 
-// ErrorEvent events are created when an error occurs during flow execution.
+// ErrorEvent events are created when an error occurs during flow execution. Some errors also include a code which
+// identifies the kind of error.
 //
 //   {
 //     "type": "error",
@@ -27,6 +28,7 @@ type ErrorEvent struct {
 	baseEvent
 
 	Text string `json:"text" validate:"required"`
+	Code string `json:"code,omitempty"`
 }
 
 // NewError returns a new error event for the passed in error
@@ -42,6 +44,13 @@ func NewErrorf(format string, a ...interface{}) *ErrorEvent {
 	}
 }
 
+// NewCodedErrorf returns a new error event with the given code for the passed in format string and args
+func NewCodedErrorf(code string, format string, a ...interface{}) *ErrorEvent {
+	e := NewErrorf(format, a...)
+	e.Code = code
+	return e
+}
+
 // NewDependencyError returns an error event for a missing dependency
 func NewDependencyError(ref assets.Reference) *ErrorEvent {
 	return NewErrorf("missing dependency: %s", ref.String())
@@ -0,0 +1,66 @@
+package events
+
+import (
+	"github.com/nyaruka/gocommon/urns"
+	"github.com/nyaruka/goflow/envs"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/utils"
+)
+
+func init() {
+	registerType(TypePersonalizedBroadcastCreated, func() flows.Event { return &PersonalizedBroadcastCreatedEvent{} })
+}
+
+// TypePersonalizedBroadcastCreated is a constant for personalized outgoing message events
+const TypePersonalizedBroadcastCreated string = "personalized_broadcast_created"
+
+// PersonalizedMsg is the broadcast content rendered for a particular recipient
+type PersonalizedMsg struct {
+	URN          urns.URN                `json:"urn" validate:"required,urn"`
+	Contact      *flows.ContactReference `json:"contact,omitempty" validate:"omitempty,dive"`
+	Language     envs.Language           `json:"language,omitempty"`
+	Text         string                  `json:"text"`
+	Attachments  []utils.Attachment      `json:"attachments,omitempty"`
+	QuickReplies []string                `json:"quick_replies,omitempty"`
+}
+
+// PersonalizedBroadcastCreatedEvent events are created when an action wants to send a message to other contacts
+// which has been rendered separately for each recipient. The contact is only included for recipients which are
+// existing contacts, and the language is the recipient's language which was used to localize their message.
+//
+//   {
+//     "type": "personalized_broadcast_created",
+//     "created_on": "2006-01-02T15:04:05Z",
+//     "messages": [
+//       {
+//         "urn": "tel:+12065551212",
+//         "contact": {"uuid": "0e06f977-cbb7-475f-9d0b-a0c4aaec7f6a", "name": "Bob"},
+//         "language": "eng",
+//         "text": "Hi Bob, Ryan has invited you to join",
+//         "quick_replies": ["Join", "No thanks"]
+//       },
+//       {
+//         "urn": "tel:+12065553434",
+//         "language": "eng",
+//         "text": "Hi there, Ryan has invited you to join",
+//         "quick_replies": ["Join", "No thanks"]
+//       }
+//     ]
+//   }
+//
+// @event personalized_broadcast_created
+type PersonalizedBroadcastCreatedEvent struct {
+	baseEvent
+
+	Messages []*PersonalizedMsg `json:"messages" validate:"min=1,dive"`
+}
+
+// NewPersonalizedBroadcastCreated creates a new personalized broadcast event for the given rendered messages
+func NewPersonalizedBroadcastCreated(messages []*PersonalizedMsg) *PersonalizedBroadcastCreatedEvent {
+	return &PersonalizedBroadcastCreatedEvent{
+		baseEvent: newBaseEvent(TypePersonalizedBroadcastCreated),
+		Messages:  messages,
+	}
+}
+
+var _ flows.Event = (*PersonalizedBroadcastCreatedEvent)(nil)
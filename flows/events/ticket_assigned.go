@@ -0,0 +1,40 @@
+package events
+
+import (
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/flows"
+)
+
+func init() {
+	registerType(TypeTicketAssigned, func() flows.Event { return &TicketAssignedEvent{} })
+}
+
+// TypeTicketAssigned is the type for our ticket assigned events
+const TypeTicketAssigned string = "ticket_assigned"
+
+// TicketAssignedEvent events are created when a ticket is assigned to a user, or unassigned in which case the
+// assignee will be null.
+//
+//   {
+//     "type": "ticket_assigned",
+//     "created_on": "2006-01-02T15:04:05Z",
+//     "ticket_uuid": "2e677ae6-9b57-423c-b022-7950503eef35",
+//     "assignee": {"email": "bob@nyaruka.com", "name": "Bob"}
+//   }
+//
+// @event ticket_assigned
+type TicketAssignedEvent struct {
+	baseEvent
+
+	TicketUUID flows.TicketUUID      `json:"ticket_uuid" validate:"required,uuid4"`
+	Assignee   *assets.UserReference `json:"assignee"    validate:"omitempty,dive"`
+}
+
+// NewTicketAssigned returns a new ticket assigned event
+func NewTicketAssigned(ticket *flows.Ticket, assignee *flows.User) *TicketAssignedEvent {
+	return &TicketAssignedEvent{
+		baseEvent:  newBaseEvent(TypeTicketAssigned),
+		TicketUUID: ticket.UUID(),
+		Assignee:   assignee.Reference(),
+	}
+}
@@ -0,0 +1,33 @@
+package events
+
+import "github.com/nyaruka/goflow/flows"
+
+func init() {
+	registerType(TypeTicketClosed, func() flows.Event { return &TicketClosedEvent{} })
+}
+
+// TypeTicketClosed is the type for our ticket closed events
+const TypeTicketClosed string = "ticket_closed"
+
+// TicketClosedEvent events are created when a ticket is closed.
+//
+//   {
+//     "type": "ticket_closed",
+//     "created_on": "2006-01-02T15:04:05Z",
+//     "ticket_uuid": "2e677ae6-9b57-423c-b022-7950503eef35"
+//   }
+//
+// @event ticket_closed
+type TicketClosedEvent struct {
+	baseEvent
+
+	TicketUUID flows.TicketUUID `json:"ticket_uuid" validate:"required,uuid4"`
+}
+
+// NewTicketClosed returns a new ticket closed event
+func NewTicketClosed(ticket *flows.Ticket) *TicketClosedEvent {
+	return &TicketClosedEvent{
+		baseEvent:  newBaseEvent(TypeTicketClosed),
+		TicketUUID: ticket.UUID(),
+	}
+}
@@ -0,0 +1,36 @@
+package events
+
+import "github.com/nyaruka/goflow/flows"
+
+func init() {
+	registerType(TypeTicketNoteAdded, func() flows.Event { return &TicketNoteAddedEvent{} })
+}
+
+// TypeTicketNoteAdded is the type for our ticket note added events
+const TypeTicketNoteAdded string = "ticket_note_added"
+
+// TicketNoteAddedEvent events are created when a note is added to a ticket.
+//
+//   {
+//     "type": "ticket_note_added",
+//     "created_on": "2006-01-02T15:04:05Z",
+//     "ticket_uuid": "2e677ae6-9b57-423c-b022-7950503eef35",
+//     "note": "Customer is a VIP"
+//   }
+//
+// @event ticket_note_added
+type TicketNoteAddedEvent struct {
+	baseEvent
+
+	TicketUUID flows.TicketUUID `json:"ticket_uuid" validate:"required,uuid4"`
+	Note       string           `json:"note"`
+}
+
+// NewTicketNoteAdded returns a new ticket note added event
+func NewTicketNoteAdded(ticket *flows.Ticket, note string) *TicketNoteAddedEvent {
+	return &TicketNoteAddedEvent{
+		baseEvent:  newBaseEvent(TypeTicketNoteAdded),
+		TicketUUID: ticket.UUID(),
+		Note:       note,
+	}
+}
@@ -0,0 +1,33 @@
+package events
+
+import "github.com/nyaruka/goflow/flows"
+
+func init() {
+	registerType(TypeTicketReopened, func() flows.Event { return &TicketReopenedEvent{} })
+}
+
+// TypeTicketReopened is the type for our ticket reopened events
+const TypeTicketReopened string = "ticket_reopened"
+
+// TicketReopenedEvent events are created when a closed ticket is reopened.
+//
+//   {
+//     "type": "ticket_reopened",
+//     "created_on": "2006-01-02T15:04:05Z",
+//     "ticket_uuid": "2e677ae6-9b57-423c-b022-7950503eef35"
+//   }
+//
+// @event ticket_reopened
+type TicketReopenedEvent struct {
+	baseEvent
+
+	TicketUUID flows.TicketUUID `json:"ticket_uuid" validate:"required,uuid4"`
+}
+
+// NewTicketReopened returns a new ticket reopened event
+func NewTicketReopened(ticket *flows.Ticket) *TicketReopenedEvent {
+	return &TicketReopenedEvent{
+		baseEvent:  newBaseEvent(TypeTicketReopened),
+		TicketUUID: ticket.UUID(),
+	}
+}
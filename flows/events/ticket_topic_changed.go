@@ -0,0 +1,42 @@
+package events
+
+import (
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/flows"
+)
+
+func init() {
+	registerType(TypeTicketTopicChanged, func() flows.Event { return &TicketTopicChangedEvent{} })
+}
+
+// TypeTicketTopicChanged is the type for our ticket topic changed events
+const TypeTicketTopicChanged string = "ticket_topic_changed"
+
+// TicketTopicChangedEvent events are created when the topic of a ticket is changed.
+//
+//   {
+//     "type": "ticket_topic_changed",
+//     "created_on": "2006-01-02T15:04:05Z",
+//     "ticket_uuid": "2e677ae6-9b57-423c-b022-7950503eef35",
+//     "topic": {
+//       "uuid": "add17edf-0b6e-4311-bcd7-a64b2a459157",
+//       "name": "Weather"
+//     }
+//   }
+//
+// @event ticket_topic_changed
+type TicketTopicChangedEvent struct {
+	baseEvent
+
+	TicketUUID flows.TicketUUID       `json:"ticket_uuid" validate:"required,uuid4"`
+	Topic      *assets.TopicReference `json:"topic"       validate:"required,dive"`
+}
+
+// NewTicketTopicChanged returns a new ticket topic changed event
+func NewTicketTopicChanged(ticket *flows.Ticket, topic *flows.Topic) *TicketTopicChangedEvent {
+	return &TicketTopicChangedEvent{
+		baseEvent:  newBaseEvent(TypeTicketTopicChanged),
+		TicketUUID: ticket.UUID(),
+		Topic:      topic.Reference(),
+	}
+}
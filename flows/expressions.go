@@ -39,6 +39,7 @@ var RunContextTopLevels = []string{
 	"input",
 	"legacy_extra",
 	"node",
+	"params",
 	"parent",
 	"results",
 	"resume",
@@ -29,6 +29,7 @@ type MsgInput struct {
 	text        string
 	attachments []utils.Attachment
 	externalID  string
+	buttonID    string
 }
 
 // NewMsg creates a new user input based on a message
@@ -45,6 +46,7 @@ func NewMsg(assets flows.SessionAssets, msg *flows.MsgIn, createdOn time.Time) *
 		text:        msg.Text(),
 		attachments: msg.Attachments(),
 		externalID:  msg.ExternalID(),
+		buttonID:    msg.ButtonID(),
 	}
 }
 
@@ -58,6 +60,7 @@ func NewMsg(assets flows.SessionAssets, msg *flows.MsgIn, createdOn time.Time) *
 //   text:text -> the text part of the input
 //   attachments:[]text -> any attachments on the input
 //   external_id:text -> the external ID of the input
+//   button_id:text -> the ID of the interactive button or list row selected by the contact
 //
 // @context input
 func (i *MsgInput) Context(env envs.Environment) map[string]types.XValue {
@@ -82,6 +85,7 @@ func (i *MsgInput) Context(env envs.Environment) map[string]types.XValue {
 		"text":        types.NewXText(i.text),
 		"attachments": types.NewXArray(attachments...),
 		"external_id": types.NewXText(i.externalID),
+		"button_id":   types.NewXText(i.buttonID),
 	}
 }
 
@@ -108,6 +112,7 @@ type msgInputEnvelope struct {
 	Text        string             `json:"text"`
 	Attachments []utils.Attachment `json:"attachments,omitempty"`
 	ExternalID  string             `json:"external_id,omitempty"`
+	ButtonID    string             `json:"button_id,omitempty"`
 }
 
 func readMsgInput(sessionAssets flows.SessionAssets, data json.RawMessage, missing assets.MissingCallback) (flows.Input, error) {
@@ -122,6 +127,7 @@ func readMsgInput(sessionAssets flows.SessionAssets, data json.RawMessage, missi
 		text:        e.Text,
 		attachments: e.Attachments,
 		externalID:  e.ExternalID,
+		buttonID:    e.ButtonID,
 	}
 
 	if err := i.unmarshal(sessionAssets, &e.baseInputEnvelope, missing); err != nil {
@@ -138,6 +144,7 @@ func (i *MsgInput) MarshalJSON() ([]byte, error) {
 		Text:        i.text,
 		Attachments: i.attachments,
 		ExternalID:  i.externalID,
+		ButtonID:    i.buttonID,
 	}
 
 	i.marshal(&e.baseInputEnvelope)
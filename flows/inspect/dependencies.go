@@ -66,6 +66,8 @@ func NewDependencies(refs []flows.ExtractedReference, sa flows.SessionAssets) []
 // CheckReference determines whether this reference is accessible
 func CheckReference(sa flows.SessionAssets, ref assets.Reference) bool {
 	switch typed := ref.(type) {
+	case *assets.AuthProfileReference:
+		return sa.AuthProfiles().Get(typed.UUID) != nil
 	case *assets.ChannelReference:
 		return sa.Channels().Get(typed.UUID) != nil
 	case *assets.ClassifierReference:
@@ -0,0 +1,104 @@
+package issues
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/envs"
+	"github.com/nyaruka/goflow/excellent"
+	"github.com/nyaruka/goflow/excellent/types"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/actions"
+)
+
+func init() {
+	registerType(TypeInvalidParam, InvalidParamCheck)
+}
+
+// TypeInvalidParam is our type for an invalid param passed to another flow
+const TypeInvalidParam string = "invalid_param"
+
+// InvalidParam is a param passed to a flow which is missing, not declared by that flow, or not valid for its type
+type InvalidParam struct {
+	baseIssue
+
+	Flow  *assets.FlowReference `json:"flow"`
+	Param string                `json:"param"`
+}
+
+func newInvalidParam(nodeUUID flows.NodeUUID, actionUUID flows.ActionUUID, flow *assets.FlowReference, param, description string) *InvalidParam {
+	return &InvalidParam{
+		baseIssue: newBaseIssue(
+			TypeInvalidParam,
+			nodeUUID,
+			actionUUID,
+			"",
+			description,
+		),
+		Flow:  flow,
+		Param: param,
+	}
+}
+
+// InvalidParamCheck checks the params passed by enter_flow actions against those declared by the flows they enter
+func InvalidParamCheck(sa flows.SessionAssets, flow flows.Flow, tpls []flows.ExtractedTemplate, refs []flows.ExtractedReference, report func(flows.Issue)) {
+	// skip check if we don't have assets
+	if sa == nil {
+		return
+	}
+
+	env := envs.NewBuilder().Build()
+
+	for _, node := range flow.Nodes() {
+		for _, action := range node.Actions() {
+			enterFlow, isEnterFlow := action.(*actions.EnterFlowAction)
+			if !isEnterFlow {
+				continue
+			}
+
+			// missing flows are reported as missing dependencies
+			child, err := sa.Flows().Get(enterFlow.Flow.UUID)
+			if err != nil {
+				continue
+			}
+
+			reportParam := func(param, description string) {
+				report(newInvalidParam(node.UUID(), action.UUID(), child.Reference(), param, description))
+			}
+
+			declared := make(map[string]bool, len(child.Params()))
+
+			for _, p := range child.Params() {
+				declared[p.Key] = true
+
+				template, passed := enterFlow.Params[p.Key]
+				if !passed {
+					if p.Required {
+						reportParam(p.Key, fmt.Sprintf("missing value for required param '%s' of flow '%s'", p.Key, child.Name()))
+					}
+					continue
+				}
+
+				// only check values which don't contain expressions
+				if !excellent.HasExpressions(template, flows.RunContextTopLevels) {
+					if _, err := p.Type.Convert(env, types.NewXText(template)); err != nil {
+						reportParam(p.Key, fmt.Sprintf("value '%s' isn't a valid %s for param '%s' of flow '%s'", template, p.Type, p.Key, child.Name()))
+					}
+				}
+			}
+
+			unknown := make([]string, 0)
+			for key := range enterFlow.Params {
+				if !declared[key] {
+					unknown = append(unknown, key)
+				}
+			}
+			sort.Strings(unknown)
+
+			for _, key := range unknown {
+				reportParam(key, fmt.Sprintf("param '%s' isn't declared by flow '%s'", key, child.Name()))
+			}
+		}
+	}
+}
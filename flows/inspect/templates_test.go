@@ -13,6 +13,7 @@ import (
 	"github.com/nyaruka/goflow/flows/actions"
 	"github.com/nyaruka/goflow/flows/definition"
 	"github.com/nyaruka/goflow/flows/inspect"
+	"github.com/nyaruka/goflow/flows/routers/waits"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -72,20 +73,35 @@ func TestTemplatePaths(t *testing.T) {
 		})
 	}
 
+	inspect.TemplatePaths(reflect.TypeOf(&waits.DelayWait{}), "$.nodes[*].router.wait[@.type=\"delay\"]", func(path string) {
+		paths = append(paths, path)
+	})
+
 	sort.Strings(paths)
 
 	assert.Equal(t, []string{
 		"$.nodes[*].actions[@.type=\"add_contact_groups\"].groups[*].name_match",
 		"$.nodes[*].actions[@.type=\"add_contact_urn\"].path",
 		"$.nodes[*].actions[@.type=\"add_input_labels\"].labels[*].name_match",
+		"$.nodes[*].actions[@.type=\"add_ticket_note\"].note",
+		"$.nodes[*].actions[@.type=\"assign_ticket\"].assignee.email_match",
 		"$.nodes[*].actions[@.type=\"call_classifier\"].input",
+		"$.nodes[*].actions[@.type=\"call_http\"].body",
+		"$.nodes[*].actions[@.type=\"call_http\"].headers[*]",
+		"$.nodes[*].actions[@.type=\"call_http\"].url",
 		"$.nodes[*].actions[@.type=\"call_webhook\"].body",
 		"$.nodes[*].actions[@.type=\"call_webhook\"].headers[*]",
 		"$.nodes[*].actions[@.type=\"call_webhook\"].url",
+		"$.nodes[*].actions[@.type=\"create_contact\"].name",
+		"$.nodes[*].actions[@.type=\"create_contact\"].urns[*]",
+		"$.nodes[*].actions[@.type=\"enter_flow\"].params[*]",
+		"$.nodes[*].actions[@.type=\"lookup_contact\"].urn",
+		"$.nodes[*].actions[@.type=\"lookup_contact\"].value",
 		"$.nodes[*].actions[@.type=\"open_ticket\"].assignee.email_match",
 		"$.nodes[*].actions[@.type=\"open_ticket\"].body",
 		"$.nodes[*].actions[@.type=\"play_audio\"].audio_url",
 		"$.nodes[*].actions[@.type=\"remove_contact_groups\"].groups[*].name_match",
+		"$.nodes[*].actions[@.type=\"remove_contact_urn\"].urn",
 		"$.nodes[*].actions[@.type=\"say_msg\"].text",
 		"$.nodes[*].actions[@.type=\"send_broadcast\"].attachments[*]",
 		"$.nodes[*].actions[@.type=\"send_broadcast\"].contact_query",
@@ -97,17 +113,32 @@ func TestTemplatePaths(t *testing.T) {
 		"$.nodes[*].actions[@.type=\"send_email\"].body",
 		"$.nodes[*].actions[@.type=\"send_email\"].subject",
 		"$.nodes[*].actions[@.type=\"send_msg\"].attachments[*]",
+		"$.nodes[*].actions[@.type=\"send_msg\"].interactive.button",
+		"$.nodes[*].actions[@.type=\"send_msg\"].interactive.buttons[*].description",
+		"$.nodes[*].actions[@.type=\"send_msg\"].interactive.buttons[*].title",
+		"$.nodes[*].actions[@.type=\"send_msg\"].interactive.sections[*].rows[*].description",
+		"$.nodes[*].actions[@.type=\"send_msg\"].interactive.sections[*].rows[*].title",
+		"$.nodes[*].actions[@.type=\"send_msg\"].interactive.sections[*].title",
+		"$.nodes[*].actions[@.type=\"send_msg\"].interactive.url",
 		"$.nodes[*].actions[@.type=\"send_msg\"].quick_replies[*]",
 		"$.nodes[*].actions[@.type=\"send_msg\"].templating.variables[*]",
 		"$.nodes[*].actions[@.type=\"send_msg\"].text",
+		"$.nodes[*].actions[@.type=\"send_personalized_broadcast\"].attachments[*]",
+		"$.nodes[*].actions[@.type=\"send_personalized_broadcast\"].quick_replies[*]",
+		"$.nodes[*].actions[@.type=\"send_personalized_broadcast\"].text",
+		"$.nodes[*].actions[@.type=\"send_personalized_broadcast\"].urns[*]",
 		"$.nodes[*].actions[@.type=\"set_contact_field\"].value",
+		"$.nodes[*].actions[@.type=\"set_contact_fields\"].values[*]",
 		"$.nodes[*].actions[@.type=\"set_contact_language\"].language",
 		"$.nodes[*].actions[@.type=\"set_contact_name\"].name",
+		"$.nodes[*].actions[@.type=\"set_contact_preferred_urn\"].urn",
 		"$.nodes[*].actions[@.type=\"set_contact_timezone\"].timezone",
 		"$.nodes[*].actions[@.type=\"set_run_result\"].value",
 		"$.nodes[*].actions[@.type=\"start_session\"].contact_query",
 		"$.nodes[*].actions[@.type=\"start_session\"].groups[*].name_match",
 		"$.nodes[*].actions[@.type=\"start_session\"].legacy_vars[*]",
+		"$.nodes[*].router.wait[@.type=\"delay\"].seconds",
+		"$.nodes[*].router.wait[@.type=\"delay\"].until",
 	}, paths)
 }
 
@@ -1,6 +1,7 @@
 package flows
 
 import (
+	"context"
 	"encoding/json"
 	"time"
 
@@ -67,6 +68,9 @@ const (
 
 	// SessionStatusFailed represents a session that encountered an unrecoverable error
 	SessionStatusFailed SessionStatus = "failed"
+
+	// SessionStatusPaused represents a session which exceeded the step or time budget of a sprint and can be continued
+	SessionStatusPaused SessionStatus = "paused"
 )
 
 // RunStatus represents the current status of the flow run
@@ -100,6 +104,7 @@ type SessionAssets interface {
 
 	Source() assets.Source
 
+	AuthProfiles() *AuthProfileAssets
 	Channels() *ChannelAssets
 	Classifiers() *ClassifierAssets
 	Fields() *FieldAssets
@@ -132,6 +137,8 @@ type Flow interface {
 	Type() FlowType
 	ExpireAfterMinutes() int
 	Localization() Localization
+	Params() []*FlowParam
+	Outputs() []*FlowOutput
 	UI() json.RawMessage
 	Nodes() []Node
 	GetNode(uuid NodeUUID) Node
@@ -169,6 +176,13 @@ type Action interface {
 	Validate() error
 }
 
+// ContextAction is an action which can be given a context to control cancellation of any calls it makes to services
+type ContextAction interface {
+	Action
+
+	ExecuteWithContext(context.Context, FlowRun, Step, ModifierCallback, EventCallback) error
+}
+
 // Category is how routers map results to exits
 type Category interface {
 	Localizable
@@ -216,8 +230,12 @@ type Wait interface {
 
 	Timeout() Timeout
 
-	Begin(FlowRun, EventCallback) ActivatedWait
+	// Begin begins waiting, returning the activated wait, or nil if the wait was skipped, in which case it also
+	// returns whether the router should route as though the wait timed out
+	Begin(FlowRun, EventCallback) (ActivatedWait, bool)
 	End(Resume) error
+
+	EnumerateTemplates(Localization, func(envs.Language, string))
 }
 
 // ActivatedWait is a wait once it has been activated in a session
@@ -323,13 +341,29 @@ type Step interface {
 // Engine provides callers with session starting and resuming
 type Engine interface {
 	NewSession(SessionAssets, Trigger) (Session, Sprint, error)
+	NewSessionWithContext(context.Context, SessionAssets, Trigger) (Session, Sprint, error)
 	ReadSession(SessionAssets, json.RawMessage, assets.MissingCallback) (Session, error)
 
 	Services() Services
+	Middleware() Middleware
+	Tracer() Tracer
+	Metrics() Metrics
+	KeyProvider() KeyProvider
 	MaxStepsPerSprint() int
+	MaxSprintDuration() time.Duration
+	PauseOnStepLimit() bool
+	MaxPausedSteps() int
 	MaxTemplateChars() int
 }
 
+// Middleware can intercept the events and modifiers generated during a sprint before they are logged or applied. Each
+// method returns what should be used in place of what was passed in - which can be nothing to veto it, or more than
+// one item to enrich it.
+type Middleware interface {
+	Event(Session, Event) []Event
+	Modifier(Session, Modifier) []Modifier
+}
+
 // Sprint is an interaction with the engine - i.e. a start or resume of a session
 type Sprint interface {
 	Modifiers() []Modifier
@@ -359,10 +393,16 @@ type Session interface {
 	Trigger() Trigger
 	CurrentResume() Resume
 	BatchStart() bool
-	PushFlow(Flow, FlowRun, bool)
+	AuthToken(assets.AuthProfileUUID) string
+	SetAuthToken(assets.AuthProfileUUID, string, time.Time)
+	PushFlow(Flow, FlowRun, bool, *types.XObject)
 	Wait() ActivatedWait
 
 	Resume(Resume) (Sprint, error)
+	ResumeWithContext(context.Context, Resume) (Sprint, error)
+	Continue() (Sprint, error)
+	ContinueWithContext(context.Context) (Sprint, error)
+	Rewind(StepUUID) (Sprint, error)
 	Runs() []FlowRun
 	GetRun(RunUUID) (FlowRun, error)
 	FindStep(uuid StepUUID) (FlowRun, Step)
@@ -393,6 +433,7 @@ type FlowRun interface {
 
 	Environment() envs.Environment
 	Session() Session
+	Params() *types.XObject
 	SaveResult(*Result)
 	SetStatus(RunStatus)
 	Webhook() types.XValue
@@ -400,6 +441,7 @@ type FlowRun interface {
 
 	CreateStep(Node) Step
 	Path() []Step
+	SetPath([]Step)
 	PathLocation() (Step, Node, error)
 
 	LogEvent(Step, Event)
@@ -411,6 +453,8 @@ type FlowRun interface {
 	EvaluateTemplateText(string, excellent.Escaping, bool) (string, error)
 	EvaluateTemplate(string) (string, error)
 	RootContext(envs.Environment) map[string]types.XValue
+	ContactEnvironment(*Contact) envs.Environment
+	EvaluateTemplateForContact(*Contact, string) (string, error)
 
 	GetText(uuids.UUID, string, string) string
 	GetTextArray(uuids.UUID, string, []string) ([]string, envs.Language)
@@ -427,6 +471,7 @@ type FlowRun interface {
 	ResetExpiration(*time.Time)
 	ExitedOn() *time.Time
 	Exit(RunStatus)
+	Rewind(int, int, RunStatus)
 }
 
 // LegacyExtraContributor is something which contributes results for constructing @legacy_extra
@@ -0,0 +1,21 @@
+package flows
+
+// names of the metrics reported by the engine
+const (
+	MetricSessionsStarted     = "goflow_sessions_started_total"
+	MetricSessionsResumed     = "goflow_sessions_resumed_total"
+	MetricSessionStatuses     = "goflow_session_statuses_total"
+	MetricEvents              = "goflow_events_total"
+	MetricSprintSteps         = "goflow_sprint_steps"
+	MetricTemplateErrors      = "goflow_template_errors_total"
+	MetricServiceCallDuration = "goflow_service_call_duration_seconds"
+)
+
+// Metrics is a sink for counters and histograms reported by the engine as it executes flows
+type Metrics interface {
+	// IncCounter increments the named counter
+	IncCounter(name string, labels map[string]string)
+
+	// ObserveHistogram adds an observation to the named histogram
+	ObserveHistogram(name string, value float64, labels map[string]string)
+}
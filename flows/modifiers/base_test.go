@@ -115,6 +115,7 @@ func TestConstructors(t *testing.T) {
 
 	nexmo := assets.Channels().Get("3a05eaf5-cb1b-4246-bef1-f277419c83a7")
 	age := assets.Fields().Get("age")
+	gender := assets.Fields().Get("gender")
 	testers := assets.Groups().Get("b7cf0d83-f1c9-411c-96fd-c511a4cfa86d")
 	la, _ := time.LoadLocation("America/Los_Angeles")
 
@@ -143,6 +144,17 @@ func TestConstructors(t *testing.T) {
 				"value": "37 years"
 			}`,
 		},
+		{
+			modifiers.NewFields([]*flows.Field{age, gender}, map[string]string{"age": "37", "gender": ""}),
+			`{
+				"type": "fields",
+				"fields": [
+					{"key": "age", "name": "Age"},
+					{"key": "gender", "name": "Gender"}
+				],
+				"values": {"age": "37", "gender": ""}
+			}`,
+		},
 		{
 			modifiers.NewGroups([]*flows.Group{testers}, modifiers.GroupsAdd),
 			`{
@@ -0,0 +1,119 @@
+package modifiers
+
+import (
+	"encoding/json"
+
+	"github.com/nyaruka/gocommon/jsonx"
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/envs"
+	"github.com/nyaruka/goflow/excellent/types"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/events"
+	"github.com/nyaruka/goflow/utils"
+)
+
+func init() {
+	registerType(TypeFields, readFieldsModifier)
+}
+
+// TypeFields is the type of our fields modifier
+const TypeFields string = "fields"
+
+// FieldsModifier modifies several field values on the contact at once. Values are set in the order of the fields so
+// that location fields can be parsed using the parent locations set before them.
+type FieldsModifier struct {
+	baseModifier
+
+	fields []*flows.Field
+	values map[string]string
+}
+
+// NewFields creates a new fields modifier
+func NewFields(fields []*flows.Field, values map[string]string) *FieldsModifier {
+	return &FieldsModifier{
+		baseModifier: newBaseModifier(TypeFields),
+		fields:       fields,
+		values:       values,
+	}
+}
+
+// Apply applies this modification to the given contact
+func (m *FieldsModifier) Apply(env envs.Environment, sa flows.SessionAssets, contact *flows.Contact, log flows.EventCallback) {
+	changed := make([]*flows.Field, 0, len(m.fields))
+	changedValues := make(map[string]*flows.Value, len(m.fields))
+
+	for _, field := range m.fields {
+		oldValue := contact.Fields().Get(field)
+
+		newValue := contact.Fields().Parse(env, sa.Fields(), field, m.values[field.Key()])
+
+		// truncate text value if necessary
+		if newValue != nil {
+			newValue.Text = types.NewXText(utils.Truncate(newValue.Text.Native(), env.MaxValueLength()))
+		}
+
+		if !newValue.Equals(oldValue) {
+			contact.Fields().Set(field, newValue)
+			changed = append(changed, field)
+			changedValues[field.Key()] = newValue
+		}
+	}
+
+	if len(changed) > 0 {
+		log(events.NewContactFieldsChanged(changed, changedValues))
+		ReevaluateGroups(env, sa, contact, log)
+	}
+}
+
+// Values returns the values to set keyed by field key
+func (m *FieldsModifier) Values() map[string]string {
+	return m.values
+}
+
+var _ flows.Modifier = (*FieldsModifier)(nil)
+
+//------------------------------------------------------------------------------------------
+// JSON Encoding / Decoding
+//------------------------------------------------------------------------------------------
+
+type fieldsModifierEnvelope struct {
+	utils.TypedEnvelope
+	Fields []*assets.FieldReference `json:"fields" validate:"required,min=1,dive"`
+	Values map[string]string        `json:"values"`
+}
+
+func readFieldsModifier(assets flows.SessionAssets, data json.RawMessage, missing assets.MissingCallback) (flows.Modifier, error) {
+	e := &fieldsModifierEnvelope{}
+	if err := utils.UnmarshalAndValidate(data, e); err != nil {
+		return nil, err
+	}
+
+	fields := make([]*flows.Field, 0, len(e.Fields))
+	for _, ref := range e.Fields {
+		field := assets.Fields().Get(ref.Key)
+		if field != nil {
+			fields = append(fields, field)
+		} else {
+			missing(ref, nil)
+		}
+	}
+
+	if len(fields) == 0 {
+		return nil, ErrNoModifier // nothing left to modify without any fields
+	}
+
+	return NewFields(fields, e.Values), nil
+}
+
+func (m *FieldsModifier) MarshalJSON() ([]byte, error) {
+	refs := make([]*assets.FieldReference, len(m.fields))
+	for i, field := range m.fields {
+		refs[i] = field.Reference()
+	}
+
+	return jsonx.Marshal(&fieldsModifierEnvelope{
+		TypedEnvelope: utils.TypedEnvelope{Type: m.Type()},
+		Fields:        refs,
+		Values:        m.values,
+	})
+}
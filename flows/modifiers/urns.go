@@ -26,14 +26,16 @@ const (
 	URNsAppend URNsModification = "append"
 	URNsRemove URNsModification = "remove"
 	URNsSet    URNsModification = "set"
+	URNsPrefer URNsModification = "prefer"
 )
 
-// URNsModifier modifies the URNs on a contact
+// URNsModifier modifies the URNs on a contact. When preferring URNs, those which the contact has are moved to the
+// front of its URNs, in the given order, and any others are ignored.
 type URNsModifier struct {
 	baseModifier
 
 	URNs         []urns.URN       `json:"urns" validate:"required"`
-	Modification URNsModification `json:"modification" validate:"required,eq=append|eq=remove|eq=set"`
+	Modification URNsModification `json:"modification" validate:"required,eq=append|eq=remove|eq=set|eq=prefer"`
 }
 
 // NewURNs creates a new URNs modifier
@@ -53,7 +55,17 @@ func (m *URNsModifier) Apply(env envs.Environment, assets flows.SessionAssets, c
 		modified = contact.ClearURNs()
 	}
 
-	for _, urn := range m.URNs {
+	urnz := m.URNs
+
+	// prefer URNs in reverse order so that the first ends up at the front
+	if m.Modification == URNsPrefer {
+		urnz = make([]urns.URN, len(m.URNs))
+		for i, urn := range m.URNs {
+			urnz[len(m.URNs)-1-i] = urn
+		}
+	}
+
+	for _, urn := range urnz {
 		// normalize the URN
 		urn := urn.Normalize(string(env.DefaultCountry()))
 
@@ -62,6 +74,8 @@ func (m *URNsModifier) Apply(env envs.Environment, assets flows.SessionAssets, c
 		} else {
 			if m.Modification == URNsAppend || m.Modification == URNsSet {
 				modified = contact.AddURN(urn, nil)
+			} else if m.Modification == URNsPrefer {
+				modified = contact.SetPreferredURN(urn) || modified
 			} else {
 				modified = contact.RemoveURN(urn)
 			}
@@ -16,6 +16,9 @@ func init() {
 	utils.RegisterValidatorAlias("msg_topic", "eq=event|eq=account|eq=purchase|eq=agent", func(validator.FieldError) string {
 		return "is not a valid message topic"
 	})
+	utils.RegisterValidatorAlias("interactive_type", "eq=buttons|eq=list|eq=cta_url", func(validator.FieldError) string {
+		return "is not a valid interactive type"
+	})
 }
 
 // MsgTopic is the topic, as required by some channel types
@@ -30,6 +33,16 @@ const (
 	MsgTopicAgent    MsgTopic = "agent"
 )
 
+// InteractiveType is the type of interactive content in an outgoing message, as supported by some channel types
+type InteractiveType string
+
+// possible interactive type values
+const (
+	InteractiveTypeButtons InteractiveType = "buttons"
+	InteractiveTypeList    InteractiveType = "list"
+	InteractiveTypeCTAURL  InteractiveType = "cta_url"
+)
+
 // BaseMsg represents a incoming or outgoing message with the session contact
 type BaseMsg struct {
 	UUID_        MsgUUID                  `json:"uuid"`
@@ -45,16 +58,18 @@ type MsgIn struct {
 	BaseMsg
 
 	ExternalID_ string `json:"external_id,omitempty"`
+	ButtonID_   string `json:"button_id,omitempty"`
 }
 
 // MsgOut represents a outgoing message to the session contact
 type MsgOut struct {
 	BaseMsg
 
-	QuickReplies_ []string       `json:"quick_replies,omitempty"`
-	Templating_   *MsgTemplating `json:"templating,omitempty"`
-	Topic_        MsgTopic       `json:"topic,omitempty"`
-	TextLanguage  envs.Language  `json:"text_language,omitempty"`
+	QuickReplies_ []string        `json:"quick_replies,omitempty"`
+	Interactive_  *MsgInteractive `json:"interactive,omitempty"`
+	Templating_   *MsgTemplating  `json:"templating,omitempty"`
+	Topic_        MsgTopic        `json:"topic,omitempty"`
+	TextLanguage  envs.Language   `json:"text_language,omitempty"`
 }
 
 // NewMsgIn creates a new incoming message
@@ -71,7 +86,7 @@ func NewMsgIn(uuid MsgUUID, urn urns.URN, channel *assets.ChannelReference, text
 }
 
 // NewMsgOut creates a new outgoing message
-func NewMsgOut(urn urns.URN, channel *assets.ChannelReference, text string, attachments []utils.Attachment, quickReplies []string, templating *MsgTemplating, topic MsgTopic) *MsgOut {
+func NewMsgOut(urn urns.URN, channel *assets.ChannelReference, text string, attachments []utils.Attachment, quickReplies []string, interactive *MsgInteractive, templating *MsgTemplating, topic MsgTopic) *MsgOut {
 	return &MsgOut{
 		BaseMsg: BaseMsg{
 			UUID_:        MsgUUID(uuids.New()),
@@ -81,6 +96,7 @@ func NewMsgOut(urn urns.URN, channel *assets.ChannelReference, text string, atta
 			Attachments_: attachments,
 		},
 		QuickReplies_: quickReplies,
+		Interactive_:  interactive,
 		Templating_:   templating,
 		Topic_:        topic,
 	}
@@ -102,6 +118,7 @@ func NewIVRMsgOut(urn urns.URN, channel *assets.ChannelReference, text string, t
 			Attachments_: attachments,
 		},
 		QuickReplies_: nil,
+		Interactive_:  nil,
 		Templating_:   nil,
 		Topic_:        NilMsgTopic,
 		TextLanguage:  textLanguage,
@@ -138,15 +155,48 @@ func (m *MsgIn) ExternalID() string { return m.ExternalID_ }
 // SetExternalID sets the external ID of this message
 func (m *MsgIn) SetExternalID(id string) { m.ExternalID_ = id }
 
+// ButtonID returns the ID of the interactive button or list row that the contact selected (if any)
+func (m *MsgIn) ButtonID() string { return m.ButtonID_ }
+
+// SetButtonID sets the ID of the interactive button or list row that the contact selected
+func (m *MsgIn) SetButtonID(id string) { m.ButtonID_ = id }
+
 // QuickReplies returns the quick replies of this outgoing message
 func (m *MsgOut) QuickReplies() []string { return m.QuickReplies_ }
 
+// Interactive returns the interactive content of this outgoing message (if any)
+func (m *MsgOut) Interactive() *MsgInteractive { return m.Interactive_ }
+
 // Templating returns the templating to use to send this message (if any)
 func (m *MsgOut) Templating() *MsgTemplating { return m.Templating_ }
 
 // Topic returns the topic to use to send this message (if any)
 func (m *MsgOut) Topic() MsgTopic { return m.Topic_ }
 
+// MsgButton is a reply button or list row in interactive content which the contact can select, and whose ID is
+// included in the incoming message when they do
+type MsgButton struct {
+	ID          string `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+}
+
+// MsgListSection is a section of rows in an interactive list
+type MsgListSection struct {
+	Title string       `json:"title,omitempty"`
+	Rows  []*MsgButton `json:"rows"`
+}
+
+// MsgInteractive is interactive content which should be sent with a message. Depending on its type, this has reply
+// buttons, a list of sections of rows which is opened with a button, or a button which opens a URL.
+type MsgInteractive struct {
+	Type     InteractiveType   `json:"type"`
+	Buttons  []*MsgButton      `json:"buttons,omitempty"`
+	Button   string            `json:"button,omitempty"`
+	Sections []*MsgListSection `json:"sections,omitempty"`
+	URL      string            `json:"url,omitempty"`
+}
+
 // MsgTemplating represents any substituted message template that should be applied when sending this message
 type MsgTemplating struct {
 	Template_  *assets.TemplateReference `json:"template"`
@@ -29,6 +29,7 @@ func TestMsgIn(t *testing.T) {
 	)
 	msg.SetID(123)
 	msg.SetExternalID("EX346436734")
+	msg.SetButtonID("yes")
 
 	// test marshaling our msg
 	marshaled, err := jsonx.Marshal(msg)
@@ -43,7 +44,8 @@ func TestMsgIn(t *testing.T) {
 		"text":"Hi there",
 		"attachments":["image/jpeg:https://example.com/test.jpg",
 		"audio/mp3:https://example.com/test.mp3"],
-		"external_id":"EX346436734"
+		"external_id":"EX346436734",
+		"button_id":"yes"
 	}`), marshaled, "JSON mismatch")
 
 	// test unmarshaling
@@ -56,6 +58,7 @@ func TestMsgIn(t *testing.T) {
 	assert.Equal(t, "Hi there", msg.Text())
 	assert.Equal(t, assets.ChannelUUID("61f38f46-a856-4f90-899e-905691784159"), msg.Channel().UUID)
 	assert.Equal(t, "My Android", msg.Channel().Name)
+	assert.Equal(t, "yes", msg.ButtonID())
 	assert.Equal(t, "EX346436734", msg.ExternalID())
 }
 
@@ -73,6 +76,7 @@ func TestMsgOut(t *testing.T) {
 		},
 		nil,
 		nil,
+		nil,
 		flows.MsgTopicAgent,
 	)
 
@@ -88,6 +92,47 @@ func TestMsgOut(t *testing.T) {
 		"attachments": ["image/jpeg:https://example.com/test.jpg", "audio/mp3:https://example.com/test.mp3"],
 		"topic": "agent"
 	}`), marshaled, "JSON mismatch")
+
+	// and one with interactive list content
+	msg = flows.NewMsgOut(
+		urns.URN("tel:+1234567890"),
+		nil,
+		"Pick a size",
+		nil,
+		nil,
+		&flows.MsgInteractive{
+			Type:   flows.InteractiveTypeList,
+			Button: "Sizes",
+			Sections: []*flows.MsgListSection{
+				{Title: "Shirts", Rows: []*flows.MsgButton{{ID: "shirt_s", Title: "Small"}, {ID: "shirt_l", Title: "Large", Description: "Extra roomy"}}},
+			},
+		},
+		nil,
+		flows.NilMsgTopic,
+	)
+	assert.Equal(t, flows.InteractiveTypeList, msg.Interactive().Type)
+
+	marshaled, err = jsonx.Marshal(msg)
+	require.NoError(t, err)
+
+	test.AssertEqualJSON(t, []byte(`{
+		"uuid": "e7187099-7d38-4f60-955c-325957214c42",
+		"urn": "tel:+1234567890",
+		"text": "Pick a size",
+		"interactive": {
+			"type": "list",
+			"button": "Sizes",
+			"sections": [
+				{
+					"title": "Shirts",
+					"rows": [
+						{"id": "shirt_s", "title": "Small"},
+						{"id": "shirt_l", "title": "Large", "description": "Extra roomy"}
+					]
+				}
+			]
+		}
+	}`), marshaled, "JSON mismatch")
 }
 
 func TestIVRMsgOut(t *testing.T) {
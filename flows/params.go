@@ -0,0 +1,58 @@
+package flows
+
+import (
+	"github.com/nyaruka/goflow/envs"
+	"github.com/nyaruka/goflow/excellent/types"
+	"github.com/nyaruka/goflow/utils"
+
+	"github.com/pkg/errors"
+	validator "gopkg.in/go-playground/validator.v9"
+)
+
+func init() {
+	utils.RegisterValidatorAlias("param_type", "eq=text|eq=number|eq=datetime|eq=boolean", func(validator.FieldError) string {
+		return "is not a valid parameter type"
+	})
+}
+
+// ParamType is the type of a flow parameter or output
+type ParamType string
+
+// parameter types
+const (
+	ParamTypeText     ParamType = "text"
+	ParamTypeNumber   ParamType = "number"
+	ParamTypeDatetime ParamType = "datetime"
+	ParamTypeBoolean  ParamType = "boolean"
+)
+
+// Convert converts the given value to this type
+func (t ParamType) Convert(env envs.Environment, value types.XValue) (types.XValue, error) {
+	switch t {
+	case ParamTypeNumber:
+		return types.ToXNumber(env, value)
+	case ParamTypeDatetime:
+		return types.ToXDateTime(env, value)
+	case ParamTypeBoolean:
+		return types.ToXBoolean(value)
+	case ParamTypeText:
+		return types.ToXText(env, value)
+	}
+	return nil, errors.Errorf("unknown parameter type '%s'", t)
+}
+
+// FlowParam is an input parameter declared by a flow which can be passed a value by the flow which enters it
+type FlowParam struct {
+	Key      string    `json:"key" validate:"required"`
+	Name     string    `json:"name" validate:"required"`
+	Type     ParamType `json:"type" validate:"required,param_type"`
+	Required bool      `json:"required,omitempty"`
+}
+
+// FlowOutput is an output declared by a flow, whose value is the result with the same key in a run of the flow, and
+// which is saved as a result with the output's name on the parent run when that run completes
+type FlowOutput struct {
+	Key  string    `json:"key" validate:"required"`
+	Name string    `json:"name" validate:"required"`
+	Type ParamType `json:"type" validate:"required,param_type"`
+}
@@ -68,6 +68,9 @@ func (r *baseRouter) ResultName() string { return r.resultName }
 
 // EnumerateTemplates enumerates all expressions on this object and its children
 func (r *baseRouter) EnumerateTemplates(localization flows.Localization, include func(envs.Language, string)) {
+	if r.wait != nil {
+		r.wait.EnumerateTemplates(localization, include)
+	}
 }
 
 // EnumerateDependencies enumerates all dependencies on this object
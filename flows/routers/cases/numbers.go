@@ -0,0 +1,249 @@
+package cases
+
+import (
+	"strings"
+
+	"github.com/nyaruka/goflow/envs"
+	"github.com/nyaruka/goflow/utils"
+
+	"github.com/shopspring/decimal"
+)
+
+// NumberGrammar finds numbers which are written as words in a particular language
+type NumberGrammar interface {
+	// FindNumbers finds numbers in the given words, which are lowercase and have had accents folded
+	FindNumbers(words []string) []decimal.Decimal
+}
+
+// RegisterNumberGrammar registers the grammar used to find numbers written as words in the given language
+func RegisterNumberGrammar(lang envs.Language, grammar NumberGrammar) {
+	numberGrammars[lang] = grammar
+}
+
+// finds numbers written as words in the given text using the grammar of the given language if there is one
+func findWrittenNumbers(text string, lang envs.Language) []decimal.Decimal {
+	grammar := numberGrammars[lang]
+	if grammar == nil {
+		return nil
+	}
+
+	words := utils.TokenizeString(utils.FoldAccents(strings.ToLower(text), string(lang)))
+	return grammar.FindNumbers(words)
+}
+
+// NumberWords is a number grammar defined by the words which a language uses to write numbers. Words must be lowercase
+// and without accents.
+type NumberWords struct {
+	// words, or phrases of space separated words, which have a value, e.g. "five" or "quatre vingt"
+	Values map[string]int64
+
+	// words which multiply the value before them, or after them if MultiplierFirst is set, e.g. "hundred"
+	Multipliers map[string]int64
+
+	// words which can join the parts of a number, e.g. "and"
+	Conjunctions []string
+
+	// whether multipliers come before the values they multiply, e.g. "mia tano" is 500 in Swahili
+	MultiplierFirst bool
+}
+
+// FindNumbers finds numbers in the given words
+func (w *NumberWords) FindNumbers(words []string) []decimal.Decimal {
+	maxPhrase := 1
+	for phrase := range w.Values {
+		if n := strings.Count(phrase, " ") + 1; n > maxPhrase {
+			maxPhrase = n
+		}
+	}
+
+	// matches the longest value phrase at the given position, returning its value and number of words
+	matchValue := func(i int) (int64, int) {
+		for n := maxPhrase; n > 0; n-- {
+			if i+n <= len(words) {
+				if value, found := w.Values[strings.Join(words[i:i+n], " ")]; found {
+					return value, n
+				}
+			}
+		}
+		return 0, 0
+	}
+	isNumberWord := func(i int) bool {
+		_, n := matchValue(i)
+		_, isMultiplier := w.Multipliers[words[i]]
+		return n > 0 || isMultiplier
+	}
+
+	numbers := make([]decimal.Decimal, 0)
+
+	// the total of the number so far, the value of the group of words since the last large multiplier, and the last
+	// part added which determines whether the next value continues this number or starts a new one
+	var total, group, last int64
+	inNumber := false
+
+	endNumber := func() {
+		if inNumber {
+			numbers = append(numbers, decimal.NewFromInt(total+group))
+		}
+		total, group, last, inNumber = 0, 0, 0, false
+	}
+
+	for i := 0; i < len(words); {
+		// conjunctions are only part of a number if they join two number words
+		if inNumber && utils.StringSliceContains(w.Conjunctions, words[i], true) && i+1 < len(words) && isNumberWord(i+1) {
+			i++
+			continue
+		}
+
+		if value, n := matchValue(i); n > 0 {
+			// a value can only be added to a larger one, e.g. twenty five, otherwise it's the start of another number
+			if inNumber && numDigits(value) >= numDigits(last) {
+				endNumber()
+			}
+
+			group += value
+			last = value
+			inNumber = true
+			i += n
+			continue
+		}
+
+		multiplier, isMultiplier := w.Multipliers[words[i]]
+		if !isMultiplier {
+			endNumber()
+			i++
+			continue
+		}
+
+		if w.MultiplierFirst {
+			part, n := multiplier, 1
+			if value, vn := matchValue(i + 1); vn > 0 && value < multiplier {
+				part = multiplier * value
+				n += vn
+			}
+
+			if inNumber && part >= last {
+				endNumber()
+			}
+
+			group += part
+			last = part
+			inNumber = true
+			i += n
+			continue
+		}
+
+		if group == 0 {
+			group = 1
+		}
+
+		if multiplier < 1000 {
+			group *= multiplier
+			last = group
+		} else {
+			total += group * multiplier
+			group = 0
+			last = multiplier
+		}
+		inNumber = true
+		i++
+	}
+
+	endNumber()
+
+	return numbers
+}
+
+func numDigits(n int64) int {
+	digits := 1
+	for n >= 10 {
+		n /= 10
+		digits++
+	}
+	return digits
+}
+
+// builds a map of words to values where each word's value is its index in the given slice plus the given offset
+func numberWordValues(offset int64, step int64, words ...string) map[string]int64 {
+	values := make(map[string]int64, len(words))
+	for i, word := range words {
+		if word != "" {
+			values[word] = offset + int64(i)*step
+		}
+	}
+	return values
+}
+
+// merges the given maps of words to values
+func mergeNumberWords(maps ...map[string]int64) map[string]int64 {
+	merged := make(map[string]int64)
+	for _, m := range maps {
+		for word, value := range m {
+			merged[word] = value
+		}
+	}
+	return merged
+}
+
+// the number words of French, where 70-79 and 80-99 are written as 60+10-19, 4x20 and 4x20+10-19
+func frenchNumberValues() map[string]int64 {
+	teens := []string{"dix", "onze", "douze", "treize", "quatorze", "quinze", "seize"}
+	values := mergeNumberWords(
+		numberWordValues(0, 1, "zero", "un", "deux", "trois", "quatre", "cinq", "six", "sept", "huit", "neuf"),
+		numberWordValues(10, 1, teens...),
+		numberWordValues(20, 10, "vingt", "trente", "quarante", "cinquante", "soixante"),
+		map[string]int64{"une": 1, "quatre vingt": 80, "quatre vingts": 80, "soixante et onze": 71},
+	)
+	for i, teen := range teens {
+		values["soixante "+teen] = int64(70 + i)
+		values["quatre vingt "+teen] = int64(90 + i)
+	}
+	return values
+}
+
+var numberGrammars = map[envs.Language]NumberGrammar{
+	"eng": &NumberWords{
+		Values: mergeNumberWords(
+			numberWordValues(0, 1, "zero", "one", "two", "three", "four", "five", "six", "seven", "eight", "nine", "ten", "eleven", "twelve", "thirteen", "fourteen", "fifteen", "sixteen", "seventeen", "eighteen", "nineteen"),
+			numberWordValues(20, 10, "twenty", "thirty", "forty", "fifty", "sixty", "seventy", "eighty", "ninety"),
+		),
+		Multipliers:  map[string]int64{"hundred": 100, "thousand": 1000, "million": 1000000, "billion": 1000000000},
+		Conjunctions: []string{"and"},
+	},
+	"fra": &NumberWords{
+		Values:       frenchNumberValues(),
+		Multipliers:  map[string]int64{"cent": 100, "cents": 100, "mille": 1000, "million": 1000000, "millions": 1000000, "milliard": 1000000000, "milliards": 1000000000},
+		Conjunctions: []string{"et"},
+	},
+	"spa": &NumberWords{
+		Values: mergeNumberWords(
+			numberWordValues(0, 1, "cero", "uno", "dos", "tres", "cuatro", "cinco", "seis", "siete", "ocho", "nueve", "diez", "once", "doce", "trece", "catorce", "quince", "dieciseis", "diecisiete", "dieciocho", "diecinueve"),
+			numberWordValues(20, 1, "veinte", "veintiuno", "veintidos", "veintitres", "veinticuatro", "veinticinco", "veintiseis", "veintisiete", "veintiocho", "veintinueve"),
+			numberWordValues(30, 10, "treinta", "cuarenta", "cincuenta", "sesenta", "setenta", "ochenta", "noventa"),
+			numberWordValues(100, 100, "ciento", "doscientos", "trescientos", "cuatrocientos", "quinientos", "seiscientos", "setecientos", "ochocientos", "novecientos"),
+			numberWordValues(200, 100, "doscientas", "trescientas", "cuatrocientas", "quinientas", "seiscientas", "setecientas", "ochocientas", "novecientas"),
+			map[string]int64{"un": 1, "una": 1, "veintiun": 21, "veintiuna": 21, "cien": 100},
+		),
+		Multipliers:  map[string]int64{"mil": 1000, "millon": 1000000, "millones": 1000000},
+		Conjunctions: []string{"y"},
+	},
+	"por": &NumberWords{
+		Values: mergeNumberWords(
+			numberWordValues(0, 1, "zero", "um", "dois", "tres", "quatro", "cinco", "seis", "sete", "oito", "nove", "dez", "onze", "doze", "treze", "catorze", "quinze", "dezesseis", "dezessete", "dezoito", "dezenove"),
+			numberWordValues(20, 10, "vinte", "trinta", "quarenta", "cinquenta", "sessenta", "setenta", "oitenta", "noventa"),
+			numberWordValues(100, 100, "cento", "duzentos", "trezentos", "quatrocentos", "quinhentos", "seiscentos", "setecentos", "oitocentos", "novecentos"),
+			numberWordValues(200, 100, "duzentas", "trezentas", "quatrocentas", "quinhentas", "seiscentas", "setecentas", "oitocentas", "novecentas"),
+			map[string]int64{"uma": 1, "duas": 2, "quatorze": 14, "dezasseis": 16, "dezassete": 17, "dezanove": 19, "cem": 100},
+		),
+		Multipliers:  map[string]int64{"mil": 1000, "milhao": 1000000, "milhoes": 1000000},
+		Conjunctions: []string{"e"},
+	},
+	"swa": &NumberWords{
+		Values: mergeNumberWords(
+			numberWordValues(0, 1, "sifuri", "moja", "mbili", "tatu", "nne", "tano", "sita", "saba", "nane", "tisa", "kumi"),
+			numberWordValues(20, 10, "ishirini", "thelathini", "arobaini", "hamsini", "sitini", "sabini", "themanini", "tisini"),
+		),
+		Multipliers:     map[string]int64{"mia": 100, "elfu": 1000, "laki": 100000, "milioni": 1000000},
+		Conjunctions:    []string{"na"},
+		MultiplierFirst: true,
+	},
+}
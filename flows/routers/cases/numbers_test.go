@@ -0,0 +1,113 @@
+package cases_test
+
+import (
+	"testing"
+
+	"github.com/nyaruka/goflow/envs"
+	"github.com/nyaruka/goflow/excellent/types"
+	"github.com/nyaruka/goflow/flows/routers/cases"
+	"github.com/nyaruka/goflow/test"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrittenNumbers(t *testing.T) {
+	tcs := []struct {
+		language envs.Language
+		text     string
+		expected string // empty if no number should be found
+	}{
+		{"eng", "twenty five", "25"},
+		{"eng", "I am Twenty-Five years old", "25"},
+		{"eng", "zero", "0"},
+		{"eng", "a hundred", "100"},
+		{"eng", "one hundred and five", "105"},
+		{"eng", "two thousand three hundred and forty two", "2342"},
+		{"eng", "five hundred thousand", "500000"},
+		{"eng", "one two three", "1"},
+		{"eng", "fifteen and change", "15"},
+		{"eng", "none of your business", ""},
+
+		{"fra", "vingt-cinq", "25"},
+		{"fra", "vingt et un", "21"},
+		{"fra", "soixante-dix", "70"},
+		{"fra", "soixante et onze", "71"},
+		{"fra", "soixante-dix-sept", "77"},
+		{"fra", "quatre-vingts", "80"},
+		{"fra", "quatre-vingt-douze", "92"},
+		{"fra", "quatre-vingt-dix-neuf", "99"},
+		{"fra", "deux cent trente", "230"},
+		{"fra", "mille neuf cent quatre-vingt-quatre", "1984"},
+		{"fra", "j'ai trente ans", "30"},
+
+		{"spa", "veinticinco", "25"},
+		{"spa", "veintiún", "21"},
+		{"spa", "treinta y dos", "32"},
+		{"spa", "dieciséis", "16"},
+		{"spa", "ciento cinco", "105"},
+		{"spa", "cien mil", "100000"},
+		{"spa", "mil novecientos noventa y nueve", "1999"},
+		{"spa", "dos millones", "2000000"},
+
+		{"por", "vinte e cinco", "25"},
+		{"por", "três", "3"},
+		{"por", "cento e vinte", "120"},
+		{"por", "mil e quinhentos", "1500"},
+		{"por", "duas mil", "2000"},
+
+		{"swa", "ishirini na tano", "25"},
+		{"swa", "kumi na moja", "11"},
+		{"swa", "mia tano", "500"},
+		{"swa", "mia tatu na ishirini na tano", "325"},
+		{"swa", "elfu mbili mia tatu na tano", "2305"},
+		{"swa", "mimi na wewe", ""},
+
+		{"kin", "makumyabiri na gatanu", ""}, // no grammar for this language
+	}
+
+	for _, tc := range tcs {
+		env := envs.NewBuilder().WithAllowedLanguages([]envs.Language{tc.language}).WithWrittenNumbers(true).Build()
+
+		expected := cases.FalseResult
+		if tc.expected != "" {
+			expected = cases.NewTrueResult(types.RequireXNumberFromString(tc.expected))
+		}
+
+		test.AssertXEqual(t, expected, cases.HasNumber(env, types.NewXText(tc.text)), "result mismatch for '%s' in %s", tc.text, tc.language)
+	}
+
+	// written numbers are only recognized if enabled in the environment
+	env := envs.NewBuilder().WithAllowedLanguages([]envs.Language{"eng"}).Build()
+	test.AssertXEqual(t, cases.FalseResult, cases.HasNumber(env, types.NewXText("twenty five")))
+
+	// numbers written with digits take precedence
+	env = envs.NewBuilder().WithAllowedLanguages([]envs.Language{"eng"}).WithWrittenNumbers(true).Build()
+	test.AssertXEqual(t, cases.NewTrueResult(types.RequireXNumberFromString("7")), cases.HasNumber(env, types.NewXText("twenty five or 7")))
+
+	// and other number tests use written numbers too
+	test.AssertXEqual(t, cases.NewTrueResult(types.RequireXNumberFromString("42")), cases.HasNumberBetween(env, types.NewXText("three or forty two"), types.NewXNumberFromInt(40), types.NewXNumberFromInt(50)))
+	test.AssertXEqual(t, cases.FalseResult, cases.HasNumberGT(env, types.NewXText("three or forty two"), types.NewXNumberFromInt(50)))
+}
+
+func TestNumberWords(t *testing.T) {
+	grammar := &cases.NumberWords{
+		Values:       map[string]int64{"rimwe": 1, "kabiri": 2, "gatatu": 3, "icumi": 10},
+		Multipliers:  map[string]int64{"ijana": 100},
+		Conjunctions: []string{"na"},
+	}
+
+	dec := decimal.NewFromInt
+
+	assert.Equal(t, []decimal.Decimal{}, grammar.FindNumbers([]string{}))
+	assert.Equal(t, []decimal.Decimal{dec(13)}, grammar.FindNumbers([]string{"icumi", "na", "gatatu"}))
+	assert.Equal(t, []decimal.Decimal{dec(2), dec(3)}, grammar.FindNumbers([]string{"kabiri", "cyangwa", "gatatu"}))
+	assert.Equal(t, []decimal.Decimal{dec(1), dec(2)}, grammar.FindNumbers([]string{"rimwe", "kabiri", "na"}))
+	assert.Equal(t, []decimal.Decimal{dec(200)}, grammar.FindNumbers([]string{"kabiri", "ijana"}))
+
+	cases.RegisterNumberGrammar("kin", grammar)
+	defer cases.RegisterNumberGrammar("kin", nil)
+
+	env := envs.NewBuilder().WithAllowedLanguages([]envs.Language{"kin"}).WithWrittenNumbers(true).Build()
+	test.AssertXEqual(t, cases.NewTrueResult(types.RequireXNumberFromString("13")), cases.HasNumber(env, types.NewXText("Icumi na gatatu")))
+}
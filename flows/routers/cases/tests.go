@@ -5,6 +5,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/nyaruka/gocommon/dates"
 	"github.com/nyaruka/gocommon/jsonx"
@@ -47,6 +48,9 @@ var XTESTS = map[string]types.XFunction{
 	"has_text":        functions.OneTextFunction(HasText),
 	"has_pattern":     functions.TwoTextFunction(HasPattern),
 
+	"has_fuzzy_match":  functions.MinAndMaxArgsCheck(2, 3, HasFuzzyMatch),
+	"has_similar_text": functions.MinAndMaxArgsCheck(2, 3, HasSimilarText),
+
 	"has_number":         functions.OneTextFunction(HasNumber),
 	"has_number_between": functions.ThreeArgFunction(HasNumberBetween),
 	"has_number_lt":      functions.TextAndNumberFunction(HasNumberLT),
@@ -326,8 +330,104 @@ func HasPattern(env envs.Environment, text types.XText, pattern types.XText) typ
 	return FalseResult
 }
 
+// HasFuzzyMatch tests whether any of the words in `text` is a close misspelling of any of the `words`
+//
+// Words are compared ignoring case and accents, and match if the number of insertions, deletions, substitutions or
+// transpositions of adjacent characters needed to turn one into the other is no more than `max_distance`. If that
+// isn't provided, it defaults to 1 for words of up to 5 characters and 2 for longer words. The closest word from
+// `words` is returned as the match, with the distance and the word from `text` in extra.
+//
+//   @(has_fuzzy_match("yess please", "yes no")) -> true
+//   @(has_fuzzy_match("yess please", "yes no").match) -> yes
+//   @(has_fuzzy_match("yess please", "yes no").extra) -> {distance: 1, word: yess}
+//   @(has_fuzzy_match("I live in Nairobbi", "Nairobi Mombasa").match) -> Nairobi
+//   @(has_fuzzy_match("Café", "cafe", 0)) -> true
+//   @(has_fuzzy_match("maybe", "yes no")) -> false
+//   @(has_fuzzy_match("yesss", "yes", 1)) -> false
+//   @(has_fuzzy_match("yesss", "yes", 2).extra.distance) -> 2
+//   @(has_fuzzy_match("yes", "yes", -1)) -> ERROR
+//
+// @test has_fuzzy_match(text, words, max_distance)
+func HasFuzzyMatch(env envs.Environment, args ...types.XValue) types.XValue {
+	text, xerr := types.ToXText(env, args[0])
+	if xerr != nil {
+		return xerr
+	}
+	words, xerr := types.ToXText(env, args[1])
+	if xerr != nil {
+		return xerr
+	}
+
+	maxDistance := -1
+	if len(args) == 3 {
+		num, xerr := types.ToXNumber(env, args[2])
+		if xerr != nil {
+			return xerr
+		}
+		maxDistance = int(num.Native().IntPart())
+		if maxDistance < 0 {
+			return types.NewXErrorf("max distance can't be negative")
+		}
+	}
+
+	return testFuzzyTokens(env, text, words, func(hay, pin string) (int, bool) {
+		distance := utils.EditDistance(hay, pin)
+		return distance, distance <= fuzzyMaxDistance(pin, maxDistance)
+	})
+}
+
+// HasSimilarText tests whether any of the words in `text` sounds like any of the `words`
+//
+// Words are compared ignoring case and accents, and match if they have the same phonetic code according to the
+// `algorithm` which can be `metaphone` (the default) or `soundex`. The word from `words` which is closest in spelling
+// is returned as the match, with the distance between them and the word from `text` in extra.
+//
+//   @(has_similar_text("my name is Jon", "John Paul")) -> true
+//   @(has_similar_text("my name is Jon", "John Paul").match) -> John
+//   @(has_similar_text("fone me", "phone email").extra) -> {distance: 2, word: fone}
+//   @(has_similar_text("Robert", "Rupert")) -> false
+//   @(has_similar_text("Robert", "Rupert", "soundex")) -> true
+//   @(has_similar_text("cat", "dog")) -> false
+//   @(has_similar_text("cat", "cat", "nysiis")) -> ERROR
+//
+// @test has_similar_text(text, words, algorithm)
+func HasSimilarText(env envs.Environment, args ...types.XValue) types.XValue {
+	text, xerr := types.ToXText(env, args[0])
+	if xerr != nil {
+		return xerr
+	}
+	words, xerr := types.ToXText(env, args[1])
+	if xerr != nil {
+		return xerr
+	}
+
+	encode := utils.Metaphone
+	if len(args) == 3 {
+		algorithm, xerr := types.ToXText(env, args[2])
+		if xerr != nil {
+			return xerr
+		}
+
+		switch strings.ToLower(algorithm.Native()) {
+		case "metaphone":
+		case "soundex":
+			encode = utils.Soundex
+		default:
+			return types.NewXErrorf("algorithm must be metaphone or soundex")
+		}
+	}
+
+	return testFuzzyTokens(env, text, words, func(hay, pin string) (int, bool) {
+		code := encode(hay)
+		return utils.EditDistance(hay, pin), code != "" && code == encode(pin)
+	})
+}
+
 // HasNumber tests whether `text` contains a number
 //
+// If the environment has written numbers enabled, numbers written as words in the contact's language, e.g. "twenty
+// five", are also recognized. This applies to all the number tests.
+//
 //   @(has_number("the number is 42")) -> true
 //   @(has_number("the number is 42").match) -> 42
 //   @(has_number("العدد ٤٢").match) -> 42
@@ -836,6 +936,53 @@ func hasOnlyPhraseTest(origHays []string, hays []string, pins []string) types.XV
 	return NewTrueResult(types.NewXText(strings.Join(matches, " ")))
 }
 
+// tests whether a word in text and a word in test match, returning the distance between them
+type fuzzyTokenTest func(hay string, pin string) (int, bool)
+
+// tokenizes both text values, folding case and accents according to the environment's language, and looks for the
+// matching pair of tokens with the smallest distance, preferring earlier tokens in each when there's a tie
+func testFuzzyTokens(env envs.Environment, str types.XText, testStr types.XText, testFunc fuzzyTokenTest) types.XValue {
+	lang := string(env.DefaultLanguage())
+	fold := func(s string) string { return utils.FoldAccents(strings.ToLower(s), lang) }
+
+	origHays := utils.TokenizeString(strings.TrimSpace(str.Native()))
+	origPins := utils.TokenizeString(strings.TrimSpace(testStr.Native()))
+
+	bestHay, bestPin, bestDistance := -1, -1, 0
+
+	for i, origHay := range origHays {
+		hay := fold(origHay)
+
+		for j, origPin := range origPins {
+			distance, matched := testFunc(hay, fold(origPin))
+
+			if matched && (bestHay < 0 || distance < bestDistance) {
+				bestHay, bestPin, bestDistance = i, j, distance
+			}
+		}
+	}
+
+	if bestHay < 0 {
+		return FalseResult
+	}
+
+	return NewTrueResultWithExtra(types.NewXText(origPins[bestPin]), types.NewXObject(map[string]types.XValue{
+		"distance": types.NewXNumberFromInt(bestDistance),
+		"word":     types.NewXText(origHays[bestHay]),
+	}))
+}
+
+// gets the maximum edit distance for a fuzzy match of the given word
+func fuzzyMaxDistance(word string, maxDistance int) int {
+	if maxDistance >= 0 {
+		return maxDistance
+	}
+	if utf8.RuneCountInString(word) <= 5 {
+		return 1
+	}
+	return 2
+}
+
 //------------------------------------------------------------------------------------------
 // Numerical Test Functions
 //------------------------------------------------------------------------------------------
@@ -856,6 +1003,15 @@ func testNumber(env envs.Environment, str types.XText, testNum1 types.XNumber, t
 		}
 	}
 
+	// then look for numbers written as words in the environment's language if it has that enabled
+	if env.WrittenNumbers() {
+		for _, num := range findWrittenNumbers(str.Native(), env.DefaultLanguage()) {
+			if testFunc(num, testNum1.Native(), testNum2.Native()) {
+				return NewTrueResult(types.NewXNumber(num))
+			}
+		}
+	}
+
 	return FalseResult
 }
 
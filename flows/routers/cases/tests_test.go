@@ -28,6 +28,9 @@ var result = cases.NewTrueResult
 var resultWithExtra = cases.NewTrueResultWithExtra
 var falseResult = cases.FalseResult
 var ERROR = types.NewXErrorf("any error")
+var fuzzyExtra = func(distance int, word string) *types.XObject {
+	return types.NewXObject(map[string]types.XValue{"distance": types.NewXNumberFromInt(distance), "word": xs(word)})
+}
 
 var kgl, _ = time.LoadLocation("Africa/Kigali")
 
@@ -150,6 +153,36 @@ var testTests = []struct {
 	{"has_pattern", []types.XValue{xs("<html>x</html>"), xs(`[`)}, ERROR},
 	{"has_pattern", []types.XValue{}, ERROR},
 
+	{"has_fuzzy_match", []types.XValue{xs("yess"), xs("yes no")}, resultWithExtra(xs("yes"), fuzzyExtra(1, "yess"))},
+	{"has_fuzzy_match", []types.XValue{xs("NOO!"), xs("yes no")}, resultWithExtra(xs("no"), fuzzyExtra(1, "NOO"))},
+	{"has_fuzzy_match", []types.XValue{xs("teh answer is yes"), xs("yes no")}, resultWithExtra(xs("yes"), fuzzyExtra(0, "yes"))},
+	{"has_fuzzy_match", []types.XValue{xs("I live in Nairobbi"), xs("Nairobi Mombasa")}, resultWithExtra(xs("Nairobi"), fuzzyExtra(1, "Nairobbi"))},
+	{"has_fuzzy_match", []types.XValue{xs("Mmbasaa"), xs("Nairobi Mombasa")}, resultWithExtra(xs("Mombasa"), fuzzyExtra(2, "Mmbasaa"))},
+	{"has_fuzzy_match", []types.XValue{xs("Mmbasaa"), xs("Nairobi Mombasa"), xn("1")}, falseResult},
+	{"has_fuzzy_match", []types.XValue{xs("Kigali"), xs("Kigari"), xn("0")}, falseResult},
+	{"has_fuzzy_match", []types.XValue{xs("café crème"), xs("CAFE")}, resultWithExtra(xs("CAFE"), fuzzyExtra(0, "café"))},
+	{"has_fuzzy_match", []types.XValue{xs("yesss"), xs("yes")}, falseResult},
+	{"has_fuzzy_match", []types.XValue{xs(""), xs("yes")}, falseResult},
+	{"has_fuzzy_match", []types.XValue{xs("yes"), xs("")}, falseResult},
+	{"has_fuzzy_match", []types.XValue{xs("yes"), xs("yes"), xn("-1")}, ERROR},
+	{"has_fuzzy_match", []types.XValue{xs("yes"), xs("yes"), xs("foo")}, ERROR},
+	{"has_fuzzy_match", []types.XValue{xs("yes"), ERROR}, ERROR},
+	{"has_fuzzy_match", []types.XValue{ERROR, xs("yes")}, ERROR},
+	{"has_fuzzy_match", []types.XValue{xs("yes")}, ERROR},
+
+	{"has_similar_text", []types.XValue{xs("my name is Jon"), xs("John Paul")}, resultWithExtra(xs("John"), fuzzyExtra(1, "Jon"))},
+	{"has_similar_text", []types.XValue{xs("fone me"), xs("phone email")}, resultWithExtra(xs("phone"), fuzzyExtra(2, "fone"))},
+	{"has_similar_text", []types.XValue{xs("Smyth"), xs("Smith Jones"), xs("METAPHONE")}, resultWithExtra(xs("Smith"), fuzzyExtra(1, "Smyth"))},
+	{"has_similar_text", []types.XValue{xs("Robert"), xs("Rupert")}, falseResult},
+	{"has_similar_text", []types.XValue{xs("Robert"), xs("Rupert"), xs("soundex")}, resultWithExtra(xs("Rupert"), fuzzyExtra(2, "Robert"))},
+	{"has_similar_text", []types.XValue{xs("Kathryn"), xs("Catherine")}, resultWithExtra(xs("Catherine"), fuzzyExtra(4, "Kathryn"))},
+	{"has_similar_text", []types.XValue{xs("Ñairobi"), xs("Nairobi")}, resultWithExtra(xs("Nairobi"), fuzzyExtra(0, "Ñairobi"))},
+	{"has_similar_text", []types.XValue{xs("123 ?"), xs("123 ?")}, falseResult},
+	{"has_similar_text", []types.XValue{xs("cat"), xs("dog")}, falseResult},
+	{"has_similar_text", []types.XValue{xs("cat"), xs("cat"), xs("nysiis")}, ERROR},
+	{"has_similar_text", []types.XValue{xs("cat"), ERROR}, ERROR},
+	{"has_similar_text", []types.XValue{xs("cat")}, ERROR},
+
 	{"has_number", []types.XValue{xs("the number 10")}, result(xn("10"))},
 	{"has_number", []types.XValue{xs("the number -10")}, result(xn("-10"))},
 	{"has_number", []types.XValue{xs("1-15")}, result(xn("1"))},
@@ -492,6 +525,27 @@ func TestTests(t *testing.T) {
 	}
 }
 
+func TestFuzzyTestsFoldAccentsByLanguage(t *testing.T) {
+	tcs := []struct {
+		language envs.Language
+		text     string
+		words    string
+		expected types.XValue
+	}{
+		{"eng", "Müller", "muller mueller", resultWithExtra(xs("muller"), fuzzyExtra(0, "Müller"))},
+		{"deu", "Müller", "muller mueller", resultWithExtra(xs("mueller"), fuzzyExtra(0, "Müller"))},
+		{"deu", "Strasse", "straße", resultWithExtra(xs("straße"), fuzzyExtra(0, "Strasse"))},
+		{"dan", "Håkon", "hakon haakon", resultWithExtra(xs("haakon"), fuzzyExtra(0, "Håkon"))},
+	}
+
+	for _, tc := range tcs {
+		env := envs.NewBuilder().WithAllowedLanguages([]envs.Language{tc.language}).Build()
+
+		result := cases.HasFuzzyMatch(env, xs(tc.text), xs(tc.words), xn("0"))
+		test.AssertXEqual(t, tc.expected, result, "result mismatch for %s in %s", tc.text, tc.language)
+	}
+}
+
 func TestEvaluateTemplate(t *testing.T) {
 	vars := types.NewXObject(map[string]types.XValue{
 		"int1":   types.NewXNumberFromInt(1),
@@ -0,0 +1,92 @@
+package routers
+
+import (
+	"encoding/json"
+
+	"github.com/nyaruka/gocommon/jsonx"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/utils"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	registerType(TypeFork, readForkRouter)
+}
+
+// TypeFork is the type for a fork router
+const TypeFork string = "fork"
+
+// ForkRouter is a router which rather than picking a single exit, forks execution into a branch for each of its
+// categories. Branches are executed in parallel, with calls to services overlapping, until they reach a node with a
+// join router, where execution continues once every branch has arrived. Branches share the run and so any results
+// they save are merged in the run.
+type ForkRouter struct {
+	baseRouter
+}
+
+// NewFork creates a new fork router
+func NewFork(categories []flows.Category) *ForkRouter {
+	return &ForkRouter{newBaseRouter(TypeFork, nil, "", categories)}
+}
+
+// Branches returns the exits which start each branch
+func (r *ForkRouter) Branches() []flows.ExitUUID {
+	exits := make([]flows.ExitUUID, 0, len(r.categories))
+	seen := make(map[flows.ExitUUID]bool, len(r.categories))
+
+	for _, c := range r.categories {
+		if !seen[c.ExitUUID()] {
+			exits = append(exits, c.ExitUUID())
+			seen[c.ExitUUID()] = true
+		}
+	}
+	return exits
+}
+
+// Validate validates that the fields on this router are valid
+func (r *ForkRouter) Validate(flow flows.Flow, exits []flows.Exit) error {
+	if r.wait != nil {
+		return errors.New("fork routers can't have a wait")
+	}
+	if r.resultName != "" {
+		return errors.New("fork routers can't save a result")
+	}
+
+	return r.validate(flow, exits)
+}
+
+// Route determines which exit to take from a node
+func (r *ForkRouter) Route(run flows.FlowRun, step flows.Step, logEvent flows.EventCallback) (flows.ExitUUID, error) {
+	return "", errors.New("fork routers don't pick a single exit")
+}
+
+//------------------------------------------------------------------------------------------
+// JSON Encoding / Decoding
+//------------------------------------------------------------------------------------------
+
+func readForkRouter(data json.RawMessage) (flows.Router, error) {
+	e := &baseRouterEnvelope{}
+	if err := utils.UnmarshalAndValidate(data, e); err != nil {
+		return nil, err
+	}
+
+	r := &ForkRouter{}
+
+	if err := r.unmarshal(e); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// MarshalJSON marshals this router into JSON
+func (r *ForkRouter) MarshalJSON() ([]byte, error) {
+	e := &baseRouterEnvelope{}
+
+	if err := r.marshal(e); err != nil {
+		return nil, err
+	}
+
+	return jsonx.Marshal(e)
+}
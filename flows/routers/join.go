@@ -0,0 +1,78 @@
+package routers
+
+import (
+	"encoding/json"
+
+	"github.com/nyaruka/gocommon/jsonx"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/utils"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	registerType(TypeJoin, readJoinRouter)
+}
+
+// TypeJoin is the type for a join router
+const TypeJoin string = "join"
+
+// JoinRouter is a router which joins the branches created by a fork router. The engine waits for every branch to
+// reach the node before executing it, after which it exits out its only category.
+type JoinRouter struct {
+	baseRouter
+}
+
+// NewJoin creates a new join router
+func NewJoin(resultName string, category flows.Category) *JoinRouter {
+	return &JoinRouter{newBaseRouter(TypeJoin, nil, resultName, []flows.Category{category})}
+}
+
+// Validate validates that the fields on this router are valid
+func (r *JoinRouter) Validate(flow flows.Flow, exits []flows.Exit) error {
+	if r.wait != nil {
+		return errors.New("join routers can't have a wait")
+	}
+	if len(r.categories) != 1 {
+		return errors.New("join routers must have a single category")
+	}
+
+	return r.validate(flow, exits)
+}
+
+// Route determines which exit to take from a node
+func (r *JoinRouter) Route(run flows.FlowRun, step flows.Step, logEvent flows.EventCallback) (flows.ExitUUID, error) {
+	category := r.categories[0]
+
+	return r.routeToCategory(run, step, category.UUID(), category.Name(), "", nil, logEvent)
+}
+
+//------------------------------------------------------------------------------------------
+// JSON Encoding / Decoding
+//------------------------------------------------------------------------------------------
+
+func readJoinRouter(data json.RawMessage) (flows.Router, error) {
+	e := &baseRouterEnvelope{}
+	if err := utils.UnmarshalAndValidate(data, e); err != nil {
+		return nil, err
+	}
+
+	r := &JoinRouter{}
+
+	if err := r.unmarshal(e); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// MarshalJSON marshals this router into JSON
+func (r *JoinRouter) MarshalJSON() ([]byte, error) {
+	e := &baseRouterEnvelope{}
+
+	if err := r.marshal(e); err != nil {
+		return nil, err
+	}
+
+	return jsonx.Marshal(e)
+}
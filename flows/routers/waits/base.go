@@ -3,6 +3,7 @@ package waits
 import (
 	"encoding/json"
 
+	"github.com/nyaruka/goflow/envs"
 	"github.com/nyaruka/goflow/flows"
 	"github.com/nyaruka/goflow/utils"
 
@@ -51,6 +52,10 @@ func (w *baseWait) Type() string { return w.type_ }
 // Timeout returns the timeout of this wait or nil if no timeout is set
 func (w *baseWait) Timeout() flows.Timeout { return w.timeout }
 
+// EnumerateTemplates enumerates all expressions on this object
+func (w *baseWait) EnumerateTemplates(localization flows.Localization, include func(envs.Language, string)) {
+}
+
 func (w *baseWait) resumeTypeError(r flows.Resume) error {
 	return errors.Errorf("can't end a wait of type '%s' with a resume of type '%s'", w.type_, r.Type())
 }
@@ -0,0 +1,208 @@
+package waits
+
+import (
+	"encoding/json"
+	"math"
+	"time"
+
+	"github.com/nyaruka/gocommon/dates"
+	"github.com/nyaruka/gocommon/jsonx"
+	"github.com/nyaruka/goflow/envs"
+	"github.com/nyaruka/goflow/excellent/types"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/events"
+	"github.com/nyaruka/goflow/flows/inspect"
+	"github.com/nyaruka/goflow/flows/resumes"
+	"github.com/nyaruka/goflow/utils"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	registerType(TypeDelay, readDelayWait, readActivatedDelayWait)
+}
+
+// TypeDelay is the type of our delay wait
+const TypeDelay string = "delay"
+
+// DelayWait is a wait which pauses until a datetime or for a number of seconds, and which can only be ended by a
+// timeout (or expiration). Messages received from the contact during the wait don't end it. The timeout of the wait
+// has no fixed number of seconds, as that is only known when the wait begins, but has the category that the router
+// takes when the delay is over.
+type DelayWait struct {
+	baseWait
+
+	Until_   string `json:"until,omitempty"   engine:"evaluated"`
+	Seconds_ string `json:"seconds,omitempty" engine:"evaluated"`
+}
+
+// NewDelayUntilWait creates a new delay wait which waits until the datetime that the given template evaluates to
+func NewDelayUntilWait(until string, categoryUUID flows.CategoryUUID) *DelayWait {
+	return &DelayWait{
+		baseWait: newBaseWait(TypeDelay, NewTimeout(0, categoryUUID)),
+		Until_:   until,
+	}
+}
+
+// NewDelayForWait creates a new delay wait which waits for the number of seconds that the given template evaluates to
+func NewDelayForWait(seconds string, categoryUUID flows.CategoryUUID) *DelayWait {
+	return &DelayWait{
+		baseWait: newBaseWait(TypeDelay, NewTimeout(0, categoryUUID)),
+		Seconds_: seconds,
+	}
+}
+
+// Until returns the datetime template (optional)
+func (w *DelayWait) Until() string { return w.Until_ }
+
+// Seconds returns the duration template (optional)
+func (w *DelayWait) Seconds() string { return w.Seconds_ }
+
+// AllowedFlowTypes returns the flow types which this wait is allowed to occur in
+func (w *DelayWait) AllowedFlowTypes() []flows.FlowType {
+	return []flows.FlowType{flows.FlowTypeMessaging}
+}
+
+// Begin beings waiting at this wait. If the delay is already over or can't be evaluated, no wait is activated and
+// the router should route as though the wait timed out.
+func (w *DelayWait) Begin(run flows.FlowRun, log flows.EventCallback) (flows.ActivatedWait, bool) {
+	now := dates.Now()
+
+	until, err := w.evaluateUntil(run, now)
+	if err != nil {
+		log(events.NewError(err))
+		return nil, true
+	}
+
+	timeoutSeconds := int(math.Ceil(until.Sub(now).Seconds()))
+	if timeoutSeconds <= 0 {
+		return nil, true
+	}
+
+	log(events.NewDelayWait(until, timeoutSeconds))
+
+	return NewActivatedDelayWait(until, timeoutSeconds), false
+}
+
+func (w *DelayWait) evaluateUntil(run flows.FlowRun, now time.Time) (time.Time, error) {
+	if w.Until_ != "" {
+		value, err := run.EvaluateTemplateValue(w.Until_)
+		if err != nil {
+			return time.Time{}, err
+		}
+		until, xerr := types.ToXDateTime(run.Environment(), value)
+		if xerr != nil {
+			return time.Time{}, errors.Errorf("delay evaluated to '%s' which isn't a valid datetime", types.Render(value))
+		}
+		return until.Native(), nil
+	}
+
+	value, err := run.EvaluateTemplateValue(w.Seconds_)
+	if err != nil {
+		return time.Time{}, err
+	}
+	seconds, xerr := types.ToXNumber(run.Environment(), value)
+	if xerr != nil {
+		return time.Time{}, errors.Errorf("delay evaluated to '%s' which isn't a valid number of seconds", types.Render(value))
+	}
+	return now.Add(time.Duration(seconds.Native().IntPart()) * time.Second), nil
+}
+
+// EnumerateTemplates enumerates all expressions on this object
+func (w *DelayWait) EnumerateTemplates(localization flows.Localization, include func(envs.Language, string)) {
+	inspect.Templates(w, localization, include)
+}
+
+// End ends this wait or returns an error
+func (w *DelayWait) End(resume flows.Resume) error {
+	switch resume.Type() {
+	case resumes.TypeWaitTimeout, resumes.TypeRunExpiration:
+		return nil
+	}
+	return w.resumeTypeError(resume)
+}
+
+var _ flows.Wait = (*DelayWait)(nil)
+
+type ActivatedDelayWait struct {
+	baseActivatedWait
+
+	until time.Time
+}
+
+func NewActivatedDelayWait(until time.Time, timeoutSeconds int) *ActivatedDelayWait {
+	return &ActivatedDelayWait{
+		baseActivatedWait: baseActivatedWait{type_: TypeDelay, timeoutSeconds: &timeoutSeconds},
+		until:             until,
+	}
+}
+
+// Until returns when this wait is over
+func (w *ActivatedDelayWait) Until() time.Time { return w.until }
+
+var _ flows.ActivatedWait = (*ActivatedDelayWait)(nil)
+
+//------------------------------------------------------------------------------------------
+// JSON Encoding / Decoding
+//------------------------------------------------------------------------------------------
+
+type delayWaitEnvelope struct {
+	Type         string             `json:"type" validate:"required"`
+	Until        string             `json:"until,omitempty"`
+	Seconds      string             `json:"seconds,omitempty"`
+	CategoryUUID flows.CategoryUUID `json:"category_uuid" validate:"required,uuid4"`
+}
+
+func readDelayWait(data json.RawMessage) (flows.Wait, error) {
+	e := &delayWaitEnvelope{}
+	if err := utils.UnmarshalAndValidate(data, e); err != nil {
+		return nil, err
+	}
+	if (e.Until == "") == (e.Seconds == "") {
+		return nil, errors.New("delay wait must have one of until or seconds")
+	}
+
+	return &DelayWait{
+		baseWait: newBaseWait(e.Type, NewTimeout(0, e.CategoryUUID)),
+		Until_:   e.Until,
+		Seconds_: e.Seconds,
+	}, nil
+}
+
+// MarshalJSON marshals this wait into JSON
+func (w *DelayWait) MarshalJSON() ([]byte, error) {
+	return jsonx.Marshal(&delayWaitEnvelope{
+		Type:         w.type_,
+		Until:        w.Until_,
+		Seconds:      w.Seconds_,
+		CategoryUUID: w.timeout.CategoryUUID(),
+	})
+}
+
+type activatedDelayWaitEnvelope struct {
+	baseActivatedWaitEnvelope
+
+	Until time.Time `json:"until" validate:"required"`
+}
+
+func readActivatedDelayWait(data json.RawMessage) (flows.ActivatedWait, error) {
+	e := &activatedDelayWaitEnvelope{}
+	if err := utils.UnmarshalAndValidate(data, e); err != nil {
+		return nil, err
+	}
+
+	w := &ActivatedDelayWait{until: e.Until}
+
+	return w, w.unmarshal(&e.baseActivatedWaitEnvelope)
+}
+
+// MarshalJSON marshals this wait into JSON
+func (w *ActivatedDelayWait) MarshalJSON() ([]byte, error) {
+	e := &activatedDelayWaitEnvelope{Until: w.until}
+
+	if err := w.marshal(&e.baseActivatedWaitEnvelope); err != nil {
+		return nil, err
+	}
+
+	return jsonx.Marshal(e)
+}
@@ -0,0 +1,113 @@
+package waits_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nyaruka/gocommon/dates"
+	"github.com/nyaruka/gocommon/jsonx"
+	"github.com/nyaruka/goflow/envs"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/events"
+	"github.com/nyaruka/goflow/flows/resumes"
+	"github.com/nyaruka/goflow/flows/routers/waits"
+	"github.com/nyaruka/goflow/test"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDelayWait(t *testing.T) {
+	session, _, err := test.CreateTestSession("", envs.RedactionPolicyNone)
+	require.NoError(t, err)
+	run := session.Runs()[0]
+
+	dates.SetNowSource(dates.NewFixedNowSource(time.Date(2018, 10, 18, 14, 20, 30, 0, time.UTC)))
+	defer dates.SetNowSource(dates.DefaultNowSource)
+
+	// one of until or seconds required
+	_, err = waits.ReadWait([]byte(`{"type": "delay", "category_uuid": "c82e161f-fa2d-4e7d-a338-c27f6c349445"}`))
+	assert.EqualError(t, err, "delay wait must have one of until or seconds")
+
+	_, err = waits.ReadWait([]byte(`{"type": "delay", "until": "@(now())", "seconds": "60", "category_uuid": "c82e161f-fa2d-4e7d-a338-c27f6c349445"}`))
+	assert.EqualError(t, err, "delay wait must have one of until or seconds")
+
+	// as is the category to route to when the delay is over
+	_, err = waits.ReadWait([]byte(`{"type": "delay", "seconds": "60"}`))
+	assert.EqualError(t, err, "field 'category_uuid' is required")
+
+	wait, err := waits.ReadWait([]byte(`{"type": "delay", "until": "@(datetime_add(now(), 2, \"D\"))", "category_uuid": "c82e161f-fa2d-4e7d-a338-c27f6c349445"}`))
+	require.NoError(t, err)
+	assert.Equal(t, waits.TypeDelay, wait.Type())
+	assert.Equal(t, flows.CategoryUUID("c82e161f-fa2d-4e7d-a338-c27f6c349445"), wait.Timeout().CategoryUUID())
+	assert.Equal(t, []flows.FlowType{flows.FlowTypeMessaging}, wait.AllowedFlowTypes())
+
+	// test marshalling definition wait
+	marshaled, err := jsonx.Marshal(wait)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"type":"delay","until":"@(datetime_add(now(), 2, \"D\"))","category_uuid":"c82e161f-fa2d-4e7d-a338-c27f6c349445"}`, string(marshaled))
+
+	// try activating the wait
+	log := test.NewEventLog()
+	activated, timedOut := wait.Begin(run, log.Log)
+
+	assert.Equal(t, "delay", activated.Type())
+	assert.False(t, timedOut)
+	assert.Equal(t, 172800, *activated.TimeoutSeconds())
+	assert.Equal(t, time.Date(2018, 10, 20, 14, 20, 30, 0, time.UTC), activated.(*waits.ActivatedDelayWait).Until().UTC())
+	assert.Equal(t, 1, len(log.Events))
+	assert.Equal(t, "delay_wait", log.Events[0].Type())
+	assert.Equal(t, 172800, log.Events[0].(*events.DelayWaitEvent).TimeoutSeconds)
+
+	// test marshalling activated wait
+	marshaled, err = jsonx.Marshal(activated)
+	assert.NoError(t, err)
+
+	activated, err = waits.ReadActivatedWait(marshaled)
+	require.NoError(t, err)
+	assert.Equal(t, 172800, *activated.TimeoutSeconds())
+	assert.Equal(t, time.Date(2018, 10, 20, 14, 20, 30, 0, time.UTC), activated.(*waits.ActivatedDelayWait).Until().UTC())
+
+	// can only be ended by a timeout or expiration
+	assert.NoError(t, wait.End(resumes.NewWaitTimeout(nil, nil)))
+	assert.NoError(t, wait.End(resumes.NewRunExpiration(nil, nil)))
+	assert.EqualError(t, wait.End(resumes.NewMsg(nil, nil, nil)), "can't end a wait of type 'delay' with a resume of type 'msg'")
+
+	// try a wait for a number of seconds
+	wait = waits.NewDelayForWait("@(5 * 60)", "c82e161f-fa2d-4e7d-a338-c27f6c349445")
+
+	log = test.NewEventLog()
+	activated, _ = wait.Begin(run, log.Log)
+
+	assert.Equal(t, 300, *activated.TimeoutSeconds())
+	assert.Equal(t, 1, len(log.Events))
+
+	// if the delay is already over, we don't wait at all and route as though the wait timed out
+	wait = waits.NewDelayUntilWait("2018-10-17T10:00:00Z", "c82e161f-fa2d-4e7d-a338-c27f6c349445")
+
+	log = test.NewEventLog()
+	activated, timedOut = wait.Begin(run, log.Log)
+
+	assert.Nil(t, activated)
+	assert.True(t, timedOut)
+	assert.Equal(t, 0, len(log.Events))
+
+	// and if the delay can't be evaluated, we log an error and don't wait
+	wait = waits.NewDelayUntilWait("@(\"tomorrow-ish\")", "c82e161f-fa2d-4e7d-a338-c27f6c349445")
+
+	log = test.NewEventLog()
+	activated, timedOut = wait.Begin(run, log.Log)
+
+	assert.Nil(t, activated)
+	assert.True(t, timedOut)
+	assert.Equal(t, 1, len(log.Events))
+	assert.Equal(t, "delay evaluated to 'tomorrow-ish' which isn't a valid datetime", log.Events[0].(*events.ErrorEvent).Text)
+
+	wait = waits.NewDelayForWait("soon", "c82e161f-fa2d-4e7d-a338-c27f6c349445")
+
+	log = test.NewEventLog()
+	activated, _ = wait.Begin(run, log.Log)
+
+	assert.Nil(t, activated)
+	assert.Equal(t, "delay evaluated to 'soon' which isn't a valid number of seconds", log.Events[0].(*events.ErrorEvent).Text)
+}
@@ -39,7 +39,7 @@ func (w *DialWait) AllowedFlowTypes() []flows.FlowType {
 }
 
 // Begin beings waiting at this wait
-func (w *DialWait) Begin(run flows.FlowRun, log flows.EventCallback) flows.ActivatedWait {
+func (w *DialWait) Begin(run flows.FlowRun, log flows.EventCallback) (flows.ActivatedWait, bool) {
 	phone, err := run.EvaluateTemplate(w.phone)
 	if err != nil {
 		log(events.NewError(err))
@@ -48,12 +48,12 @@ func (w *DialWait) Begin(run flows.FlowRun, log flows.EventCallback) flows.Activ
 	urn, err := urns.NewTelURNForCountry(phone, string(run.Environment().DefaultCountry()))
 	if err != nil {
 		log(events.NewError(err))
-		return nil
+		return nil, false
 	}
 
 	log(events.NewDialWait(urn))
 
-	return NewActivatedDialWait(urn)
+	return NewActivatedDialWait(urn), false
 }
 
 // End ends this wait or returns an error
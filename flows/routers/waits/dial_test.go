@@ -34,7 +34,7 @@ func TestDialWait(t *testing.T) {
 
 	// try activating the wait
 	log := test.NewEventLog()
-	activated := wait.Begin(run, log.Log)
+	activated, _ := wait.Begin(run, log.Log)
 
 	assert.Equal(t, "dial", activated.Type())
 	assert.Equal(t, 1, len(log.Events))
@@ -58,7 +58,7 @@ func TestDialWait(t *testing.T) {
 	assert.NoError(t, err)
 
 	log = test.NewEventLog()
-	activated = wait.Begin(run, log.Log)
+	activated, _ = wait.Begin(run, log.Log)
 
 	assert.Equal(t, "dial", activated.Type())
 	assert.Equal(t, urns.URN("tel:+593979123456"), activated.(*waits.ActivatedDialWait).URN())
@@ -71,9 +71,10 @@ func TestDialWait(t *testing.T) {
 	assert.NoError(t, err)
 
 	log = test.NewEventLog()
-	activated = wait.Begin(run, log.Log)
+	activated, timedOut := wait.Begin(run, log.Log)
 
 	assert.Nil(t, activated)
+	assert.False(t, timedOut)
 	assert.Equal(t, 1, len(log.Events))
 	assert.Equal(t, "error", log.Events[0].Type())
 }
@@ -49,7 +49,7 @@ func (w *MsgWait) AllowedFlowTypes() []flows.FlowType {
 }
 
 // Begin beings waiting at this wait
-func (w *MsgWait) Begin(run flows.FlowRun, log flows.EventCallback) flows.ActivatedWait {
+func (w *MsgWait) Begin(run flows.FlowRun, log flows.EventCallback) (flows.ActivatedWait, bool) {
 	var timeoutSeconds *int
 
 	if w.timeout != nil {
@@ -61,12 +61,12 @@ func (w *MsgWait) Begin(run flows.FlowRun, log flows.EventCallback) flows.Activa
 	triggerHasMsg := run.Session().Trigger().Type() == triggers.TypeMsg
 
 	if triggerHasMsg && len(run.Session().Runs()) == 1 && len(run.Path()) == 1 {
-		return nil
+		return nil, false
 	}
 
 	log(events.NewMsgWait(timeoutSeconds, w.hint))
 
-	return NewActivatedMsgWait(timeoutSeconds, w.hint)
+	return NewActivatedMsgWait(timeoutSeconds, w.hint), false
 }
 
 // End ends this wait or returns an error
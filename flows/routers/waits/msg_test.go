@@ -78,7 +78,7 @@ func TestMsgWait(t *testing.T) {
 
 	// try activating the wait
 	log := test.NewEventLog()
-	activated := wait.Begin(run, log.Log)
+	activated, _ := wait.Begin(run, log.Log)
 
 	assert.Equal(t, "msg", activated.Type())
 	assert.Equal(t, 1, len(log.Events))
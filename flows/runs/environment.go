@@ -11,19 +11,27 @@ import (
 type runEnvironment struct {
 	envs.Environment
 
-	run *flowRun
+	contact func() *flows.Contact
 }
 
 // creates a run environment based on the given run
 func newRunEnvironment(base envs.Environment, run *flowRun) envs.Environment {
 	return &runEnvironment{
 		flows.NewEnvironment(base, run.Session().Assets().Locations()),
-		run,
+		run.Contact,
+	}
+}
+
+// creates a run environment based on the given run but which takes values from the given contact
+func newContactEnvironment(base envs.Environment, run *flowRun, contact *flows.Contact) envs.Environment {
+	return &runEnvironment{
+		flows.NewEnvironment(base, run.Session().Assets().Locations()),
+		func() *flows.Contact { return contact },
 	}
 }
 
 func (e *runEnvironment) Timezone() *time.Location {
-	contact := e.run.Contact()
+	contact := e.contact()
 
 	// if we have a contact and they have a timezone that overrides the base enviroment's timezone
 	if contact != nil && contact.Timezone() != nil {
@@ -33,7 +41,7 @@ func (e *runEnvironment) Timezone() *time.Location {
 }
 
 func (e *runEnvironment) DefaultLanguage() envs.Language {
-	contact := e.run.Contact()
+	contact := e.contact()
 
 	// if we have a contact and they have a language and it's an allowed language that overrides the base environment's languuage
 	if contact != nil && contact.Language() != envs.NilLanguage && isAllowedLanguage(e, contact.Language()) {
@@ -43,7 +51,7 @@ func (e *runEnvironment) DefaultLanguage() envs.Language {
 }
 
 func (e *runEnvironment) DefaultCountry() envs.Country {
-	contact := e.run.Contact()
+	contact := e.contact()
 
 	// if we have a contact and they have a preferred channel with a country that overrides the base environment's country
 	if contact != nil {
@@ -134,4 +134,16 @@ func TestRunEnvironment(t *testing.T) {
 	run.Contact().SetLanguage(envs.Language("spa"))
 	assert.Equal(t, envs.Language("eng"), runEnv.DefaultLanguage())
 	assert.Equal(t, "en-US", runEnv.DefaultLocale().ToBCP47())
+
+	// can also get an environment for another contact
+	other := flows.NewEmptyContact(sa, "Jim", envs.Language("kin"), tzUK)
+	otherEnv := run.ContactEnvironment(other)
+	assert.Equal(t, envs.Language("kin"), otherEnv.DefaultLanguage())
+	assert.Equal(t, envs.Country("RW"), otherEnv.DefaultCountry())
+	assert.Equal(t, tzUK, otherEnv.Timezone())
+	assert.NotNil(t, otherEnv.LocationResolver())
+
+	// which doesn't affect the environment of the run
+	assert.Equal(t, envs.Language("eng"), runEnv.DefaultLanguage())
+	assert.Equal(t, tzUK, runEnv.Timezone())
 }
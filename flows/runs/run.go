@@ -27,6 +27,7 @@ type flowRun struct {
 	flowRef *assets.FlowReference
 
 	parent  flows.FlowRun
+	params  *types.XObject
 	results flows.Results
 	path    Path
 	events  []flows.Event
@@ -42,7 +43,11 @@ type flowRun struct {
 }
 
 // NewRun initializes a new context and flow run for the passed in flow and contact
-func NewRun(session flows.Session, flow flows.Flow, parent flows.FlowRun) flows.FlowRun {
+func NewRun(session flows.Session, flow flows.Flow, parent flows.FlowRun, params *types.XObject) flows.FlowRun {
+	if params == nil {
+		params = types.XObjectEmpty
+	}
+
 	now := dates.Now()
 	r := &flowRun{
 		uuid:       flows.RunUUID(uuids.New()),
@@ -50,6 +55,7 @@ func NewRun(session flows.Session, flow flows.Flow, parent flows.FlowRun) flows.
 		flow:       flow,
 		flowRef:    flow.Reference(),
 		parent:     parent,
+		params:     params,
 		results:    flows.NewResults(),
 		status:     flows.RunStatusActive,
 		events:     make([]flows.Event, 0),
@@ -75,6 +81,7 @@ func (r *flowRun) FlowReference() *assets.FlowReference { return r.flowRef }
 func (r *flowRun) Contact() *flows.Contact              { return r.session.Contact() }
 func (r *flowRun) Events() []flows.Event                { return r.events }
 
+func (r *flowRun) Params() *types.XObject { return r.params }
 func (r *flowRun) Results() flows.Results { return r.results }
 func (r *flowRun) SaveResult(result *flows.Result) {
 	// truncate value if necessary
@@ -179,6 +186,11 @@ func (r *flowRun) CreateStep(node flows.Node) flows.Step {
 	return step
 }
 
+// SetPath replaces the path of this run
+func (r *flowRun) SetPath(path []flows.Step) {
+	r.path = path
+}
+
 func (r *flowRun) PathLocation() (flows.Step, flows.Node, error) {
 	if r.Path() == nil {
 		return nil, nil, errors.Errorf("run has no location as path is empty")
@@ -222,6 +234,37 @@ func (r *flowRun) ResetExpiration(from *time.Time) {
 
 func (r *flowRun) ExitedOn() *time.Time { return r.exitedOn }
 
+// Rewind discards the steps and events of this run after the given numbers of each, and rebuilds the
+// results from the events which remain
+func (r *flowRun) Rewind(numSteps, numEvents int, status flows.RunStatus) {
+	r.path = r.path[:numSteps]
+	r.events = r.events[:numEvents]
+	r.results = flows.NewResults()
+
+	for _, e := range r.events {
+		if typed, ok := e.(*events.RunResultChangedEvent); ok {
+			var nodeUUID flows.NodeUUID
+			for _, s := range r.path {
+				if s.UUID() == typed.StepUUID() {
+					nodeUUID = s.NodeUUID()
+				}
+			}
+			r.results.Save(flows.NewResult(typed.Name, typed.Value, typed.Category, typed.CategoryLocalized, nodeUUID, typed.Input, typed.Extra, typed.CreatedOn()))
+		}
+	}
+
+	r.status = status
+	r.modifiedOn = dates.Now()
+
+	if status == flows.RunStatusActive || status == flows.RunStatusWaiting {
+		r.exitedOn = nil
+		r.ResetExpiration(nil)
+	}
+
+	r.webhook = lastWebhookSavedAsExtra(r)
+	r.legacyExtra = newLegacyExtra(r)
+}
+
 // RootContext returns the root context for expression evaluation
 //
 //   contact:contact -> the contact
@@ -232,6 +275,7 @@ func (r *flowRun) ExitedOn() *time.Time { return r.exitedOn }
 //   run:run -> the current run
 //   child:related_run -> the last child run
 //   parent:related_run -> the parent of the run
+//   params:any -> the parameters passed to the run by its parent
 //   ticket:ticket -> the last opened ticket for the contact
 //   webhook:any -> the parsed JSON response of the last webhook call
 //   node:node -> the current node
@@ -241,15 +285,18 @@ func (r *flowRun) ExitedOn() *time.Time { return r.exitedOn }
 //
 // @context root
 func (r *flowRun) RootContext(env envs.Environment) map[string]types.XValue {
-	var urns, fields, ticket, node types.XValue
-	if r.Contact() != nil {
-		urns = flows.ContextFunc(env, r.Contact().URNs().MapContext)
-		fields = flows.Context(env, r.Contact().Fields())
+	return r.rootContext(env, r.Contact())
+}
 
-		tickets := r.Contact().Tickets()
+// builds the root context with the given contact in place of the contact of the run
+func (r *flowRun) rootContext(env envs.Environment, contact *flows.Contact) map[string]types.XValue {
+	var urns, fields, ticket, node types.XValue
+	if contact != nil {
+		urns = flows.ContextFunc(env, contact.URNs().MapContext)
+		fields = flows.Context(env, contact.Fields())
 
-		if tickets.Count() > 0 {
-			ticket = flows.Context(env, tickets.All()[tickets.Count()-1])
+		if last := contact.Tickets().Last(); last != nil {
+			ticket = flows.Context(env, last)
 		}
 	}
 
@@ -266,9 +313,10 @@ func (r *flowRun) RootContext(env envs.Environment) map[string]types.XValue {
 		"run":    flows.Context(env, r),
 		"child":  flows.Context(env, child),
 		"parent": flows.Context(env, parent),
+		"params": r.params,
 
 		// shortcuts to things on the current run or contact
-		"contact": flows.Context(env, r.Contact()),
+		"contact": flows.Context(env, contact),
 		"results": flows.Context(env, r.Results()),
 		"urns":    urns,
 		"fields":  fields,
@@ -341,7 +389,11 @@ func (r *flowRun) nodeContext(env envs.Environment) map[string]types.XValue {
 func (r *flowRun) EvaluateTemplateValue(template string) (types.XValue, error) {
 	context := types.NewXObject(r.RootContext(r.Environment()))
 
-	return excellent.EvaluateTemplateValue(r.Environment(), context, template)
+	value, err := excellent.EvaluateTemplateValue(r.Environment(), context, template)
+	if err != nil {
+		r.recordTemplateError()
+	}
+	return value, err
 }
 
 // EvaluateTemplateText evaluates the given template as text in the context of this run
@@ -349,6 +401,9 @@ func (r *flowRun) EvaluateTemplateText(template string, escaping excellent.Escap
 	context := types.NewXObject(r.RootContext(r.Environment()))
 
 	value, err := excellent.EvaluateTemplate(r.Environment(), context, template, escaping)
+	if err != nil {
+		r.recordTemplateError()
+	}
 	if truncate {
 		value = utils.TruncateEllipsis(value, r.Session().Engine().MaxTemplateChars())
 	}
@@ -360,6 +415,29 @@ func (r *flowRun) EvaluateTemplate(template string) (string, error) {
 	return r.EvaluateTemplateText(template, nil, true)
 }
 
+// ContactEnvironment returns an environment for the given contact, which is like the environment of this run but takes
+// values such as language and timezone from the given contact rather than the contact of the run
+func (r *flowRun) ContactEnvironment(contact *flows.Contact) envs.Environment {
+	return newContactEnvironment(r.session.Environment(), r, contact)
+}
+
+// EvaluateTemplateForContact evaluates the given template as text in the context of this run, but with the given
+// contact in place of the contact of the run, e.g. to personalize a message to another contact
+func (r *flowRun) EvaluateTemplateForContact(contact *flows.Contact, template string) (string, error) {
+	env := r.ContactEnvironment(contact)
+	context := types.NewXObject(r.rootContext(env, contact))
+
+	value, err := excellent.EvaluateTemplate(env, context, template, nil)
+	if err != nil {
+		r.recordTemplateError()
+	}
+	return utils.TruncateEllipsis(value, r.Session().Engine().MaxTemplateChars()), err
+}
+
+func (r *flowRun) recordTemplateError() {
+	r.Session().Engine().Metrics().IncCounter(flows.MetricTemplateErrors, map[string]string{"flow_uuid": string(r.FlowReference().UUID)})
+}
+
 // get the ordered list of languages to be used for localization in this run
 func (r *flowRun) getLanguages() []envs.Language {
 	languages := make([]envs.Language, 0, 3)
@@ -441,6 +519,7 @@ type runEnvelope struct {
 	Results    flows.Results         `json:"results,omitempty" validate:"omitempty,dive"`
 	Status     flows.RunStatus       `json:"status" validate:"required"`
 	ParentUUID flows.RunUUID         `json:"parent_uuid,omitempty" validate:"omitempty,uuid4"`
+	Params     json.RawMessage       `json:"params,omitempty"`
 
 	CreatedOn  time.Time  `json:"created_on" validate:"required"`
 	ModifiedOn time.Time  `json:"modified_on" validate:"required"`
@@ -481,6 +560,10 @@ func ReadRun(session flows.Session, data json.RawMessage, missing assets.Missing
 		}
 	}
 
+	if r.params, err = readParams(session.Environment(), r.flow, e.Params); err != nil {
+		return nil, errors.Wrap(err, "unable to read params")
+	}
+
 	if e.Results != nil {
 		r.results = e.Results
 	} else {
@@ -528,6 +611,12 @@ func (r *flowRun) MarshalJSON() ([]byte, error) {
 		e.ParentUUID = r.parent.UUID()
 	}
 
+	if r.params.Count() > 0 {
+		if e.Params, err = jsonx.Marshal(r.params); err != nil {
+			return nil, errors.Wrap(err, "unable to marshal params")
+		}
+	}
+
 	e.Path = make([]*step, len(r.path))
 	for i, s := range r.path {
 		e.Path[i] = s.(*step)
@@ -542,3 +631,30 @@ func (r *flowRun) MarshalJSON() ([]byte, error) {
 
 	return jsonx.Marshal(e)
 }
+
+// reads the params of a run, converting them back to the types declared by its flow if we have it
+func readParams(env envs.Environment, flow flows.Flow, data json.RawMessage) (*types.XObject, error) {
+	if len(data) == 0 {
+		return types.XObjectEmpty, nil
+	}
+
+	params, err := types.ReadXObject(data)
+	if err != nil || flow == nil {
+		return params, err
+	}
+
+	values := make(map[string]types.XValue, params.Count())
+	for _, key := range params.Properties() {
+		values[key], _ = params.Get(key)
+	}
+
+	for _, p := range flow.Params() {
+		if value := values[p.Key]; value != nil {
+			if values[p.Key], err = p.Type.Convert(env, value); err != nil {
+				return nil, errors.Wrapf(err, "invalid value for param '%s'", p.Key)
+			}
+		}
+	}
+
+	return types.NewXObject(values), nil
+}
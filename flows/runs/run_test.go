@@ -7,6 +7,7 @@ import (
 
 	"github.com/nyaruka/gocommon/dates"
 	"github.com/nyaruka/gocommon/jsonx"
+	"github.com/nyaruka/gocommon/urns"
 	"github.com/nyaruka/gocommon/uuids"
 	"github.com/nyaruka/goflow/assets"
 	"github.com/nyaruka/goflow/envs"
@@ -233,6 +234,42 @@ func TestRunContext(t *testing.T) {
 	assert.Equal(t, `gender = "M\" OR"`, evaluated)
 }
 
+func TestEvaluateTemplateForContact(t *testing.T) {
+	session, _, err := test.CreateTestSession("", envs.RedactionPolicyNone)
+	require.NoError(t, err)
+
+	run := session.Runs()[0]
+
+	other, err := flows.NewContact(
+		session.Assets(), flows.ContactUUID(uuids.New()), flows.ContactID(0), "Jim", envs.Language("spa"), flows.ContactStatusActive,
+		nil, dates.Now(), nil, []urns.URN{"tel:+12065559999"}, nil, nil, nil, assets.IgnoreMissing,
+	)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		template string
+		expected string
+	}{
+		{`@contact.name`, `Jim`},
+		{`@contact.language`, `spa`},
+		{`@urns.tel`, `tel:+12065559999`},
+		{`@fields.gender`, ``},
+		{`@run.contact.name`, `Ryan Lewis`},
+		{`@results.favorite_color`, `red`},
+	}
+
+	for _, tc := range testCases {
+		actual, err := run.EvaluateTemplateForContact(other, tc.template)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.expected, actual, "template mismatch for %s", tc.template)
+	}
+
+	// context of the run itself is unchanged
+	evaluated, err := run.EvaluateTemplate(`@contact.name`)
+	assert.NoError(t, err)
+	assert.Equal(t, `Ryan Lewis`, evaluated)
+}
+
 func TestMissingRelatedRunContext(t *testing.T) {
 	// create a run with no parent or child
 	sa, err := test.CreateSessionAssets([]byte(sessionAssets), "")
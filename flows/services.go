@@ -1,12 +1,14 @@
 package flows
 
 import (
+	"context"
 	"net/http"
 	"time"
 
 	"github.com/nyaruka/gocommon/httpx"
 	"github.com/nyaruka/gocommon/urns"
 	"github.com/nyaruka/gocommon/uuids"
+	"github.com/nyaruka/goflow/envs"
 	"github.com/nyaruka/goflow/utils"
 
 	"github.com/shopspring/decimal"
@@ -19,6 +21,25 @@ type Services interface {
 	Classification(Session, *Classifier) (ClassificationService, error)
 	Ticket(Session, *Ticketer) (TicketService, error)
 	Airtime(Session) (AirtimeService, error)
+	Contact(Session) (ContactService, error)
+}
+
+type blockingContextKey struct{}
+
+// WithBlocking returns a copy of the given context in which calls to Blocking will be passed to the given function
+func WithBlocking(ctx context.Context, blocking func(func())) context.Context {
+	return context.WithValue(ctx, blockingContextKey{}, blocking)
+}
+
+// Blocking invokes the given function which is expected to block waiting on a service. If the context belongs to a
+// branch of a fork then it may be invoked concurrently with the blocking functions of other branches, so it can read
+// session state but must not modify it.
+func Blocking(ctx context.Context, fn func()) {
+	if blocking, ok := ctx.Value(blockingContextKey{}).(func(func())); ok {
+		blocking(fn)
+	} else {
+		fn()
+	}
 }
 
 // EmailService provides email functionality to the engine
@@ -54,6 +75,13 @@ type WebhookService interface {
 	Call(session Session, request *http.Request) (*WebhookCall, error)
 }
 
+// ContextWebhookService is a webhook service which can be given a context to control cancellation of calls
+type ContextWebhookService interface {
+	WebhookService
+
+	CallWithContext(ctx context.Context, session Session, request *http.Request) (*WebhookCall, error)
+}
+
 // ExtractedIntent models an intent match
 type ExtractedIntent struct {
 	Name       string          `json:"name"`
@@ -77,10 +105,55 @@ type ClassificationService interface {
 	Classify(session Session, input string, logHTTP HTTPLogCallback) (*Classification, error)
 }
 
+// ContextClassificationService is a classification service which can be given a context to control cancellation of calls
+type ContextClassificationService interface {
+	ClassificationService
+
+	ClassifyWithContext(ctx context.Context, session Session, input string, logHTTP HTTPLogCallback) (*Classification, error)
+}
+
 // TicketService provides ticketing functionality to the engine
 type TicketService interface {
 	// Open tries to open a new ticket
 	Open(session Session, topic *Topic, body string, assignee *User, logHTTP HTTPLogCallback) (*Ticket, error)
+
+	// AddNote tries to add a note to an existing ticket
+	AddNote(session Session, ticket *Ticket, note string, logHTTP HTTPLogCallback) error
+
+	// Assign tries to assign an existing ticket to the given user, or unassign it if user is nil
+	Assign(session Session, ticket *Ticket, assignee *User, logHTTP HTTPLogCallback) error
+
+	// ChangeTopic tries to change the topic of an existing ticket
+	ChangeTopic(session Session, ticket *Ticket, topic *Topic, logHTTP HTTPLogCallback) error
+
+	// Close tries to close an existing ticket
+	Close(session Session, ticket *Ticket, logHTTP HTTPLogCallback) error
+
+	// Reopen tries to reopen an existing closed ticket
+	Reopen(session Session, ticket *Ticket, logHTTP HTTPLogCallback) error
+}
+
+// ContextTicketService is a ticket service which can be given a context to control cancellation of calls
+type ContextTicketService interface {
+	TicketService
+
+	// OpenWithContext tries to open a new ticket, giving up if the context is cancelled
+	OpenWithContext(ctx context.Context, session Session, topic *Topic, body string, assignee *User, logHTTP HTTPLogCallback) (*Ticket, error)
+
+	// AddNoteWithContext tries to add a note to an existing ticket, giving up if the context is cancelled
+	AddNoteWithContext(ctx context.Context, session Session, ticket *Ticket, note string, logHTTP HTTPLogCallback) error
+
+	// AssignWithContext tries to assign an existing ticket, giving up if the context is cancelled
+	AssignWithContext(ctx context.Context, session Session, ticket *Ticket, assignee *User, logHTTP HTTPLogCallback) error
+
+	// ChangeTopicWithContext tries to change the topic of an existing ticket, giving up if the context is cancelled
+	ChangeTopicWithContext(ctx context.Context, session Session, ticket *Ticket, topic *Topic, logHTTP HTTPLogCallback) error
+
+	// CloseWithContext tries to close an existing ticket, giving up if the context is cancelled
+	CloseWithContext(ctx context.Context, session Session, ticket *Ticket, logHTTP HTTPLogCallback) error
+
+	// ReopenWithContext tries to reopen an existing closed ticket, giving up if the context is cancelled
+	ReopenWithContext(ctx context.Context, session Session, ticket *Ticket, logHTTP HTTPLogCallback) error
 }
 
 // AirtimeTransferStatus is a status of a airtime transfer
@@ -108,6 +181,40 @@ type AirtimeService interface {
 	Transfer(session Session, sender urns.URN, recipient urns.URN, amounts map[string]decimal.Decimal, logHTTP HTTPLogCallback) (*AirtimeTransfer, error)
 }
 
+// ContextAirtimeService is an airtime service which can be given a context to control cancellation of calls
+type ContextAirtimeService interface {
+	AirtimeService
+
+	// TransferWithContext transfers airtime to the given URN, giving up if the context is cancelled
+	TransferWithContext(ctx context.Context, session Session, sender urns.URN, recipient urns.URN, amounts map[string]decimal.Decimal, logHTTP HTTPLogCallback) (*AirtimeTransfer, error)
+}
+
+// ContactService provides lookup and creation of contacts other than the session contact
+type ContactService interface {
+	// LookupByURN tries to find the contact with the given URN, returning nil if there isn't one
+	LookupByURN(session Session, urn urns.URN) (*Contact, error)
+
+	// LookupByField tries to find a contact with the given value for the given field, returning nil if there isn't one
+	LookupByField(session Session, field *Field, value string) (*Contact, error)
+
+	// Create tries to create a new contact with the given name, language and URNs
+	Create(session Session, name string, language envs.Language, urns []urns.URN) (*Contact, error)
+}
+
+// ContextContactService is a contact service which can be given a context to control cancellation of calls
+type ContextContactService interface {
+	ContactService
+
+	// LookupByURNWithContext tries to find the contact with the given URN, giving up if the context is cancelled
+	LookupByURNWithContext(ctx context.Context, session Session, urn urns.URN) (*Contact, error)
+
+	// LookupByFieldWithContext tries to find a contact by field value, giving up if the context is cancelled
+	LookupByFieldWithContext(ctx context.Context, session Session, field *Field, value string) (*Contact, error)
+
+	// CreateWithContext tries to create a new contact, giving up if the context is cancelled
+	CreateWithContext(ctx context.Context, session Session, name string, language envs.Language, urns []urns.URN) (*Contact, error)
+}
+
 // HTTPTrace describes an HTTP request/response
 type HTTPTrace struct {
 	URL        string     `json:"url" validate:"required"`
@@ -7,11 +7,28 @@ import (
 	"github.com/nyaruka/goflow/envs"
 	"github.com/nyaruka/goflow/excellent/types"
 	"github.com/nyaruka/goflow/utils"
+
+	validator "gopkg.in/go-playground/validator.v9"
 )
 
+func init() {
+	utils.RegisterValidatorAlias("ticket_status", "eq=open|eq=closed", func(validator.FieldError) string {
+		return "is not a valid ticket status"
+	})
+}
+
 // TicketUUID is the UUID of a ticket
 type TicketUUID uuids.UUID
 
+// TicketStatus is the status of a ticket
+type TicketStatus string
+
+// possible values for ticket statuses
+const (
+	TicketStatusOpen   TicketStatus = "open"
+	TicketStatusClosed TicketStatus = "closed"
+)
+
 // Ticket is a ticket in a ticketing system
 type Ticket struct {
 	uuid       TicketUUID
@@ -20,6 +37,7 @@ type Ticket struct {
 	body       string
 	externalID string
 	assignee   *User
+	status     TicketStatus
 }
 
 // NewTicket creates a new ticket
@@ -31,6 +49,7 @@ func NewTicket(uuid TicketUUID, ticketer *Ticketer, topic *Topic, body, external
 		body:       body,
 		externalID: externalID,
 		assignee:   assignee,
+		status:     TicketStatusOpen,
 	}
 }
 
@@ -39,19 +58,24 @@ func OpenTicket(ticketer *Ticketer, topic *Topic, body string, assignee *User) *
 	return NewTicket(TicketUUID(uuids.New()), ticketer, topic, body, "", assignee)
 }
 
-func (t *Ticket) UUID() TicketUUID        { return t.uuid }
-func (t *Ticket) Ticketer() *Ticketer     { return t.ticketer }
-func (t *Ticket) Topic() *Topic           { return t.topic }
-func (t *Ticket) Body() string            { return t.body }
-func (t *Ticket) ExternalID() string      { return t.externalID }
-func (t *Ticket) SetExternalID(id string) { t.externalID = id }
-func (t *Ticket) Assignee() *User         { return t.assignee }
+func (t *Ticket) UUID() TicketUUID              { return t.uuid }
+func (t *Ticket) Ticketer() *Ticketer           { return t.ticketer }
+func (t *Ticket) Topic() *Topic                 { return t.topic }
+func (t *Ticket) SetTopic(topic *Topic)         { t.topic = topic }
+func (t *Ticket) Body() string                  { return t.body }
+func (t *Ticket) ExternalID() string            { return t.externalID }
+func (t *Ticket) SetExternalID(id string)       { t.externalID = id }
+func (t *Ticket) Assignee() *User               { return t.assignee }
+func (t *Ticket) SetAssignee(assignee *User)    { t.assignee = assignee }
+func (t *Ticket) Status() TicketStatus          { return t.status }
+func (t *Ticket) SetStatus(status TicketStatus) { t.status = status }
 
 // Context returns the properties available in expressions
 //
 //   uuid:text -> the UUID of the ticket
 //   subject:text -> the subject of the ticket
 //   body:text -> the body of the ticket
+//   status:text -> the status of the ticket, i.e. open or closed
 //
 // @context ticket
 func (t *Ticket) Context(env envs.Environment) map[string]types.XValue {
@@ -60,6 +84,7 @@ func (t *Ticket) Context(env envs.Environment) map[string]types.XValue {
 		"topic":    Context(env, t.topic),
 		"body":     types.NewXText(t.body),
 		"assignee": Context(env, t.assignee),
+		"status":   types.NewXText(string(t.status)),
 	}
 }
 
@@ -74,6 +99,7 @@ type ticketEnvelope struct {
 	Body       string                    `json:"body"`
 	ExternalID string                    `json:"external_id,omitempty"`
 	Assignee   *assets.UserReference     `json:"assignee,omitempty"     validate:"omitempty,dive"`
+	Status     TicketStatus              `json:"status,omitempty"       validate:"omitempty,ticket_status"`
 }
 
 // ReadTicket decodes a contact from the passed in JSON. If the ticketer or assigned user can't
@@ -106,6 +132,12 @@ func ReadTicket(sa SessionAssets, data []byte, missing assets.MissingCallback) (
 		}
 	}
 
+	// tickets without a status are open
+	status := e.Status
+	if status == "" {
+		status = TicketStatusOpen
+	}
+
 	return &Ticket{
 		uuid:       e.UUID,
 		ticketer:   ticketer,
@@ -113,6 +145,7 @@ func ReadTicket(sa SessionAssets, data []byte, missing assets.MissingCallback) (
 		body:       e.Body,
 		externalID: e.ExternalID,
 		assignee:   assignee,
+		status:     status,
 	}, nil
 }
 
@@ -133,6 +166,12 @@ func (t *Ticket) MarshalJSON() ([]byte, error) {
 		assigneeRef = t.assignee.Reference()
 	}
 
+	// open is the default status so only closed tickets record it
+	var status TicketStatus
+	if t.status != TicketStatusOpen {
+		status = t.status
+	}
+
 	return jsonx.Marshal(&ticketEnvelope{
 		UUID:       t.uuid,
 		Ticketer:   ticketerRef,
@@ -140,6 +179,7 @@ func (t *Ticket) MarshalJSON() ([]byte, error) {
 		Body:       t.body,
 		ExternalID: t.externalID,
 		Assignee:   assigneeRef,
+		Status:     status,
 	})
 }
 
@@ -156,7 +196,10 @@ func NewTicketList(tickets []*Ticket) *TicketList {
 // returns a clone of this ticket list
 func (l *TicketList) clone() *TicketList {
 	tickets := make([]*Ticket, len(l.tickets))
-	copy(tickets, l.tickets)
+	for i, ticket := range l.tickets {
+		cloned := *ticket
+		tickets[i] = &cloned
+	}
 	return &TicketList{tickets: tickets}
 }
 
@@ -170,6 +213,35 @@ func (l *TicketList) All() []*Ticket {
 	return l.tickets
 }
 
+// Open returns the open tickets in this ticket list
+func (l *TicketList) Open() []*Ticket {
+	open := make([]*Ticket, 0, len(l.tickets))
+	for _, ticket := range l.tickets {
+		if ticket.status == TicketStatusOpen {
+			open = append(open, ticket)
+		}
+	}
+	return open
+}
+
+// FindByUUID finds the ticket with the given UUID in this ticket list
+func (l *TicketList) FindByUUID(uuid TicketUUID) *Ticket {
+	for _, ticket := range l.tickets {
+		if ticket.uuid == uuid {
+			return ticket
+		}
+	}
+	return nil
+}
+
+// Last returns the most recently added ticket in this ticket list, which may be closed
+func (l *TicketList) Last() *Ticket {
+	if len(l.tickets) == 0 {
+		return nil
+	}
+	return l.tickets[len(l.tickets)-1]
+}
+
 // Count returns the number of tickets
 func (l *TicketList) Count() int {
 	return len(l.tickets)
@@ -3,10 +3,12 @@ package flows_test
 import (
 	"testing"
 
+	"github.com/nyaruka/gocommon/jsonx"
 	"github.com/nyaruka/gocommon/uuids"
 	"github.com/nyaruka/goflow/assets"
 	"github.com/nyaruka/goflow/assets/static"
 	"github.com/nyaruka/goflow/envs"
+	"github.com/nyaruka/goflow/excellent/types"
 	"github.com/nyaruka/goflow/flows"
 	"github.com/nyaruka/goflow/flows/engine"
 
@@ -122,6 +124,10 @@ func TestTickets(t *testing.T) {
 	assert.Equal(t, 0, len(missingRefs))
 	assert.Equal(t, "Support Tickets", ticket2.Ticketer().Name())
 	assert.Equal(t, "Bob", ticket2.Assignee().Name())
+	assert.Equal(t, flows.TicketStatusOpen, ticket2.Status()) // tickets without a status are open
+
+	_, err = flows.ReadTicket(sa, []byte(`{"uuid": "5a4af021-d2c2-47fc-9abc-abbb8635d8c0", "ticketer": {"uuid": "d605bb96-258d-4097-ad0a-080937db2212", "name": "Support Tickets"}, "status": "resolved"}`), missing)
+	assert.EqualError(t, err, "field 'status' is not a valid ticket status")
 
 	tickets := flows.NewTicketList([]*flows.Ticket{ticket1, ticket2})
 	assert.Equal(t, 2, tickets.Count())
@@ -140,4 +146,28 @@ func TestTickets(t *testing.T) {
 
 	tickets.Add(ticket3)
 	assert.Equal(t, 3, tickets.Count())
+	assert.Equal(t, ticket3, tickets.Last())
+
+	// closed tickets are still included in expressions but can be told apart by their status
+	ticket1.SetStatus(flows.TicketStatusClosed)
+	assert.Equal(t, 3, tickets.ToXValue(env).(*types.XArray).Count())
+	status, _ := flows.Context(env, ticket1).(*types.XObject).Get("status")
+	assert.Equal(t, types.NewXText("closed"), status)
+	assert.Equal(t, []*flows.Ticket{ticket2, ticket3}, tickets.Open())
+
+	ticket3.SetTopic(nil)
+	ticket3.SetAssignee(nil)
+	assert.Nil(t, ticket3.Topic())
+	assert.Nil(t, ticket3.Assignee())
+
+	marshaled, err := jsonx.Marshal(ticket1)
+	require.NoError(t, err)
+	assert.Contains(t, string(marshaled), `"status":"closed"`)
+
+	// but open is the default status so isn't recorded
+	marshaled, err = jsonx.Marshal(ticket2)
+	require.NoError(t, err)
+	assert.NotContains(t, string(marshaled), `"status"`)
+
+	assert.Nil(t, flows.NewTicketList(nil).Last())
 }
@@ -0,0 +1,43 @@
+package flows
+
+import (
+	"context"
+	"time"
+)
+
+// names of the spans created by the engine
+const (
+	SpanSprint        = "sprint"
+	SpanVisitNode     = "visit_node"
+	SpanPickNodeExit  = "pick_node_exit"
+	SpanExecuteAction = "execute_action"
+	SpanHTTP          = "http"
+)
+
+// keys of the attributes added to spans by the engine
+const (
+	SpanAttrSessionUUID = "session_uuid"
+	SpanAttrFlowUUID    = "flow_uuid"
+	SpanAttrNodeUUID    = "node_uuid"
+	SpanAttrActionType  = "action_type"
+	SpanAttrActionUUID  = "action_uuid"
+	SpanAttrURL         = "url"
+	SpanAttrStatus      = "status"
+	SpanAttrStatusCode  = "status_code"
+)
+
+// Tracer records spans of time spent in different parts of flow execution. Spans started in a context which
+// contains another span should be recorded as children of that span.
+type Tracer interface {
+	// StartSpan starts a new span which will be ended by the caller, and returns a context containing it
+	StartSpan(ctx context.Context, name string, attrs map[string]string) (context.Context, Span)
+
+	// RecordSpan records a span for something which has already happened, e.g. an HTTP call
+	RecordSpan(ctx context.Context, name string, start, end time.Time, attrs map[string]string)
+}
+
+// Span is an in-progress span started by a tracer
+type Span interface {
+	SetAttribute(key, value string)
+	End()
+}
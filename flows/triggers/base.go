@@ -82,7 +82,7 @@ func (t *baseTrigger) Initialize(session flows.Session, logEvent flows.EventCall
 	}
 
 	session.SetType(flow.Type())
-	session.PushFlow(flow, nil, false)
+	session.PushFlow(flow, nil, false, nil)
 
 	if t.environment != nil {
 		session.SetEnvironment(t.environment)
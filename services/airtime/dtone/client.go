@@ -2,6 +2,7 @@ package dtone
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"net/http"
@@ -75,9 +76,14 @@ type Operator struct {
 
 // LookupMobileNumber see https://dvs-api-doc.dtone.com/#tag/Mobile-Number
 func (c *Client) LookupMobileNumber(tel string) ([]*Operator, *httpx.Trace, error) {
+	return c.LookupMobileNumberWithContext(context.Background(), tel)
+}
+
+// LookupMobileNumberWithContext looks up the operators for the given number using the given context
+func (c *Client) LookupMobileNumberWithContext(ctx context.Context, tel string) ([]*Operator, *httpx.Trace, error) {
 	var response []*Operator
 
-	trace, err := c.request("GET", fmt.Sprintf("lookup/mobile-number/%s", tel), nil, &response)
+	trace, err := c.request(ctx, "GET", fmt.Sprintf("lookup/mobile-number/%s", tel), nil, &response)
 	if err != nil {
 		return nil, trace, err
 	}
@@ -113,11 +119,16 @@ type Product struct {
 
 // Products see https://dvs-api-doc.dtone.com/#tag/Products
 func (c *Client) Products(_type string, operatorID int) ([]*Product, *httpx.Trace, error) {
+	return c.ProductsWithContext(context.Background(), _type, operatorID)
+}
+
+// ProductsWithContext fetches the products of the given type for the given operator using the given context
+func (c *Client) ProductsWithContext(ctx context.Context, _type string, operatorID int) ([]*Product, *httpx.Trace, error) {
 	var response []*Product
 
 	// TODO endpoint could return more than 100 products in which case we need to page
 
-	trace, err := c.request("GET", fmt.Sprintf("products?type=%s&operator_id=%d&per_page=100", _type, operatorID), nil, &response)
+	trace, err := c.request(ctx, "GET", fmt.Sprintf("products?type=%s&operator_id=%d&per_page=100", _type, operatorID), nil, &response)
 	if err != nil {
 		return nil, trace, err
 	}
@@ -144,6 +155,11 @@ type Transaction struct {
 
 // TransactionSync see https://dvs-api-doc.dtone.com/#tag/Transactions
 func (c *Client) TransactionSync(externalID string, productID int, mobileNumber string) (*Transaction, *httpx.Trace, error) {
+	return c.TransactionSyncWithContext(context.Background(), externalID, productID, mobileNumber)
+}
+
+// TransactionSyncWithContext creates a synchronous transaction using the given context
+func (c *Client) TransactionSyncWithContext(ctx context.Context, externalID string, productID int, mobileNumber string) (*Transaction, *httpx.Trace, error) {
 	var response *Transaction
 
 	type creditPartyIdentifier struct {
@@ -164,7 +180,7 @@ func (c *Client) TransactionSync(externalID string, productID int, mobileNumber
 		},
 	}
 
-	trace, err := c.request("POST", "sync/transactions", payload, &response)
+	trace, err := c.request(ctx, "POST", "sync/transactions", payload, &response)
 	if err != nil {
 		return nil, trace, err
 	}
@@ -172,7 +188,7 @@ func (c *Client) TransactionSync(externalID string, productID int, mobileNumber
 	return response, trace, nil
 }
 
-func (c *Client) request(method, endpoint string, payload interface{}, response interface{}) (*httpx.Trace, error) {
+func (c *Client) request(ctx context.Context, method, endpoint string, payload interface{}, response interface{}) (*httpx.Trace, error) {
 	url := apiURL + endpoint
 	headers := map[string]string{}
 	var body io.Reader
@@ -191,6 +207,7 @@ func (c *Client) request(method, endpoint string, payload interface{}, response
 		return nil, err
 	}
 
+	req = req.WithContext(ctx)
 	req.SetBasicAuth(c.key, c.secret)
 
 	trace, err := httpx.DoTrace(c.httpClient, req, c.httpRetries, nil, -1)
@@ -1,6 +1,7 @@
 package dtone
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/nyaruka/gocommon/httpx"
@@ -27,6 +28,11 @@ func NewService(httpClient *http.Client, httpRetries *httpx.RetryConfig, key, se
 }
 
 func (s *service) Transfer(session flows.Session, sender urns.URN, recipient urns.URN, amounts map[string]decimal.Decimal, logHTTP flows.HTTPLogCallback) (*flows.AirtimeTransfer, error) {
+	return s.TransferWithContext(context.Background(), session, sender, recipient, amounts, logHTTP)
+}
+
+// TransferWithContext transfers airtime using the given context
+func (s *service) TransferWithContext(ctx context.Context, session flows.Session, sender urns.URN, recipient urns.URN, amounts map[string]decimal.Decimal, logHTTP flows.HTTPLogCallback) (*flows.AirtimeTransfer, error) {
 	transfer := &flows.AirtimeTransfer{
 		UUID:          uuids.New(),
 		Sender:        sender,
@@ -35,7 +41,7 @@ func (s *service) Transfer(session flows.Session, sender urns.URN, recipient urn
 		ActualAmount:  decimal.Zero,
 	}
 
-	operators, trace, err := s.client.LookupMobileNumber(recipient.Path())
+	operators, trace, err := s.client.LookupMobileNumberWithContext(ctx, recipient.Path())
 	if trace != nil {
 		logHTTP(flows.NewHTTPLog(trace, flows.HTTPStatusFromCode, s.redactor))
 	}
@@ -56,7 +62,7 @@ func (s *service) Transfer(session flows.Session, sender urns.URN, recipient urn
 	}
 
 	// fetch available products for this operator
-	products, trace, err := s.client.Products("FIXED_VALUE_RECHARGE", operator.ID)
+	products, trace, err := s.client.ProductsWithContext(ctx, "FIXED_VALUE_RECHARGE", operator.ID)
 	if trace != nil {
 		logHTTP(flows.NewHTTPLog(trace, flows.HTTPStatusFromCode, s.redactor))
 	}
@@ -94,7 +100,7 @@ func (s *service) Transfer(session flows.Session, sender urns.URN, recipient urn
 	transfer.DesiredAmount = amounts[transfer.Currency]
 
 	// request synchronous confirmed transaction for this product
-	tx, trace, err := s.client.TransactionSync(string(transfer.UUID), product.ID, recipient.Path())
+	tx, trace, err := s.client.TransactionSyncWithContext(ctx, string(transfer.UUID), product.ID, recipient.Path())
 	if trace != nil {
 		logHTTP(flows.NewHTTPLog(trace, flows.HTTPStatusFromCode, s.redactor))
 	}
@@ -110,3 +116,5 @@ func (s *service) Transfer(session flows.Session, sender urns.URN, recipient urn
 
 	return transfer, nil
 }
+
+var _ flows.ContextAirtimeService = (*service)(nil)
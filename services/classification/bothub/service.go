@@ -1,6 +1,7 @@
 package bothub
 
 import (
+	"context"
 	"net/http"
 	"strings"
 
@@ -26,10 +27,15 @@ func NewService(httpClient *http.Client, httpRetries *httpx.RetryConfig, classif
 }
 
 func (s *service) Classify(session flows.Session, input string, logHTTP flows.HTTPLogCallback) (*flows.Classification, error) {
+	return s.ClassifyWithContext(context.Background(), session, input, logHTTP)
+}
+
+// ClassifyWithContext classifies the given input using the given context
+func (s *service) ClassifyWithContext(ctx context.Context, session flows.Session, input string, logHTTP flows.HTTPLogCallback) (*flows.Classification, error) {
 	locale := session.Runs()[0].Environment().DefaultLocale()
 	localeStr := strings.ReplaceAll(strings.ToLower(locale.ToBCP47()), "-", "_") // en-US -> en_us
 
-	response, trace, err := s.client.Parse(input, localeStr)
+	response, trace, err := s.client.ParseWithContext(ctx, input, localeStr)
 	if trace != nil {
 		logHTTP(flows.NewHTTPLog(trace, flows.HTTPStatusFromCode, s.redactor))
 	}
@@ -57,4 +63,4 @@ func (s *service) Classify(session flows.Session, input string, logHTTP flows.HT
 	return result, nil
 }
 
-var _ flows.ClassificationService = (*service)(nil)
+var _ flows.ContextClassificationService = (*service)(nil)
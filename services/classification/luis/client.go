@@ -1,6 +1,7 @@
 package luis
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -101,6 +102,11 @@ func NewClient(httpClient *http.Client, httpRetries *httpx.RetryConfig, httpAcce
 
 // Predict gets the published endpoint predictions for the given query
 func (c *Client) Predict(q string) (*PredictResponse, *httpx.Trace, error) {
+	return c.PredictWithContext(context.Background(), q)
+}
+
+// PredictWithContext gets the published endpoint predictions for the given query using the given context
+func (c *Client) PredictWithContext(ctx context.Context, q string) (*PredictResponse, *httpx.Trace, error) {
 	endpoint := fmt.Sprintf("%sluis/prediction/v3.0/apps/%s/slots/%s/predict?subscription-key=%s&verbose=true&show-all-intents=true&log=true&query=%s", c.endpoint, c.appID, c.slot, c.key, url.QueryEscape(q))
 
 	request, err := httpx.NewRequest("GET", endpoint, nil, nil)
@@ -108,6 +114,8 @@ func (c *Client) Predict(q string) (*PredictResponse, *httpx.Trace, error) {
 		return nil, nil, err
 	}
 
+	request = request.WithContext(ctx)
+
 	trace, err := httpx.DoTrace(c.httpClient, request, c.httpRetries, c.httpAccess, -1)
 	if err != nil {
 		return nil, trace, err
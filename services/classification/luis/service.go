@@ -1,6 +1,7 @@
 package luis
 
 import (
+	"context"
 	"net/http"
 	"sort"
 
@@ -26,7 +27,12 @@ func NewService(httpClient *http.Client, httpRetries *httpx.RetryConfig, httpAcc
 }
 
 func (s *service) Classify(session flows.Session, input string, logHTTP flows.HTTPLogCallback) (*flows.Classification, error) {
-	response, trace, err := s.client.Predict(input)
+	return s.ClassifyWithContext(context.Background(), session, input, logHTTP)
+}
+
+// ClassifyWithContext classifies the given input using the given context
+func (s *service) ClassifyWithContext(ctx context.Context, session flows.Session, input string, logHTTP flows.HTTPLogCallback) (*flows.Classification, error) {
+	response, trace, err := s.client.PredictWithContext(ctx, input)
 	if trace != nil {
 		logHTTP(flows.NewHTTPLog(trace, flows.HTTPStatusFromCode, s.redactor))
 	}
@@ -62,4 +68,4 @@ func (s *service) Classify(session flows.Session, input string, logHTTP flows.HT
 	return result, nil
 }
 
-var _ flows.ClassificationService = (*service)(nil)
+var _ flows.ContextClassificationService = (*service)(nil)
@@ -1,6 +1,7 @@
 package wit
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
@@ -68,6 +69,11 @@ func NewClient(httpClient *http.Client, httpRetries *httpx.RetryConfig, accessTo
 
 // Message gets the meaning of a message
 func (c *Client) Message(q string) (*MessageResponse, *httpx.Trace, error) {
+	return c.MessageWithContext(context.Background(), q)
+}
+
+// MessageWithContext gets the meaning of a message using the given context
+func (c *Client) MessageWithContext(ctx context.Context, q string) (*MessageResponse, *httpx.Trace, error) {
 	endpoint := fmt.Sprintf("%s/message?v=%s&q=%s", apiBaseURL, version, url.QueryEscape(q))
 
 	request, err := httpx.NewRequest("GET", endpoint, nil, c.headers)
@@ -75,6 +81,8 @@ func (c *Client) Message(q string) (*MessageResponse, *httpx.Trace, error) {
 		return nil, nil, err
 	}
 
+	request = request.WithContext(ctx)
+
 	trace, err := httpx.DoTrace(c.httpClient, request, c.httpRetries, nil, -1)
 	if err != nil {
 		return nil, trace, err
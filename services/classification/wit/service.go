@@ -1,6 +1,7 @@
 package wit
 
 import (
+	"context"
 	"net/http"
 	"strings"
 
@@ -26,7 +27,12 @@ func NewService(httpClient *http.Client, httpRetries *httpx.RetryConfig, classif
 }
 
 func (s *service) Classify(session flows.Session, input string, logHTTP flows.HTTPLogCallback) (*flows.Classification, error) {
-	response, trace, err := s.client.Message(input)
+	return s.ClassifyWithContext(context.Background(), session, input, logHTTP)
+}
+
+// ClassifyWithContext classifies the given input using the given context
+func (s *service) ClassifyWithContext(ctx context.Context, session flows.Session, input string, logHTTP flows.HTTPLogCallback) (*flows.Classification, error) {
+	response, trace, err := s.client.MessageWithContext(ctx, input)
 	if trace != nil {
 		logHTTP(flows.NewHTTPLog(trace, flows.HTTPStatusFromCode, s.redactor))
 	}
@@ -58,4 +64,4 @@ func (s *service) Classify(session flows.Session, input string, logHTTP flows.HT
 	return result, nil
 }
 
-var _ flows.ClassificationService = (*service)(nil)
+var _ flows.ContextClassificationService = (*service)(nil)
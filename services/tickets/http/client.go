@@ -0,0 +1,133 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/nyaruka/gocommon/httpx"
+	"github.com/nyaruka/gocommon/jsonx"
+	"github.com/nyaruka/goflow/utils"
+
+	"github.com/pkg/errors"
+)
+
+// Comment is a comment on a ticket, which is private if it's a note for agents
+type Comment struct {
+	Body   string `json:"body"`
+	Public bool   `json:"public"`
+}
+
+// Requester is the person who requested a ticket
+type Requester struct {
+	Name       string `json:"name,omitempty"`
+	ExternalID string `json:"external_id,omitempty"`
+}
+
+// Ticket is a ticket as sent to and returned from the ticket API
+type Ticket struct {
+	ID            json.Number `json:"id,omitempty"`
+	ExternalID    string      `json:"external_id,omitempty"`
+	Subject       string      `json:"subject,omitempty"`
+	Comment       *Comment    `json:"comment,omitempty"`
+	Requester     *Requester  `json:"requester,omitempty"`
+	AssigneeEmail string      `json:"assignee_email,omitempty"`
+	Status        string      `json:"status,omitempty"`
+}
+
+type ticketRequest struct {
+	Ticket interface{} `json:"ticket"`
+}
+
+type ticketResponse struct {
+	Ticket *Ticket `json:"ticket" validate:"required"`
+}
+
+// Client is a client for a ticket API which accepts tickets in the same JSON format as the Zendesk tickets API, i.e.
+// wrapped in a ticket object and with updates made with PUT requests to the ticket's URL
+type Client struct {
+	httpClient  *http.Client
+	httpRetries *httpx.RetryConfig
+	baseURL     string
+	headers     map[string]string
+}
+
+// NewClient creates a new client for the ticket API at the given base URL
+func NewClient(httpClient *http.Client, httpRetries *httpx.RetryConfig, baseURL, authToken string) *Client {
+	return &Client{
+		httpClient:  httpClient,
+		httpRetries: httpRetries,
+		baseURL:     strings.TrimSuffix(baseURL, "/"),
+		headers: map[string]string{
+			"Authorization": fmt.Sprintf("Bearer %s", authToken),
+		},
+	}
+}
+
+// CreateTicket creates a new ticket, returning the created ticket which will have an ID
+func (c *Client) CreateTicket(ticket *Ticket) (*Ticket, *httpx.Trace, error) {
+	return c.CreateTicketWithContext(context.Background(), ticket)
+}
+
+// CreateTicketWithContext creates a new ticket using the given context
+func (c *Client) CreateTicketWithContext(ctx context.Context, ticket *Ticket) (*Ticket, *httpx.Trace, error) {
+	response := &ticketResponse{}
+
+	trace, err := c.request(ctx, "POST", "tickets.json", &ticketRequest{Ticket: ticket}, response)
+	if err != nil {
+		return nil, trace, err
+	}
+	if response.Ticket.ID == "" {
+		return nil, trace, errors.New("ticket API response has no ticket ID")
+	}
+
+	return response.Ticket, trace, nil
+}
+
+// UpdateTicket updates the existing ticket with the given ID, using the given changes which are sent as is so that
+// fields can be set to null
+func (c *Client) UpdateTicket(id string, changes map[string]interface{}) (*httpx.Trace, error) {
+	return c.UpdateTicketWithContext(context.Background(), id, changes)
+}
+
+// UpdateTicketWithContext updates the existing ticket with the given ID using the given context
+func (c *Client) UpdateTicketWithContext(ctx context.Context, id string, changes map[string]interface{}) (*httpx.Trace, error) {
+	return c.request(ctx, "PUT", fmt.Sprintf("tickets/%s.json", id), &ticketRequest{Ticket: changes}, nil)
+}
+
+func (c *Client) request(ctx context.Context, method, endpoint string, payload interface{}, response interface{}) (*httpx.Trace, error) {
+	url := fmt.Sprintf("%s/%s", c.baseURL, endpoint)
+	headers := map[string]string{"Content-Type": "application/json"}
+	for k, v := range c.headers {
+		headers[k] = v
+	}
+
+	data, err := jsonx.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := httpx.NewRequest(method, url, bytes.NewReader(data), headers)
+	if err != nil {
+		return nil, err
+	}
+
+	req = req.WithContext(ctx)
+
+	trace, err := httpx.DoTrace(c.httpClient, req, c.httpRetries, nil, -1)
+	if err != nil {
+		return trace, err
+	}
+
+	if trace.Response.StatusCode >= 400 {
+		return trace, errors.Errorf("ticket API request failed with status %d", trace.Response.StatusCode)
+	}
+
+	if response != nil {
+		return trace, utils.UnmarshalAndValidate(trace.ResponseBody, response)
+	}
+	return trace, nil
+}
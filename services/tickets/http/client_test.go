@@ -0,0 +1,79 @@
+package http_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/nyaruka/gocommon/httpx"
+	tickets "github.com/nyaruka/goflow/services/tickets/http"
+	"github.com/nyaruka/goflow/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateTicket(t *testing.T) {
+	defer httpx.SetRequestor(httpx.DefaultRequestor)
+
+	httpx.SetRequestor(httpx.NewMockRequestor(map[string][]httpx.MockResponse{
+		"https://help.nyaruka.com/api/v2/tickets.json": {
+			httpx.NewMockResponse(422, nil, `{"error": "RecordInvalid"}`),
+			httpx.NewMockResponse(201, nil, `xx`),            // non-JSON response
+			httpx.NewMockResponse(201, nil, `{}`),            // no ticket in response
+			httpx.NewMockResponse(201, nil, `{"ticket": {}}`), // no ticket ID in response
+			httpx.NewMockResponse(201, nil, `{"ticket": {"id": 35436, "subject": "Weather", "status": "new"}}`),
+		},
+	}))
+
+	client := tickets.NewClient(http.DefaultClient, nil, "https://help.nyaruka.com/api/v2/", "123456789")
+	ticket := &tickets.Ticket{
+		ExternalID: "59d74b86-3e2f-4a93-aece-b05d2fdcde0c",
+		Subject:    "Weather",
+		Comment:    &tickets.Comment{Body: "Where are my cookies?", Public: true},
+		Requester:  &tickets.Requester{Name: "Ryan Lewis", ExternalID: "5d76d86b-3bb9-4d5a-b822-c9d86f5d8e4f"},
+	}
+
+	created, trace, err := client.CreateTicket(ticket)
+	assert.EqualError(t, err, "ticket API request failed with status 422")
+	test.AssertSnapshot(t, "create_request", string(trace.RequestTrace))
+	assert.Nil(t, created)
+
+	created, trace, err = client.CreateTicket(ticket)
+	assert.EqualError(t, err, "invalid character 'x' looking for beginning of value")
+	assert.Equal(t, "xx", string(trace.ResponseBody))
+	assert.Nil(t, created)
+
+	created, _, err = client.CreateTicket(ticket)
+	assert.EqualError(t, err, "field 'ticket' is required")
+	assert.Nil(t, created)
+
+	created, _, err = client.CreateTicket(ticket)
+	assert.EqualError(t, err, "ticket API response has no ticket ID")
+	assert.Nil(t, created)
+
+	created, trace, err = client.CreateTicket(ticket)
+	assert.NoError(t, err)
+	assert.Equal(t, "HTTP/1.0 201 Created\r\nContent-Length: 64\r\n\r\n", string(trace.ResponseTrace))
+	assert.Equal(t, "35436", created.ID.String())
+	assert.Equal(t, "Weather", created.Subject)
+	assert.Equal(t, "new", created.Status)
+}
+
+func TestUpdateTicket(t *testing.T) {
+	defer httpx.SetRequestor(httpx.DefaultRequestor)
+
+	httpx.SetRequestor(httpx.NewMockRequestor(map[string][]httpx.MockResponse{
+		"https://help.nyaruka.com/api/v2/tickets/35436.json": {
+			httpx.NewMockResponse(404, nil, `{"error": "RecordNotFound"}`),
+			httpx.NewMockResponse(200, nil, `{"ticket": {"id": 35436, "assignee_id": null}}`),
+		},
+	}))
+
+	client := tickets.NewClient(http.DefaultClient, nil, "https://help.nyaruka.com/api/v2", "123456789")
+
+	_, err := client.UpdateTicket("35436", map[string]interface{}{"status": "solved"})
+	assert.EqualError(t, err, "ticket API request failed with status 404")
+
+	trace, err := client.UpdateTicket("35436", map[string]interface{}{"assignee_id": nil})
+	assert.NoError(t, err)
+	test.AssertSnapshot(t, "update_request", string(trace.RequestTrace))
+}
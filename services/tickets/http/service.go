@@ -0,0 +1,147 @@
+package http
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/nyaruka/gocommon/httpx"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/utils"
+
+	"github.com/pkg/errors"
+)
+
+// ticket statuses used by the ticket API
+const (
+	apiStatusOpen   = "open"
+	apiStatusSolved = "solved"
+)
+
+// a ticket service implementation which opens and updates tickets via a ticket API over HTTP
+type service struct {
+	client   *Client
+	ticketer *flows.Ticketer
+	redactor utils.Redactor
+}
+
+// NewService creates a new ticket service which uses the ticket API at the given base URL
+func NewService(httpClient *http.Client, httpRetries *httpx.RetryConfig, ticketer *flows.Ticketer, baseURL, authToken string) flows.TicketService {
+	return &service{
+		client:   NewClient(httpClient, httpRetries, baseURL, authToken),
+		ticketer: ticketer,
+		redactor: utils.NewRedactor(flows.RedactionMask, authToken),
+	}
+}
+
+// Open opens a ticket which for this service means creating a ticket via the API
+func (s *service) Open(session flows.Session, topic *flows.Topic, body string, assignee *flows.User, logHTTP flows.HTTPLogCallback) (*flows.Ticket, error) {
+	return s.OpenWithContext(context.Background(), session, topic, body, assignee, logHTTP)
+}
+
+// OpenWithContext opens a ticket using the given context
+func (s *service) OpenWithContext(ctx context.Context, session flows.Session, topic *flows.Topic, body string, assignee *flows.User, logHTTP flows.HTTPLogCallback) (*flows.Ticket, error) {
+	ticket := flows.OpenTicket(s.ticketer, topic, body, assignee)
+
+	request := &Ticket{
+		ExternalID: string(ticket.UUID()),
+		Comment:    &Comment{Body: body, Public: true},
+		Requester: &Requester{
+			Name:       session.Contact().Name(),
+			ExternalID: string(session.Contact().UUID()),
+		},
+	}
+	if topic != nil {
+		request.Subject = topic.Name()
+	}
+	if assignee != nil {
+		request.AssigneeEmail = assignee.Email()
+	}
+
+	created, trace, err := s.client.CreateTicketWithContext(ctx, request)
+	if trace != nil {
+		logHTTP(flows.NewHTTPLog(trace, flows.HTTPStatusFromCode, s.redactor))
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating ticket")
+	}
+
+	ticket.SetExternalID(created.ID.String())
+	return ticket, nil
+}
+
+// AddNote adds a note to a ticket which for this service means adding a private comment
+func (s *service) AddNote(session flows.Session, ticket *flows.Ticket, note string, logHTTP flows.HTTPLogCallback) error {
+	return s.AddNoteWithContext(context.Background(), session, ticket, note, logHTTP)
+}
+
+// AddNoteWithContext adds a note to a ticket using the given context
+func (s *service) AddNoteWithContext(ctx context.Context, session flows.Session, ticket *flows.Ticket, note string, logHTTP flows.HTTPLogCallback) error {
+	return s.update(ctx, ticket, map[string]interface{}{"comment": &Comment{Body: note, Public: false}}, logHTTP)
+}
+
+// Assign assigns a ticket to the given user, or unassigns it if user is nil
+func (s *service) Assign(session flows.Session, ticket *flows.Ticket, assignee *flows.User, logHTTP flows.HTTPLogCallback) error {
+	return s.AssignWithContext(context.Background(), session, ticket, assignee, logHTTP)
+}
+
+// AssignWithContext assigns a ticket using the given context
+func (s *service) AssignWithContext(ctx context.Context, session flows.Session, ticket *flows.Ticket, assignee *flows.User, logHTTP flows.HTTPLogCallback) error {
+	changes := map[string]interface{}{"assignee_id": nil}
+	if assignee != nil {
+		changes = map[string]interface{}{"assignee_email": assignee.Email()}
+	}
+
+	return s.update(ctx, ticket, changes, logHTTP)
+}
+
+// ChangeTopic changes the topic of a ticket which for this service means changing its subject
+func (s *service) ChangeTopic(session flows.Session, ticket *flows.Ticket, topic *flows.Topic, logHTTP flows.HTTPLogCallback) error {
+	return s.ChangeTopicWithContext(context.Background(), session, ticket, topic, logHTTP)
+}
+
+// ChangeTopicWithContext changes the topic of a ticket using the given context, clearing its subject if topic is nil
+func (s *service) ChangeTopicWithContext(ctx context.Context, session flows.Session, ticket *flows.Ticket, topic *flows.Topic, logHTTP flows.HTTPLogCallback) error {
+	subject := ""
+	if topic != nil {
+		subject = topic.Name()
+	}
+
+	return s.update(ctx, ticket, map[string]interface{}{"subject": subject}, logHTTP)
+}
+
+// Close closes a ticket which for this service means marking it as solved
+func (s *service) Close(session flows.Session, ticket *flows.Ticket, logHTTP flows.HTTPLogCallback) error {
+	return s.CloseWithContext(context.Background(), session, ticket, logHTTP)
+}
+
+// CloseWithContext closes a ticket using the given context
+func (s *service) CloseWithContext(ctx context.Context, session flows.Session, ticket *flows.Ticket, logHTTP flows.HTTPLogCallback) error {
+	return s.update(ctx, ticket, map[string]interface{}{"status": apiStatusSolved}, logHTTP)
+}
+
+// Reopen reopens a closed ticket
+func (s *service) Reopen(session flows.Session, ticket *flows.Ticket, logHTTP flows.HTTPLogCallback) error {
+	return s.ReopenWithContext(context.Background(), session, ticket, logHTTP)
+}
+
+// ReopenWithContext reopens a closed ticket using the given context
+func (s *service) ReopenWithContext(ctx context.Context, session flows.Session, ticket *flows.Ticket, logHTTP flows.HTTPLogCallback) error {
+	return s.update(ctx, ticket, map[string]interface{}{"status": apiStatusOpen}, logHTTP)
+}
+
+func (s *service) update(ctx context.Context, ticket *flows.Ticket, changes map[string]interface{}, logHTTP flows.HTTPLogCallback) error {
+	if ticket.ExternalID() == "" {
+		return errors.Errorf("ticket %s has no external ID", ticket.UUID())
+	}
+
+	trace, err := s.client.UpdateTicketWithContext(ctx, ticket.ExternalID(), changes)
+	if trace != nil {
+		logHTTP(flows.NewHTTPLog(trace, flows.HTTPStatusFromCode, s.redactor))
+	}
+	if err != nil {
+		return errors.Wrap(err, "error updating ticket")
+	}
+	return nil
+}
+
+var _ flows.ContextTicketService = (*service)(nil)
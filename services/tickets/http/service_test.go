@@ -0,0 +1,108 @@
+package http_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/nyaruka/gocommon/dates"
+	"github.com/nyaruka/gocommon/httpx"
+	"github.com/nyaruka/gocommon/uuids"
+	"github.com/nyaruka/goflow/envs"
+	"github.com/nyaruka/goflow/flows"
+	tickets "github.com/nyaruka/goflow/services/tickets/http"
+	"github.com/nyaruka/goflow/test"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService(t *testing.T) {
+	session, _, err := test.CreateTestSession("", envs.RedactionPolicyNone)
+	require.NoError(t, err)
+
+	defer uuids.SetGenerator(uuids.DefaultGenerator)
+	defer dates.SetNowSource(dates.DefaultNowSource)
+	defer httpx.SetRequestor(httpx.DefaultRequestor)
+
+	uuids.SetGenerator(uuids.NewSeededGenerator(12345))
+	dates.SetNowSource(dates.NewSequentialNowSource(time.Date(2019, 10, 7, 15, 21, 30, 123456789, time.UTC)))
+	httpx.SetRequestor(httpx.NewMockRequestor(map[string][]httpx.MockResponse{
+		"https://help.nyaruka.com/api/v2/tickets.json": {
+			httpx.NewMockResponse(422, nil, `{"error": "RecordInvalid"}`),
+			httpx.NewMockResponse(201, nil, `{"ticket": {"id": 35436, "subject": "Weather", "status": "new"}}`),
+		},
+		"https://help.nyaruka.com/api/v2/tickets/35436.json": {
+			httpx.NewMockResponse(200, nil, `{"ticket": {"id": 35436}}`),
+			httpx.NewMockResponse(200, nil, `{"ticket": {"id": 35436}}`),
+			httpx.NewMockResponse(200, nil, `{"ticket": {"id": 35436}}`),
+			httpx.NewMockResponse(200, nil, `{"ticket": {"id": 35436}}`),
+			httpx.NewMockResponse(200, nil, `{"ticket": {"id": 35436}}`),
+			httpx.NewMockResponse(200, nil, `{"ticket": {"id": 35436}}`),
+			httpx.NewMockResponse(404, nil, `{"error": "RecordNotFound"}`),
+		},
+	}))
+
+	ticketer := session.Assets().Ticketers().Get("19dc6346-9623-4fe4-be80-538d493ecdf5")
+	weather := session.Assets().Topics().Get("472a7a73-96cb-4736-b567-056d987cc5b4")
+	bob := session.Assets().Users().Get("bob@nyaruka.com")
+
+	svc := tickets.NewService(http.DefaultClient, nil, ticketer, "https://help.nyaruka.com/api/v2", "123456789")
+
+	// check a failed call to open a ticket
+	httpLogger := &flows.HTTPLogger{}
+
+	ticket, err := svc.Open(session, weather, "Where are my cookies?", bob, httpLogger.Log)
+	assert.EqualError(t, err, "error creating ticket: ticket API request failed with status 422")
+	assert.Nil(t, ticket)
+	assert.Equal(t, 1, len(httpLogger.Logs))
+	assert.Equal(t, flows.CallStatusResponseError, httpLogger.Logs[0].Status)
+
+	// and a successful one
+	httpLogger = &flows.HTTPLogger{}
+
+	ticket, err = svc.Open(session, weather, "Where are my cookies?", bob, httpLogger.Log)
+	assert.NoError(t, err)
+	assert.Equal(t, ticketer, ticket.Ticketer())
+	assert.Equal(t, weather, ticket.Topic())
+	assert.Equal(t, "Where are my cookies?", ticket.Body())
+	assert.Equal(t, "35436", ticket.ExternalID())
+	assert.Equal(t, bob, ticket.Assignee())
+
+	assert.Equal(t, 1, len(httpLogger.Logs))
+	assert.Equal(t, "https://help.nyaruka.com/api/v2/tickets.json", httpLogger.Logs[0].URL)
+	assert.Equal(t, flows.CallStatusSuccess, httpLogger.Logs[0].Status)
+	test.AssertSnapshot(t, "open_request", httpLogger.Logs[0].Request)
+
+	// check updating the ticket, with each update logging its own HTTP call
+	httpLogger = &flows.HTTPLogger{}
+
+	assert.NoError(t, svc.AddNote(session, ticket, "Customer is a VIP", httpLogger.Log))
+	assert.NoError(t, svc.Assign(session, ticket, nil, httpLogger.Log))
+	assert.NoError(t, svc.ChangeTopic(session, ticket, weather, httpLogger.Log))
+	assert.NoError(t, svc.ChangeTopic(session, ticket, nil, httpLogger.Log))
+	assert.NoError(t, svc.Close(session, ticket, httpLogger.Log))
+	assert.NoError(t, svc.Reopen(session, ticket, httpLogger.Log))
+
+	assert.Equal(t, 6, len(httpLogger.Logs))
+	assert.Contains(t, httpLogger.Logs[0].Request, `{"ticket":{"comment":{"body":"Customer is a VIP","public":false}}}`)
+	assert.Contains(t, httpLogger.Logs[1].Request, `{"ticket":{"assignee_id":null}}`)
+	assert.Contains(t, httpLogger.Logs[2].Request, `{"ticket":{"subject":"Weather"}}`)
+	assert.Contains(t, httpLogger.Logs[3].Request, `{"ticket":{"subject":""}}`)
+	assert.Contains(t, httpLogger.Logs[4].Request, `{"ticket":{"status":"solved"}}`)
+	assert.Contains(t, httpLogger.Logs[5].Request, `{"ticket":{"status":"open"}}`)
+
+	// check a failed update
+	httpLogger = &flows.HTTPLogger{}
+
+	err = svc.Assign(session, ticket, bob, httpLogger.Log)
+	assert.EqualError(t, err, "error updating ticket: ticket API request failed with status 404")
+	assert.Equal(t, 1, len(httpLogger.Logs))
+	assert.Contains(t, httpLogger.Logs[0].Request, `{"ticket":{"assignee_email":"bob@nyaruka.com"}}`)
+
+	// tickets without an external ID can't be updated
+	ticket = flows.NewTicket("7481888c-07dd-47dc-bf22-ef7448696ffe", ticketer, weather, "Where are my shoes?", "", nil)
+
+	err = svc.Close(session, ticket, httpLogger.Log)
+	assert.EqualError(t, err, "ticket 7481888c-07dd-47dc-bf22-ef7448696ffe has no external ID")
+}
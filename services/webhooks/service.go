@@ -2,6 +2,7 @@ package webhooks
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 
@@ -38,6 +39,13 @@ func NewService(httpClient *http.Client, httpRetries *httpx.RetryConfig, httpAcc
 }
 
 func (s *service) Call(session flows.Session, request *http.Request) (*flows.WebhookCall, error) {
+	return s.CallWithContext(request.Context(), session, request)
+}
+
+// CallWithContext makes the given webhook call using the given context
+func (s *service) CallWithContext(ctx context.Context, session flows.Session, request *http.Request) (*flows.WebhookCall, error) {
+	request = request.WithContext(ctx)
+
 	// set any headers with defaults
 	for k, v := range s.defaultHeaders {
 		if request.Header.Get(k) == "" {
@@ -81,4 +89,4 @@ func (s *service) Call(session flows.Session, request *http.Request) (*flows.Web
 	return nil, err
 }
 
-var _ flows.WebhookService = (*service)(nil)
+var _ flows.ContextWebhookService = (*service)(nil)
@@ -1,8 +1,10 @@
 package webhooks_test
 
 import (
+	"context"
 	"net"
 	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
@@ -10,6 +12,7 @@ import (
 	"github.com/nyaruka/gocommon/dates"
 	"github.com/nyaruka/gocommon/httpx"
 	"github.com/nyaruka/goflow/envs"
+	"github.com/nyaruka/goflow/flows"
 	"github.com/nyaruka/goflow/services/webhooks"
 	"github.com/nyaruka/goflow/test"
 
@@ -244,3 +247,36 @@ func TestGzipEncoding(t *testing.T) {
 	assert.Equal(t, "Hello", string(c.ResponseBody))
 	assert.Equal(t, "HTTP/1.1 200 OK\r\nContent-Type: application/x-gzip\r\nDate: Wed, 11 Apr 2018 18:24:30 GMT\r\n\r\nHello", string(c.SanitizedResponse("...")))
 }
+
+func TestCallWithContext(t *testing.T) {
+	session, _, err := test.CreateTestSession("", envs.RedactionPolicyNone)
+	require.NoError(t, err)
+
+	// a server which takes a long time to respond
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer server.Close()
+
+	request, err := http.NewRequest("GET", server.URL, nil)
+	require.NoError(t, err)
+
+	svc, _ := session.Engine().Services().Webhook(session)
+	ctxSvc, ok := svc.(flows.ContextWebhookService)
+	require.True(t, ok)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	c, err := ctxSvc.CallWithContext(ctx, session, request)
+
+	// call gives up when deadline is reached and becomes a call with a connection error
+	assert.NoError(t, err)
+	assert.Nil(t, c.Response)
+	assert.Equal(t, flows.CallStatusConnectionError, flows.HTTPStatusFromCode(c.Trace))
+	assert.Less(t, time.Since(start), time.Second)
+}
@@ -1,6 +1,7 @@
 package test
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"strings"
@@ -8,6 +9,8 @@ import (
 
 	"github.com/nyaruka/gocommon/httpx"
 	"github.com/nyaruka/gocommon/urns"
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/envs"
 	"github.com/nyaruka/goflow/flows"
 	"github.com/nyaruka/goflow/flows/engine"
 	"github.com/nyaruka/goflow/services/webhooks"
@@ -30,6 +33,7 @@ func NewEngine() flows.Engine {
 		}).
 		WithTicketServiceFactory(func(s flows.Session, t *flows.Ticketer) (flows.TicketService, error) { return NewTicketService(t), nil }).
 		WithAirtimeServiceFactory(func(flows.Session) (flows.AirtimeService, error) { return newAirtimeService("RWF"), nil }).
+		WithContactServiceFactory(func(flows.Session) (flows.ContactService, error) { return NewContactService(), nil }).
 		Build()
 }
 
@@ -89,7 +93,7 @@ func (s *classificationService) Classify(session flows.Session, input string, lo
 
 var _ flows.ClassificationService = (*classificationService)(nil)
 
-// implementation of a ticket service for testing which fails if ticket subject contains "fail" and passes if not
+// implementation of a ticket service for testing which fails if ticket body or note contains "fail" and passes if not
 type ticketService struct {
 	ticketer *flows.Ticketer
 }
@@ -135,6 +139,62 @@ func (s *ticketService) Open(session flows.Session, topic *flows.Topic, body str
 	return ticket, nil
 }
 
+func (s *ticketService) AddNote(session flows.Session, ticket *flows.Ticket, note string, logHTTP flows.HTTPLogCallback) error {
+	success := !strings.Contains(note, "fail")
+
+	logTicketCall(logHTTP, ticket, "notes", fmt.Sprintf(`{"note":"%s"}`, note), success)
+
+	if !success {
+		return errors.New("error calling ticket API")
+	}
+	return nil
+}
+
+func (s *ticketService) Assign(session flows.Session, ticket *flows.Ticket, assignee *flows.User, logHTTP flows.HTTPLogCallback) error {
+	email := ""
+	if assignee != nil {
+		email = assignee.Email()
+	}
+
+	logTicketCall(logHTTP, ticket, "assign", fmt.Sprintf(`{"assignee":"%s"}`, email), true)
+	return nil
+}
+
+func (s *ticketService) ChangeTopic(session flows.Session, ticket *flows.Ticket, topic *flows.Topic, logHTTP flows.HTTPLogCallback) error {
+	logTicketCall(logHTTP, ticket, "topic", fmt.Sprintf(`{"topic":"%s"}`, topic.Name()), true)
+	return nil
+}
+
+func (s *ticketService) Close(session flows.Session, ticket *flows.Ticket, logHTTP flows.HTTPLogCallback) error {
+	logTicketCall(logHTTP, ticket, "close", `{}`, true)
+	return nil
+}
+
+func (s *ticketService) Reopen(session flows.Session, ticket *flows.Ticket, logHTTP flows.HTTPLogCallback) error {
+	logTicketCall(logHTTP, ticket, "reopen", `{}`, true)
+	return nil
+}
+
+// logs a fake HTTP call to update the given ticket
+func logTicketCall(logHTTP flows.HTTPLogCallback, ticket *flows.Ticket, path, body string, success bool) {
+	trace := &flows.HTTPTrace{
+		URL:        fmt.Sprintf("http://nyaruka.tickets.com/tickets/%s/%s.json", ticket.ExternalID(), path),
+		StatusCode: 200,
+		Status:     flows.CallStatusSuccess,
+		Request:    fmt.Sprintf("POST /tickets/%s/%s.json HTTP/1.1\r\nAccept-Encoding: gzip\r\n\r\n%s", ticket.ExternalID(), path, body),
+		Response:   "HTTP/1.0 200 OK\r\nContent-Length: 15\r\n\r\n{\"status\":\"ok\"}",
+		ElapsedMS:  1,
+		Retries:    0,
+	}
+	if !success {
+		trace.StatusCode = 400
+		trace.Status = flows.CallStatusResponseError
+		trace.Response = "HTTP/1.0 400 OK\r\nContent-Length: 17\r\n\r\n{\"status\":\"fail\"}"
+	}
+
+	logHTTP(&flows.HTTPLog{HTTPTrace: trace, CreatedOn: time.Date(2019, 10, 16, 13, 59, 30, 123456789, time.UTC)})
+}
+
 // implementation of an airtime service for testing which uses a fixed currency
 type airtimeService struct {
 	fixedCurrency string
@@ -175,3 +235,86 @@ func (s *airtimeService) Transfer(session flows.Session, sender urns.URN, recipi
 }
 
 var _ flows.AirtimeService = (*airtimeService)(nil)
+
+// the other contacts which exist for the contact service used in testing
+var existingContacts = `[
+	{
+		"uuid": "0f1e5d6a-6dbc-4bd7-8a17-06e9a5d4a1a4",
+		"name": "Bob Referrer",
+		"language": "fra",
+		"created_on": "2018-03-20T10:30:00.000000000-00:00",
+		"urns": ["tel:+12065552020"],
+		"fields": {
+			"gender": {"text": "Male"},
+			"age": {"text": "41", "number": 41}
+		}
+	},
+	{
+		"uuid": "7b3e5d1c-2a4f-4e8b-9c6d-1f2e3a4b5c6d",
+		"name": "Maria Amiga",
+		"language": "spa",
+		"created_on": "2018-04-11T09:15:00.000000000-00:00",
+		"urns": ["tel:+12065553030"],
+		"fields": {
+			"gender": {"text": "Female"}
+		}
+	}
+]`
+
+// implementation of a contact service for testing which looks up contacts from a fixed set, and fails to create
+// contacts with URNs which already belong to those
+type contactService struct{}
+
+// NewContactService creates a new contact service for testing
+func NewContactService() flows.ContactService {
+	return &contactService{}
+}
+
+func (s *contactService) LookupByURN(session flows.Session, urn urns.URN) (*flows.Contact, error) {
+	return s.find(session, func(c *flows.Contact) bool { return c.HasURN(urn) })
+}
+
+func (s *contactService) LookupByField(session flows.Session, field *flows.Field, value string) (*flows.Contact, error) {
+	return s.find(session, func(c *flows.Contact) bool {
+		v := c.Fields().Get(field)
+		return v != nil && strings.EqualFold(v.Text.Native(), value)
+	})
+}
+
+func (s *contactService) Create(session flows.Session, name string, language envs.Language, urnz []urns.URN) (*flows.Contact, error) {
+	for _, urn := range urnz {
+		existing, err := s.LookupByURN(session, urn)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			return nil, errors.Errorf("URN '%s' is already taken by another contact", urn)
+		}
+	}
+
+	contact := flows.NewEmptyContact(session.Assets(), name, language, nil)
+	for _, urn := range urnz {
+		contact.AddURN(urn, nil)
+	}
+	return contact, nil
+}
+
+func (s *contactService) find(session flows.Session, match func(*flows.Contact) bool) (*flows.Contact, error) {
+	var contacts []json.RawMessage
+	if err := json.Unmarshal([]byte(existingContacts), &contacts); err != nil {
+		return nil, err
+	}
+
+	for _, data := range contacts {
+		contact, err := flows.ReadContact(session.Assets(), data, assets.IgnoreMissing)
+		if err != nil {
+			return nil, err
+		}
+		if match(contact) {
+			return contact, nil
+		}
+	}
+	return nil, nil
+}
+
+var _ flows.ContactService = (*contactService)(nil)
@@ -20,6 +20,16 @@ import (
 )
 
 var sessionAssets = `{
+    "auth_profiles": [
+        {
+            "uuid": "a8ea3f5b-4f29-4b19-9ad1-4c13f7a67e4b",
+            "name": "Shop API",
+            "type": "bearer",
+            "config": {
+                "token": "sesame"
+            }
+        }
+    ],
     "channels": [
         {
             "uuid": "57f1078f-88aa-46f4-a59a-948a5739c03d",
@@ -185,6 +195,13 @@ var sessionAssets = `{
             "spec_version": "13.0",
             "language": "eng",
             "type": "messaging",
+            "params": [
+                {
+                    "key": "name",
+                    "name": "Name",
+                    "type": "text"
+                }
+            ],
             "nodes": [{
                 "uuid": "d9dba561-b5ee-4f62-ba44-60c4dc242b84",
                 "actions": [
@@ -0,0 +1,198 @@
+package utils
+
+import (
+	"strings"
+)
+
+var soundexCodes = map[byte]byte{
+	'B': '1', 'F': '1', 'P': '1', 'V': '1',
+	'C': '2', 'G': '2', 'J': '2', 'K': '2', 'Q': '2', 'S': '2', 'X': '2', 'Z': '2',
+	'D': '3', 'T': '3',
+	'L': '4',
+	'M': '5', 'N': '5',
+	'R': '6',
+}
+
+// Soundex returns the American Soundex code of the given word, e.g. Robert and Rupert both become R163. Only the
+// letters A-Z are considered so accents should be folded first, and a word without any letters has an empty code.
+func Soundex(s string) string {
+	letters := phoneticLetters(s)
+	if len(letters) == 0 {
+		return ""
+	}
+
+	code := []byte{letters[0]}
+	last := soundexCodes[letters[0]]
+
+	for i := 1; i < len(letters) && len(code) < 4; i++ {
+		c := letters[i]
+		digit, hasDigit := soundexCodes[c]
+
+		if hasDigit {
+			if digit != last {
+				code = append(code, digit)
+			}
+			last = digit
+		} else if c != 'H' && c != 'W' {
+			// vowels separate letters with the same code but H and W don't
+			last = 0
+		}
+	}
+
+	for len(code) < 4 {
+		code = append(code, '0')
+	}
+	return string(code)
+}
+
+// Metaphone returns the Metaphone key of the given word, e.g. Knight and Night both become NT. Only the letters A-Z
+// are considered so accents should be folded first, and a word without any letters has an empty key.
+func Metaphone(s string) string {
+	w := phoneticLetters(s)
+	if len(w) == 0 {
+		return ""
+	}
+
+	// some initial letter combinations have a silent or different sounding first letter
+	switch {
+	case hasPrefixAny(w, "AE", "GN", "KN", "PN", "WR"):
+		w = w[1:]
+	case w[0] == 'X':
+		w[0] = 'S'
+	case hasPrefixAny(w, "WH"):
+		w = append([]byte{'W'}, w[2:]...)
+	}
+
+	at := func(i int) byte {
+		if i >= 0 && i < len(w) {
+			return w[i]
+		}
+		return 0
+	}
+	isVowel := func(c byte) bool { return c != 0 && strings.IndexByte("AEIOU", c) >= 0 }
+	isFrontVowel := func(c byte) bool { return c == 'E' || c == 'I' || c == 'Y' }
+
+	key := strings.Builder{}
+
+	for i := 0; i < len(w); i++ {
+		c := w[i]
+
+		// doubled letters are only coded once, except for C
+		if c != 'C' && c == at(i-1) {
+			continue
+		}
+
+		switch c {
+		case 'A', 'E', 'I', 'O', 'U':
+			if i == 0 {
+				key.WriteByte(c)
+			}
+		case 'B':
+			if !(at(i-1) == 'M' && i == len(w)-1) {
+				key.WriteByte('B')
+			}
+		case 'C':
+			if at(i+1) == 'I' && at(i+2) == 'A' {
+				key.WriteByte('X')
+			} else if at(i+1) == 'H' {
+				if at(i-1) == 'S' {
+					key.WriteByte('K')
+				} else {
+					key.WriteByte('X')
+				}
+				i++
+			} else if isFrontVowel(at(i + 1)) {
+				if at(i-1) != 'S' {
+					key.WriteByte('S')
+				}
+			} else {
+				key.WriteByte('K')
+			}
+		case 'D':
+			if at(i+1) == 'G' && isFrontVowel(at(i+2)) {
+				key.WriteByte('J')
+				i++
+			} else {
+				key.WriteByte('T')
+			}
+		case 'G':
+			if at(i+1) == 'H' && i+2 < len(w) && !isVowel(at(i+2)) {
+				// silent as in night
+			} else if at(i+1) == 'N' && (i+2 == len(w) || (at(i+2) == 'E' && at(i+3) == 'D' && i+4 == len(w))) {
+				// silent as in sign and signed
+			} else if isFrontVowel(at(i+1)) && at(i-1) != 'G' {
+				key.WriteByte('J')
+			} else {
+				key.WriteByte('K')
+			}
+		case 'H':
+			if isVowel(at(i+1)) && strings.IndexByte("CGPST", at(i-1)) < 0 {
+				key.WriteByte('H')
+			}
+		case 'K':
+			if at(i-1) != 'C' {
+				key.WriteByte('K')
+			}
+		case 'P':
+			if at(i+1) == 'H' {
+				key.WriteByte('F')
+			} else {
+				key.WriteByte('P')
+			}
+		case 'Q':
+			key.WriteByte('K')
+		case 'S':
+			if at(i+1) == 'H' {
+				key.WriteByte('X')
+				i++
+			} else if at(i+1) == 'I' && (at(i+2) == 'O' || at(i+2) == 'A') {
+				key.WriteByte('X')
+			} else {
+				key.WriteByte('S')
+			}
+		case 'T':
+			if at(i+1) == 'I' && (at(i+2) == 'O' || at(i+2) == 'A') {
+				key.WriteByte('X')
+			} else if at(i+1) == 'H' {
+				key.WriteByte('0')
+				i++
+			} else if !(at(i+1) == 'C' && at(i+2) == 'H') {
+				key.WriteByte('T')
+			}
+		case 'V':
+			key.WriteByte('F')
+		case 'W', 'Y':
+			if isVowel(at(i + 1)) {
+				key.WriteByte(c)
+			}
+		case 'X':
+			key.WriteString("KS")
+		case 'Z':
+			key.WriteByte('S')
+		default:
+			key.WriteByte(c)
+		}
+	}
+
+	return key.String()
+}
+
+// gets the upper cased letters A-Z of the given string
+func phoneticLetters(s string) []byte {
+	letters := make([]byte, 0, len(s))
+	for _, c := range strings.ToUpper(s) {
+		if c >= 'A' && c <= 'Z' {
+			letters = append(letters, byte(c))
+		}
+	}
+	return letters
+}
+
+func hasPrefixAny(w []byte, prefixes ...string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(string(w), p) {
+			return true
+		}
+	}
+	return false
+}
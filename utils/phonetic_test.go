@@ -0,0 +1,66 @@
+package utils_test
+
+import (
+	"testing"
+
+	"github.com/nyaruka/goflow/utils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSoundex(t *testing.T) {
+	tcs := []struct {
+		word string
+		code string
+	}{
+		{"", ""},
+		{"123", ""},
+		{"Robert", "R163"},
+		{"Rupert", "R163"},
+		{"rubin", "R150"},
+		{"Ashcraft", "A261"}, // H doesn't separate letters with the same code
+		{"Tymczak", "T522"},  // but vowels do
+		{"Pfister", "P236"},  // first letter's code counts as a duplicate
+		{"yes", "Y200"},
+		{"yess", "Y200"},
+	}
+
+	for _, tc := range tcs {
+		assert.Equal(t, tc.code, utils.Soundex(tc.word), "soundex mismatch for '%s'", tc.word)
+	}
+}
+
+func TestMetaphone(t *testing.T) {
+	tcs := []struct {
+		word string
+		key  string
+	}{
+		{"", ""},
+		{"123", ""},
+		{"Knight", "NT"},
+		{"night", "NT"},
+		{"phone", "FN"},
+		{"fone", "FN"},
+		{"John", "JN"},
+		{"Jon", "JN"},
+		{"Nairobi", "NRB"},
+		{"Nairobbi", "NRB"},
+		{"Smith", "SM0"},
+		{"Smyth", "SM0"},
+		{"Catherine", "K0RN"},
+		{"Kathryn", "K0RN"},
+		{"Xavier", "SFR"},
+		{"Wright", "RT"},
+		{"whistle", "WSTL"},
+		{"cherry", "XR"},
+		{"science", "SNS"},
+		{"judge", "JJ"},
+		{"sign", "SN"},
+		{"Robert", "RBRT"},
+		{"Rupert", "RPRT"},
+	}
+
+	for _, tc := range tcs {
+		assert.Equal(t, tc.key, utils.Metaphone(tc.word), "metaphone mismatch for '%s'", tc.word)
+	}
+}
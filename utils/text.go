@@ -5,8 +5,10 @@ import (
 	"regexp"
 	"sort"
 	"strings"
+	"unicode"
 
 	"github.com/blevesearch/segment"
+	"golang.org/x/text/unicode/norm"
 )
 
 var snakedChars = regexp.MustCompile(`[^\p{L}\d_]+`)
@@ -136,6 +138,107 @@ func truncate(s string, limit int, ending string) string {
 	return string(runes[:limit-len(ending)]) + ending
 }
 
+// SplitText splits the given string into parts which are no longer than limit characters, breaking at white space
+// where possible
+func SplitText(s string, limit int) []string {
+	runes := []rune(s)
+	parts := make([]string, 0, len(runes)/limit+1)
+
+	for len(runes) > limit {
+		// look for the last white space which lets us break within the limit
+		cut := limit
+		for i := limit; i > 0; i-- {
+			if unicode.IsSpace(runes[i]) {
+				cut = i
+				break
+			}
+		}
+
+		parts = append(parts, strings.TrimRightFunc(string(runes[:cut]), unicode.IsSpace))
+		runes = []rune(strings.TrimLeftFunc(string(runes[cut:]), unicode.IsSpace))
+	}
+
+	return append(parts, string(runes))
+}
+
+// EditDistance returns the number of insertions, deletions, substitutions or transpositions of adjacent characters
+// needed to turn s1 into s2, i.e. their Damerau-Levenshtein distance without repeated edits of the same substring
+func EditDistance(s1, s2 string) int {
+	r1 := []rune(s1)
+	r2 := []rune(s2)
+
+	// rows of the distance matrix for the previous two and current characters of s1
+	prev2 := make([]int, len(r2)+1)
+	prev := make([]int, len(r2)+1)
+	curr := make([]int, len(r2)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(r1); i++ {
+		curr[0] = i
+
+		for j := 1; j <= len(r2); j++ {
+			cost := 1
+			if r1[i-1] == r2[j-1] {
+				cost = 0
+			}
+
+			curr[j] = minInt(minInt(prev[j]+1, curr[j-1]+1), prev[j-1]+cost)
+
+			if i > 1 && j > 1 && r1[i-1] == r2[j-2] && r1[i-2] == r2[j-1] {
+				curr[j] = minInt(curr[j], prev2[j-2]+1)
+			}
+		}
+
+		prev2, prev, curr = prev, curr, prev2
+	}
+
+	return prev[len(r2)]
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// letters which don't decompose into a base letter and accents, but have common equivalents in plain ASCII
+var accentFoldings = map[rune]string{
+	'ß': "ss", 'æ': "ae", 'Æ': "AE", 'œ': "oe", 'Œ': "OE", 'ø': "o", 'Ø': "O", 'ł': "l", 'Ł': "L", 'đ': "d", 'Đ': "D", 'ı': "i",
+}
+
+// language specific foldings, keyed by ISO-639-3 code, which take precedence over removing accents
+var languageAccentFoldings = map[string]map[rune]string{
+	"deu": {'ä': "ae", 'Ä': "AE", 'ö': "oe", 'Ö': "OE", 'ü': "ue", 'Ü': "UE"},
+	"dan": {'å': "aa", 'Å': "AA", 'ø': "oe", 'Ø': "OE"},
+	"nor": {'å': "aa", 'Å': "AA", 'ø': "oe", 'Ø': "OE"},
+}
+
+// FoldAccents replaces accented letters in the given string with their unaccented equivalents, using the conventions of
+// the given language (an ISO-639-3 code) where it has them, e.g. ü becomes ue in German but u in other languages
+func FoldAccents(s string, lang string) string {
+	langFoldings := languageAccentFoldings[lang]
+
+	folded := strings.Builder{}
+	for _, c := range norm.NFC.String(s) {
+		if f, found := langFoldings[c]; found {
+			folded.WriteString(f)
+		} else if f, found := accentFoldings[c]; found {
+			folded.WriteString(f)
+		} else {
+			for _, d := range norm.NFD.String(string(c)) {
+				if !unicode.Is(unicode.Mn, d) {
+					folded.WriteRune(d)
+				}
+			}
+		}
+	}
+	return folded.String()
+}
+
 // Redactor is a function which can redact the given string
 type Redactor func(s string) string
 
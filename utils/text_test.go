@@ -180,6 +180,41 @@ func TestTruncate(t *testing.T) {
 	assert.Equal(t, "你喜欢我当然喜", utils.Truncate("你喜欢我当然喜欢的电", 7))
 }
 
+func TestSplitText(t *testing.T) {
+	assert.Equal(t, []string{""}, utils.SplitText("", 10))
+	assert.Equal(t, []string{"1234567890"}, utils.SplitText("1234567890", 10))
+	assert.Equal(t, []string{"12345", "67890"}, utils.SplitText("1234567890", 5))
+	assert.Equal(t, []string{"123", "456", "789", "0"}, utils.SplitText("1234567890", 3))
+	assert.Equal(t, []string{"one two", "three four", "five"}, utils.SplitText("one two three four five", 10))
+	assert.Equal(t, []string{"one", "two", "three"}, utils.SplitText("one  two \n three", 5))
+	assert.Equal(t, []string{"你喜欢我当", "然喜欢的电"}, utils.SplitText("你喜欢我当然喜欢的电", 5))
+}
+
+func TestEditDistance(t *testing.T) {
+	assert.Equal(t, 0, utils.EditDistance("", ""))
+	assert.Equal(t, 3, utils.EditDistance("", "yes"))
+	assert.Equal(t, 0, utils.EditDistance("yes", "yes"))
+	assert.Equal(t, 1, utils.EditDistance("yess", "yes"))         // insertion
+	assert.Equal(t, 1, utils.EditDistance("nairobi", "nairobbi")) // deletion
+	assert.Equal(t, 1, utils.EditDistance("yes", "yas"))          // substitution
+	assert.Equal(t, 1, utils.EditDistance("teh", "the"))          // transposition
+	assert.Equal(t, 3, utils.EditDistance("kitten", "sitting"))   // several edits
+	assert.Equal(t, 3, utils.EditDistance("ca", "abc"))           // no repeated edits of the same substring
+	assert.Equal(t, 1, utils.EditDistance("héllo", "hello"))      // accents are different characters
+	assert.Equal(t, 1, utils.EditDistance("你喜欢我", "你喜欢"))         // runes rather than bytes
+}
+
+func TestFoldAccents(t *testing.T) {
+	assert.Equal(t, "", utils.FoldAccents("", "eng"))
+	assert.Equal(t, "hello", utils.FoldAccents("hello", "eng"))
+	assert.Equal(t, "cafe creme nandu", utils.FoldAccents("café crème ñandú", "fra"))
+	assert.Equal(t, "Muller strasse AEro", utils.FoldAccents("Müller straße Ærø", "eng"))
+	assert.Equal(t, "Mueller strasse", utils.FoldAccents("Müller straße", "deu"))
+	assert.Equal(t, "AEroe Haakon", utils.FoldAccents("Ærø Håkon", "dan"))
+	assert.Equal(t, "Cafe", utils.FoldAccents("Cafe\u0301", "fra")) // decomposed accent
+	assert.Equal(t, "βητα", utils.FoldAccents("βήτα", "ell"))
+}
+
 func TestRedactor(t *testing.T) {
 	assert.Equal(t, "hello world", utils.NewRedactor("****")("hello world"))                         // nothing to redact
 	assert.Equal(t, "", utils.NewRedactor("****", "abc")(""))                                        // empty input